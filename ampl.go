@@ -0,0 +1,137 @@
+/*
+Copyright 2017 Brendan Tracey
+
+Redistribution and use in source and binary forms, with or without modification,
+are permitted provided that the following conditions are met:
+
+1. Redistributions of source code must retain the above copyright notice, this
+list of conditions and the following disclaimer.
+
+2. Redistributions in binary form must reproduce the above copyright notice,
+this list of conditions and the following disclaimer in the documentation and/or
+other materials provided with the distribution.
+
+3. Neither the name of the copyright holder nor the names of its contributors may
+be used to endorse or promote products derived from this software without specific
+prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED.
+IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT,
+INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF
+LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE
+OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED
+OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package benchlp
+
+import (
+	"io"
+	"strconv"
+)
+
+// WriteAMPL writes the constraints to w as a flat AMPL model, with a var
+// declaration for each variable followed by the explicit constraints, for
+// example
+//
+//	var a;
+//	var b;
+//
+//	subject to c0: 2*a - b <= 0;
+//
+// Variables are declared in the order given by opts.Order, and terms are
+// written in the same order. Coefficients are formatted according to opts,
+// and the output is buffered and compressed as for WriteConstraintsTo. A nil
+// opts is equivalent to the zero value.
+//
+// Constraint i is named ci, and constraints with no non-zero coefficients
+// are omitted, as AMPL rejects constraints without variables. Variable names
+// must be legal AMPL names that do not clash with the constraint names, or
+// be made so by opts.Names.
+func WriteAMPL(w io.Writer, cons []Constraint, opts *Options) (err error) {
+	if opts == nil {
+		opts = &Options{}
+	}
+	defer opts.recoverWrite(&err, cons)
+	if err := opts.Limits.check(cons); err != nil {
+		return err
+	}
+	names, nameMap := IndexVariables(cons)
+	SortVariables(names, nameMap, opts.Order)
+	c1 := make([]float64, len(names))
+	c2 := make([]float64, len(names))
+
+	bw, err := opts.output(w)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cerr := bw.Close(); err == nil {
+			err = cerr
+		}
+	}()
+	if names, err = opts.writeNames(bw, "# ", names); err != nil {
+		return err
+	}
+	var b []byte
+	for _, name := range names {
+		b = append(b[:0], "var "...)
+		b = append(b, name...)
+		b = append(b, ";\n"...)
+		if _, err := bw.Write(b); err != nil {
+			return err
+		}
+	}
+	if _, err := io.WriteString(bw, "\n"); err != nil {
+		return err
+	}
+	for i, c := range cons {
+		b = append(b[:0], "subject to c"...)
+		b = strconv.AppendInt(b, int64(i), 10)
+		b = append(b, ": "...)
+		n := len(b)
+		b = opts.appendAMPLTerms(b, CondenseConstraint(c1, c2, c, nameMap), names)
+		if len(b) == n {
+			continue
+		}
+		b = append(b, " <= "...)
+		b = opts.appendFloat(b, 0)
+		b = append(b, ";\n"...)
+		if _, err := bw.Write(b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// appendAMPLTerms appends the non-zero w_i * v_i terms as an AMPL expression.
+func (o *Options) appendAMPLTerms(b []byte, w []float64, names []string) []byte {
+	first := true
+	for i, v := range w {
+		if v == 0 {
+			continue
+		}
+		switch {
+		case first && v < 0:
+			b = append(b, '-')
+		case !first && v < 0:
+			b = append(b, " - "...)
+		case !first:
+			b = append(b, " + "...)
+		}
+		first = false
+		if v < 0 {
+			v = -v
+		}
+		if !o.OmitUnit || v != 1 {
+			b = o.appendFloat(b, v)
+			b = append(b, '*')
+		}
+		b = append(b, names[i]...)
+	}
+	return b
+}
@@ -0,0 +1,39 @@
+package benchlp
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestWriteAMPL(t *testing.T) {
+	cons := []Constraint{
+		{Left: []Term{{"b", 2}, {"a", 1}}, Right: []Term{{"c", 0.5}}},
+		{Left: []Term{{"a", 1}}, Right: []Term{{"a", 1}}},
+		{Left: []Term{{"c", -1}}},
+	}
+	for _, test := range []struct {
+		opts *Options
+		want string
+	}{
+		{
+			opts: nil,
+			want: "var b;\nvar a;\nvar c;\n\n" +
+				"subject to c0: 2*b + 1*a - 0.5*c <= 0;\n" +
+				"subject to c2: -1*c <= 0;\n",
+		},
+		{
+			opts: &Options{OmitUnit: true, Order: Lexicographic},
+			want: "var a;\nvar b;\nvar c;\n\n" +
+				"subject to c0: a + 2*b - 0.5*c <= 0;\n" +
+				"subject to c2: -c <= 0;\n",
+		},
+	} {
+		var buf bytes.Buffer
+		if err := WriteAMPL(&buf, cons, test.opts); err != nil {
+			t.Fatal(err)
+		}
+		if buf.String() != test.want {
+			t.Errorf("options %+v:\ngot\n%s\nwant\n%s", test.opts, buf.String(), test.want)
+		}
+	}
+}
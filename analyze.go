@@ -0,0 +1,164 @@
+/*
+Copyright 2017 Brendan Tracey
+
+Redistribution and use in source and binary forms, with or without modification,
+are permitted provided that the following conditions are met:
+
+1. Redistributions of source code must retain the above copyright notice, this
+list of conditions and the following disclaimer.
+
+2. Redistributions in binary form must reproduce the above copyright notice,
+this list of conditions and the following disclaimer in the documentation and/or
+other materials provided with the distribution.
+
+3. Neither the name of the copyright holder nor the names of its contributors may
+be used to endorse or promote products derived from this software without specific
+prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED.
+IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT,
+INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF
+LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE
+OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED
+OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package benchlp
+
+import (
+	"encoding/binary"
+	"hash/fnv"
+	"math"
+	"slices"
+)
+
+// Conditioning lists the constraints that are likely to cause numerical
+// trouble in a solver.
+type Conditioning struct {
+	// Wide lists the constraints whose ratio of largest to smallest
+	// coefficient magnitude exceeds the range threshold.
+	Wide []RowRange
+	// Parallel lists the pairs of constraints over the same variables whose
+	// coefficient vectors are nearly parallel or anti-parallel. A pair of
+	// anti-parallel rows forces the row to zero, which solvers handle
+	// poorly unless it is exact.
+	Parallel []RowPair
+	// RHS lists the constraints whose right hand side is non-zero and
+	// smaller in magnitude than 1/maxRange or larger than maxRange.
+	RHS []RowRHS
+}
+
+// RowRange is the range of the coefficient magnitudes of a constraint.
+type RowRange struct {
+	Row      int
+	Min, Max float64
+}
+
+// RowRHS is the right hand side of a constraint, the negated coefficient of
+// One once the constraint is condensed.
+type RowRHS struct {
+	Row int
+	RHS float64
+}
+
+// RowPair is a pair of constraints, A < B, and the cosine of the angle
+// between their coefficient vectors.
+type RowPair struct {
+	A, B   int
+	Cosine float64
+}
+
+// Analyze checks the condensed constraints for poor conditioning. A
+// constraint is wide if the ratio of its largest to smallest coefficient
+// magnitude exceeds maxRange, and two constraints are nearly parallel if the
+// absolute cosine of the angle between them is at least 1-tol. If maxRange
+// is zero, 1e6 is used, and if tol is zero, 1e-9 is used. The coefficients
+// of One are the right hand sides, which are checked against maxRange and
+// are not counted in the ranges and cosines of the rows.
+//
+// Only constraints with exactly the same variables are compared for
+// parallelism, so the cost is linear in the number of constraints unless many
+// share the same variables.
+func Analyze(cons []Constraint, maxRange, tol float64) *Conditioning {
+	if maxRange == 0 {
+		maxRange = 1e6
+	}
+	if tol == 0 {
+		tol = 1e-9
+	}
+	names, nameMap := IndexVariables(cons)
+	rows := sparseRows(cons, names, nameMap)
+	one, hasOne := nameMap[One]
+
+	an := &Conditioning{}
+	groups := make(map[uint64][]int)
+	var buf [8]byte
+	for i, row := range rows {
+		if p := slices.Index(row.Index, one); hasOne && p >= 0 {
+			rhs := -row.Value[p]
+			if a := math.Abs(rhs); a < 1/maxRange || a > maxRange {
+				an.RHS = append(an.RHS, RowRHS{i, rhs})
+			}
+			row.Index = slices.Delete(row.Index, p, p+1)
+			row.Value = slices.Delete(row.Value, p, p+1)
+			rows[i] = row
+		}
+		if len(row.Value) == 0 {
+			continue
+		}
+		min, max := math.Inf(1), 0.0
+		for _, v := range row.Value {
+			min = math.Min(min, math.Abs(v))
+			max = math.Max(max, math.Abs(v))
+		}
+		if max > maxRange*min {
+			an.Wide = append(an.Wide, RowRange{i, min, max})
+		}
+
+		h := fnv.New64a()
+		for _, j := range row.Index {
+			binary.LittleEndian.PutUint64(buf[:], uint64(j))
+			h.Write(buf[:])
+		}
+		key := h.Sum64()
+		for _, k := range groups[key] {
+			if !sameIndex(rows[k].Index, row.Index) {
+				continue
+			}
+			if cos := cosine(rows[k].Value, row.Value); math.Abs(cos) >= 1-tol {
+				an.Parallel = append(an.Parallel, RowPair{k, i, cos})
+			}
+		}
+		groups[key] = append(groups[key], i)
+	}
+	return an
+}
+
+// sameIndex returns whether the index slices are equal.
+func sameIndex(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i, v := range a {
+		if b[i] != v {
+			return false
+		}
+	}
+	return true
+}
+
+// cosine returns the cosine of the angle between the non-zero vectors a and
+// b.
+func cosine(a, b []float64) float64 {
+	var dot, na, nb float64
+	for i, v := range a {
+		dot += v * b[i]
+		na += v * v
+		nb += b[i] * b[i]
+	}
+	return dot / math.Sqrt(na*nb)
+}
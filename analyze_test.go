@@ -0,0 +1,66 @@
+package benchlp
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAnalyze(t *testing.T) {
+	cons := []Constraint{
+		{Left: []Term{{"a", 1e-4}, {"b", 1e4}}},
+		{Left: []Term{{"a", 1}, {"b", 2}}},
+		{Left: []Term{{"a", 2}}, Right: []Term{{"b", -4 * (1 + 1e-4)}}},
+		{Left: []Term{{"b", 2}}, Right: []Term{{"a", -1}}},
+		{Left: []Term{{"a", -1}, {"b", -2}}},
+		{Left: []Term{{"a", 1}, {"b", 2}, {"c", 1e-3}}},
+		{},
+	}
+	an := Analyze(cons, 0, 0)
+	if want := []RowRange{{0, 1e-4, 1e4}}; !reflect.DeepEqual(an.Wide, want) {
+		t.Errorf("wide: got %v, want %v", an.Wide, want)
+	}
+	var pairs [][2]int
+	for _, p := range an.Parallel {
+		pairs = append(pairs, [2]int{p.A, p.B})
+		if p.A == 1 && p.B == 4 && p.Cosine > -0.999 {
+			t.Errorf("rows 1 and 4 have cosine %v", p.Cosine)
+		}
+	}
+	want := [][2]int{{1, 2}, {1, 3}, {2, 3}, {1, 4}, {2, 4}, {3, 4}}
+	if !reflect.DeepEqual(pairs, want) {
+		t.Errorf("parallel: got %v, want %v", pairs, want)
+	}
+
+	an = Analyze(cons, 1e9, 1e-12)
+	if len(an.Wide) != 0 {
+		t.Errorf("wide rows with a large range: %v", an.Wide)
+	}
+	for _, p := range an.Parallel {
+		if p.B == 2 {
+			t.Errorf("row 2 parallel with a tight tolerance")
+		}
+	}
+}
+
+func TestAnalyzeRHS(t *testing.T) {
+	cons := []Constraint{
+		{Left: []Term{{"c", 1}}, Right: []Term{{One, 1e8}}},
+		{Left: []Term{{"d", 1}, {One, 1e-9}}},
+		{Left: []Term{{"e", 1}}, Right: []Term{{One, 5}}},
+		{Left: []Term{{"f", 1e-3}, {One, 2}}, Right: []Term{{One, 2}}},
+		{Left: []Term{{"a", 1}, {"b", 1}}, Right: []Term{{One, 1}}},
+		{Left: []Term{{"a", 1}, {"b", 1}}, Right: []Term{{One, 2}}},
+	}
+	an := Analyze(cons, 0, 0)
+	if want := []RowRHS{{0, 1e8}, {1, -1e-9}}; !reflect.DeepEqual(an.RHS, want) {
+		t.Errorf("rhs: got %v, want %v", an.RHS, want)
+	}
+	// The constants are not coefficients, so no row is wide, and rows 4
+	// and 5 are parallel even though their right hand sides differ.
+	if len(an.Wide) != 0 {
+		t.Errorf("wide: got %v", an.Wide)
+	}
+	if len(an.Parallel) != 1 || an.Parallel[0].A != 4 || an.Parallel[0].B != 5 {
+		t.Errorf("parallel: got %v, want rows 4 and 5", an.Parallel)
+	}
+}
@@ -0,0 +1,162 @@
+/*
+Copyright 2017 Brendan Tracey
+
+Redistribution and use in source and binary forms, with or without modification,
+are permitted provided that the following conditions are met:
+
+1. Redistributions of source code must retain the above copyright notice, this
+list of conditions and the following disclaimer.
+
+2. Redistributions in binary form must reproduce the above copyright notice,
+this list of conditions and the following disclaimer in the documentation and/or
+other materials provided with the distribution.
+
+3. Neither the name of the copyright holder nor the names of its contributors may
+be used to endorse or promote products derived from this software without specific
+prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED.
+IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT,
+INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF
+LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE
+OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED
+OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package benchlp
+
+import (
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// Attributes are key and value metadata on variables and constraints, such
+// as units or the source line that produced them. Term and Constraint do not
+// hold metadata, so the attributes are kept alongside the constraints, with
+// constraints identified by their index.
+//
+// When set as the Attributes option, the attributes are written as comments:
+// those of the variables after Comments, and those of each constraint on the
+// line before it, for example
+//
+//	\ var x: unit="kg"
+//	\ row 0: source="plant.go:12"
+//	2 x + -1 y <= 0
+//
+// with the keys sorted and the values quoted as Go strings. With the
+// TrailingAttributes option the comment follows the constraint instead, as
+//
+//	2 x + -1 y <= 0 \ row 0: source="plant.go:12"
+//
+// so that each line carries its own provenance. EncodeJSON includes them in
+// the JSON output.
+type Attributes struct {
+	Vars map[string]map[string]string `json:"vars,omitempty"`
+	Rows map[int]map[string]string    `json:"rows,omitempty"`
+}
+
+// SetVar sets the attribute of the variable.
+func (a *Attributes) SetVar(name, key, value string) {
+	if a.Vars == nil {
+		a.Vars = make(map[string]map[string]string)
+	}
+	setAttribute(a.Vars, name, key, value)
+}
+
+// SetRow sets the attribute of constraint i.
+func (a *Attributes) SetRow(i int, key, value string) {
+	if a.Rows == nil {
+		a.Rows = make(map[int]map[string]string)
+	}
+	setAttribute(a.Rows, i, key, value)
+}
+
+// Trace records the provenance of constraint i: its "source" attribute is
+// set to the file and line of the caller of Trace, and its "tag" attribute
+// to tag if tag is not empty, such as the generator or input data row that
+// produced the constraint.
+func (a *Attributes) Trace(i int, tag string) {
+	if _, file, line, ok := runtime.Caller(1); ok {
+		a.SetRow(i, "source", filepath.Base(file)+":"+strconv.Itoa(line))
+	}
+	if tag != "" {
+		a.SetRow(i, "tag", tag)
+	}
+}
+
+// Row returns the index and attributes of the named constraint, so that a
+// row reported by a solver can be traced to its source. The name is either
+// the index or, as in the MPS and AMPL output, "c" followed by the index.
+// It returns false if the name is not of that form or the row has no
+// attributes.
+func (a *Attributes) Row(name string) (i int, attrs map[string]string, ok bool) {
+	i, err := strconv.Atoi(strings.TrimPrefix(name, "c"))
+	if err != nil || i < 0 {
+		return 0, nil, false
+	}
+	attrs, ok = a.Rows[i]
+	return i, attrs, ok
+}
+
+func setAttribute[K comparable](m map[K]map[string]string, k K, key, value string) {
+	attrs := m[k]
+	if attrs == nil {
+		attrs = make(map[string]string)
+		m[k] = attrs
+	}
+	attrs[key] = value
+}
+
+// appendVarAttributes appends the comment lines of the variable attributes,
+// in order of name.
+func (o *Options) appendVarAttributes(b []byte) []byte {
+	for _, name := range sortedKeys(o.Attributes.Vars) {
+		b = append(b, o.commentPrefix()...)
+		b = append(b, "var "...)
+		b = append(b, name...)
+		b = appendAttributes(b, o.Attributes.Vars[name])
+	}
+	return b
+}
+
+// appendRowAttributes appends the comment line of the attributes of
+// constraint i, if it has any.
+func (o *Options) appendRowAttributes(b []byte, i int) []byte {
+	attrs := o.Attributes.Rows[i]
+	if len(attrs) == 0 {
+		return b
+	}
+	b = append(b, o.commentPrefix()...)
+	b = append(b, "row "...)
+	b = strconv.AppendInt(b, int64(i), 10)
+	return appendAttributes(b, attrs)
+}
+
+// appendTrailingAttributes appends the attributes of constraint i, if it
+// has any, as a comment at the end of the line in b.
+func (o *Options) appendTrailingAttributes(b []byte, i int) []byte {
+	if len(o.Attributes.Rows[i]) == 0 {
+		return b
+	}
+	b = append(b[:len(b)-1], ' ') // replace the newline
+	return o.appendRowAttributes(b, i)
+}
+
+// appendAttributes appends ": " and the attributes, sorted by key, and ends
+// the line.
+func appendAttributes(b []byte, attrs map[string]string) []byte {
+	b = append(b, ':')
+	for _, key := range sortedKeys(attrs) {
+		b = append(b, ' ')
+		b = append(b, key...)
+		b = append(b, '=')
+		b = strconv.AppendQuote(b, attrs[key])
+	}
+	return append(b, '\n')
+}
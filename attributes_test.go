@@ -0,0 +1,99 @@
+package benchlp
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestAttributes(t *testing.T) {
+	cons := []Constraint{
+		{Left: []Term{{"x", 2}}, Right: []Term{{"y", 1}}},
+		{Left: []Term{{"y", 1}}},
+	}
+	var attrs Attributes
+	attrs.SetVar("x", "unit", "kg")
+	attrs.SetVar("x", "priority", "2")
+	attrs.SetVar("y", "unit", `"m"`)
+	attrs.SetRow(1, "source", "plant.go:12")
+
+	var buf bytes.Buffer
+	opts := &Options{Comments: []string{"model"}, Attributes: &attrs}
+	if err := WriteConstraintsTo(&buf, cons, opts); err != nil {
+		t.Fatal(err)
+	}
+	want := `\ model
+\ var x: priority="2" unit="kg"
+\ var y: unit="\"m\""
+2 x + -1 y <= 0
+\ row 1: source="plant.go:12"
+1 y <= 0
+`
+	if buf.String() != want {
+		t.Errorf("got\n%s\nwant\n%s", buf.String(), want)
+	}
+	got, err := ReadConstraints(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !EqualConstraints(got, cons, 0) {
+		t.Errorf("read back %v, want %v", got, cons)
+	}
+
+	buf.Reset()
+	if err := EncodeJSONAttributes(&buf, cons, &attrs); err != nil {
+		t.Fatal(err)
+	}
+	gotCons, gotAttrs, err := DecodeJSONAttributes(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(gotCons, cons) || !reflect.DeepEqual(*gotAttrs, attrs) {
+		t.Errorf("JSON round trip: got %v and %+v", gotCons, gotAttrs)
+	}
+	if _, err := DecodeJSON(&buf); err != nil {
+		t.Errorf("DecodeJSON with attributes: %v", err)
+	}
+}
+
+func TestTrailingAttributes(t *testing.T) {
+	cons := []Constraint{
+		{Left: []Term{{"x", 2}}, Right: []Term{{"y", 1}}},
+		{Left: []Term{{"y", 1}}},
+	}
+	var attrs Attributes
+	attrs.Trace(1, "capacity/plant=7")
+	_, row, ok := attrs.Row("c1")
+	if !ok || row["tag"] != "capacity/plant=7" || !strings.HasPrefix(row["source"], "attributes_test.go:") {
+		t.Fatalf("row c1: got %v, %v", row, ok)
+	}
+	if i, _, ok := attrs.Row("1"); !ok || i != 1 {
+		t.Errorf("row 1: got %d, %v", i, ok)
+	}
+	for _, name := range []string{"c0", "c", "x1", "c-1"} {
+		if _, _, ok := attrs.Row(name); ok {
+			t.Errorf("row %q found", name)
+		}
+	}
+
+	for _, dialect := range []Dialect{CPLEXDialect, LPSolveDialect} {
+		var buf bytes.Buffer
+		opts := &Options{Attributes: &attrs, TrailingAttributes: true, Dialect: dialect}
+		if err := WriteConstraintsTo(&buf, cons, opts); err != nil {
+			t.Fatal(err)
+		}
+		lines := strings.Split(buf.String(), "\n")
+		if len(lines) != 3 || !strings.Contains(lines[1], `row 1: source="attributes_test.go:`) ||
+			!strings.HasSuffix(lines[1], ` tag="capacity/plant=7"`) {
+			t.Errorf("dialect %v: got\n%s", dialect, buf.String())
+		}
+		got, err := ReadConstraints(&buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !EqualConstraints(got, cons, 0) {
+			t.Errorf("dialect %v: read back %v, want %v", dialect, got, cons)
+		}
+	}
+}
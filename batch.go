@@ -0,0 +1,392 @@
+/*
+Copyright 2017 Brendan Tracey
+
+Redistribution and use in source and binary forms, with or without modification,
+are permitted provided that the following conditions are met:
+
+1. Redistributions of source code must retain the above copyright notice, this
+list of conditions and the following disclaimer.
+
+2. Redistributions in binary form must reproduce the above copyright notice,
+this list of conditions and the following disclaimer in the documentation and/or
+other materials provided with the distribution.
+
+3. Neither the name of the copyright holder nor the names of its contributors may
+be used to endorse or promote products derived from this software without specific
+prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED.
+IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT,
+INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF
+LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE
+OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED
+OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package benchlp
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Solver solves the model in a file, such as by running an external solver,
+// and returns the statistics of the run.
+type Solver interface {
+	Solve(ctx context.Context, model string, p *SolverParams) (*RunStats, error)
+}
+
+// ConstraintSolver solves constraints and returns the statistics of the run
+// and the solution found, if any.
+type ConstraintSolver interface {
+	SolveConstraints(ctx context.Context, cons []Constraint, p *SolverParams) (*RunStats, *Solution, error)
+}
+
+// CommandSolver is a Solver that runs the command line program of a solver
+// and reads the statistics from its log on standard output. If SolutionArgs
+// and ReadSolution are set, it is also a ConstraintSolver: SolutionArgs
+// returns the arguments that solve the model and write the solution to the
+// file named solution, which ReadSolution reads.
+type CommandSolver struct {
+	Path    string
+	Args    func(model string, p *SolverParams) []string
+	ReadLog func(io.Reader) (*RunStats, error)
+
+	SolutionArgs func(model, solution string, p *SolverParams) []string
+	ReadSolution func(io.Reader) (*Solution, error)
+}
+
+// Solve runs the program on the model. If the program fails, the error is
+// returned along with whatever statistics its log holds.
+func (s *CommandSolver) Solve(ctx context.Context, model string, p *SolverParams) (*RunStats, error) {
+	return s.run(ctx, s.Args(model, p))
+}
+
+// errNoSolutions is returned by SolveConstraints when the solver cannot
+// write solutions.
+var errNoSolutions = errors.New("lp: solver does not write solutions")
+
+// SolveConstraints writes the constraints to a temporary MPS file, with the
+// names sanitized by MPSNames, runs the program on it, and reads the
+// solution it writes, with the values keyed by the original names. The
+// solution is nil if the program wrote none. The files are removed before
+// SolveConstraints returns.
+func (s *CommandSolver) SolveConstraints(ctx context.Context, cons []Constraint, p *SolverParams) (_ *RunStats, _ *Solution, err error) {
+	if s.SolutionArgs == nil || s.ReadSolution == nil {
+		return nil, nil, errNoSolutions
+	}
+	dir, err := os.MkdirTemp("", "benchlp")
+	if err != nil {
+		return nil, nil, err
+	}
+	defer os.RemoveAll(dir)
+	model := filepath.Join(dir, "model.mps")
+	if err := writeMPSFile(model, cons, &Options{Precision: -1, Names: MPSNames}); err != nil {
+		return nil, nil, err
+	}
+
+	solution := filepath.Join(dir, "model.sol")
+	stats, err := s.run(ctx, s.SolutionArgs(model, solution, p))
+	if err != nil {
+		return stats, nil, err
+	}
+	f, err := os.Open(solution)
+	if os.IsNotExist(err) {
+		return stats, nil, nil
+	}
+	if err != nil {
+		return stats, nil, err
+	}
+	defer f.Close()
+	sol, err := s.ReadSolution(f)
+	if err != nil {
+		return stats, nil, err
+	}
+	// WriteMPS orders the variables by first appearance, as IndexVariables
+	// does, so the names are sanitized the same way.
+	names, _ := IndexVariables(cons)
+	values := make(map[string]float64, len(sol.Values))
+	for j, name := range SanitizeNames(names, MPSNames) {
+		if v, ok := sol.Values[name]; ok {
+			values[names[j]] = v
+		}
+	}
+	sol.Values = values
+	return stats, sol, nil
+}
+
+// writeMPSFile writes the constraints to the named file with WriteMPS.
+func writeMPSFile(name string, cons []Constraint, opts *Options) error {
+	f, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	err = WriteMPS(f, cons, opts)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// run runs the program with the arguments and reads its log.
+func (s *CommandSolver) run(ctx context.Context, args []string) (*RunStats, error) {
+	var out bytes.Buffer
+	cmd := exec.CommandContext(ctx, s.Path, args...)
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	err := cmd.Run()
+	stats, lerr := s.ReadLog(&out)
+	if err == nil {
+		err = lerr
+	}
+	return stats, err
+}
+
+// GurobiSolver returns a Solver running gurobi_cl, with the parameters
+// passed as Name=value arguments. Solutions are written with ResultFile.
+func GurobiSolver() *CommandSolver {
+	args := func(model, solution string, p *SolverParams) []string {
+		var args []string
+		for _, pv := range p.values() {
+			args = append(args, paramNames[pv.k][gurobiParams]+"="+pv.value)
+		}
+		if solution != "" {
+			args = append(args, "ResultFile="+solution)
+		}
+		return append(args, model)
+	}
+	return &CommandSolver{
+		Path: "gurobi_cl",
+		Args: func(model string, p *SolverParams) []string {
+			return args(model, "", p)
+		},
+		ReadLog:      ReadGurobiLog,
+		SolutionArgs: args,
+		ReadSolution: ReadSolution,
+	}
+}
+
+// CPLEXSolver returns a Solver running the CPLEX interactive optimizer, with
+// the parameters set by its commands. Solutions are written by the write
+// command, in the CPLEX XML format.
+func CPLEXSolver() *CommandSolver {
+	args := func(model, solution string, p *SolverParams) []string {
+		args := []string{"-c", "read " + model}
+		for _, pv := range p.values() {
+			args = append(args, "set "+cplexCommands[pv.k]+" "+pv.value)
+		}
+		args = append(args, "optimize")
+		if solution != "" {
+			args = append(args, "write "+solution+" sol")
+		}
+		return append(args, "quit")
+	}
+	return &CommandSolver{
+		Path: "cplex",
+		Args: func(model string, p *SolverParams) []string {
+			return args(model, "", p)
+		},
+		ReadLog:      ReadCPLEXLog,
+		SolutionArgs: args,
+		ReadSolution: ReadCPLEXSolution,
+	}
+}
+
+// cplexCommands are the CPLEX interactive commands setting the parameters,
+// in the order of paramNames.
+var cplexCommands = [...]string{
+	timeLimitParam: "timelimit",
+	threadsParam:   "threads",
+	seedParam:      "randomseed",
+	mipGapParam:    "mip tolerances mipgap",
+	presolveParam:  "preprocessing presolve",
+}
+
+// HiGHSSolver returns a Solver running highs. Its command line has no
+// options for the thread count and MIP gap, so Threads and MIPGap are
+// ignored. Solutions are written with --solution_file, in the raw format.
+func HiGHSSolver() *CommandSolver {
+	args := func(model, solution string, p *SolverParams) []string {
+		var args []string
+		for _, pv := range p.values() {
+			switch pv.k {
+			case timeLimitParam:
+				args = append(args, "--time_limit", pv.value)
+			case seedParam:
+				args = append(args, "--random_seed", pv.value)
+			case presolveParam:
+				args = append(args, "--presolve", "off")
+			}
+		}
+		if solution != "" {
+			args = append(args, "--solution_file", solution)
+		}
+		return append(args, "--model_file", model)
+	}
+	return &CommandSolver{
+		Path: "highs",
+		Args: func(model string, p *SolverParams) []string {
+			return args(model, "", p)
+		},
+		ReadLog:      ReadHiGHSLog,
+		SolutionArgs: args,
+		ReadSolution: ReadHiGHSSolution,
+	}
+}
+
+// batchGrace is how long a run of RunBatch may continue past the time limit
+// before it is killed.
+var batchGrace = 10 * time.Second
+
+// BatchResult is the result of solving one instance with RunBatch. Stats is
+// nil if the log could not be read, and Err is the error of the run.
+type BatchResult struct {
+	Instance string
+	Stats    *RunStats
+	Wall     time.Duration
+	Err      error
+}
+
+// RunBatch solves each model file in the directory with the solver, in order
+// of name, and returns the results. The model files are those with the
+// extensions .lp and .mps, optionally followed by .gz. If p.TimeLimit is
+// set, a run still going a short grace period after the limit is killed. A
+// nil p leaves every parameter at the solver default. A failed run is
+// recorded in its result rather than stopping the batch, and the error is
+// that of reading the directory or of ctx.
+func RunBatch(ctx context.Context, dir string, s Solver, p *SolverParams) ([]BatchResult, error) {
+	if p == nil {
+		p = &SolverParams{}
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var results []BatchResult
+	for _, e := range entries {
+		if e.IsDir() || !isModelFile(e.Name()) {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return results, err
+		}
+		runCtx, cancel := ctx, context.CancelFunc(func() {})
+		if p.TimeLimit > 0 {
+			runCtx, cancel = context.WithTimeout(ctx, p.TimeLimit+batchGrace)
+		}
+		start := time.Now()
+		stats, err := s.Solve(runCtx, filepath.Join(dir, e.Name()), p)
+		cancel()
+		results = append(results, BatchResult{
+			Instance: e.Name(),
+			Stats:    stats,
+			Wall:     time.Since(start),
+			Err:      err,
+		})
+	}
+	return results, ctx.Err()
+}
+
+// isModelFile returns whether the file name has the extension of a model.
+func isModelFile(name string) bool {
+	ext := filepath.Ext(strings.TrimSuffix(name, ".gz"))
+	return ext == ".lp" || ext == ".mps"
+}
+
+// batchHeader is the header of the CSV output of WriteBatchCSV.
+var batchHeader = []string{"instance", "status", "objective", "bound", "gap", "iterations", "nodes", "time", "wall", "error"}
+
+// WriteBatchCSV writes the results as CSV, one row per instance, with times
+// in seconds. Values that were not reported are empty.
+func WriteBatchCSV(w io.Writer, results []BatchResult) error {
+	cw := csv.NewWriter(w)
+	cw.Write(batchHeader)
+	for _, r := range results {
+		row := make([]string, len(batchHeader))
+		row[0] = r.Instance
+		if s := r.Stats; s != nil {
+			row[1] = s.Status
+			row[2] = formatStat(s.Objective)
+			row[3] = formatStat(s.Bound)
+			row[4] = formatStat(s.Gap)
+			row[5] = strconv.FormatInt(s.Iterations, 10)
+			row[6] = strconv.FormatInt(s.Nodes, 10)
+			row[7] = formatStat(s.Time.Seconds())
+		}
+		row[8] = formatStat(r.Wall.Seconds())
+		if r.Err != nil {
+			row[9] = r.Err.Error()
+		}
+		cw.Write(row)
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// formatStat formats a statistic for WriteBatchCSV, with NaN empty.
+func formatStat(v float64) string {
+	if math.IsNaN(v) {
+		return ""
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// batchJSON is the JSON form of a BatchResult. Values that were not
+// reported are null.
+type batchJSON struct {
+	Instance   string   `json:"instance"`
+	Status     string   `json:"status,omitempty"`
+	Objective  *float64 `json:"objective"`
+	Bound      *float64 `json:"bound"`
+	Gap        *float64 `json:"gap"`
+	Iterations int64    `json:"iterations"`
+	Nodes      int64    `json:"nodes"`
+	Time       *float64 `json:"time"`
+	Wall       float64  `json:"wall"`
+	Error      string   `json:"error,omitempty"`
+}
+
+// WriteBatchJSON writes the results as a JSON array, with the same fields
+// as WriteBatchCSV.
+func WriteBatchJSON(w io.Writer, results []BatchResult) error {
+	out := make([]batchJSON, len(results))
+	stat := func(v float64) *float64 {
+		if math.IsNaN(v) {
+			return nil
+		}
+		return &v
+	}
+	for i, r := range results {
+		out[i] = batchJSON{Instance: r.Instance, Wall: r.Wall.Seconds()}
+		if s := r.Stats; s != nil {
+			out[i].Status = s.Status
+			out[i].Objective = stat(s.Objective)
+			out[i].Bound = stat(s.Bound)
+			out[i].Gap = stat(s.Gap)
+			out[i].Iterations = s.Iterations
+			out[i].Nodes = s.Nodes
+			out[i].Time = stat(s.Time.Seconds())
+		}
+		if r.Err != nil {
+			out[i].Error = r.Err.Error()
+		}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "\t")
+	return enc.Encode(out)
+}
@@ -0,0 +1,177 @@
+package benchlp
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeSolver reports a run of each model from a table, by file name.
+type fakeSolver map[string]*RunStats
+
+func (s fakeSolver) Solve(ctx context.Context, model string, p *SolverParams) (*RunStats, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		return nil, errors.New("no deadline")
+	}
+	stats, ok := s[filepath.Base(model)]
+	if !ok {
+		return nil, errors.New("solver failed")
+	}
+	return stats, nil
+}
+
+func TestRunBatch(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"b.mps.gz", "a.lp", "c.lp", "notes.txt"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Mkdir(filepath.Join(dir, "d.lp"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	nan := math.NaN()
+	s := fakeSolver{
+		"a.lp":     {Status: "optimal", Iterations: 12, Objective: 3.5, Bound: nan, Gap: nan, Time: 250 * time.Millisecond},
+		"b.mps.gz": {Status: "time limit", Nodes: 40, Objective: 7, Bound: 6, Gap: 0.125, Time: time.Second},
+	}
+	results, err := RunBatch(context.Background(), dir, s, &SolverParams{TimeLimit: time.Minute})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, r := range results {
+		names = append(names, r.Instance)
+	}
+	if !reflect.DeepEqual(names, []string{"a.lp", "b.mps.gz", "c.lp"}) {
+		t.Fatalf("instances: got %v", names)
+	}
+	if results[0].Stats != s["a.lp"] || results[0].Err != nil || results[2].Err == nil {
+		t.Errorf("results: got %+v", results)
+	}
+
+	// Without parameters, there is no time limit.
+	noLimit, err := RunBatch(context.Background(), dir, s, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(noLimit) != 3 || noLimit[0].Err == nil || noLimit[0].Err.Error() != "no deadline" {
+		t.Errorf("nil parameters: got %+v", noLimit)
+	}
+
+	for i := range results {
+		results[i].Wall = 2 * time.Second
+	}
+
+	var buf bytes.Buffer
+	if err := WriteBatchCSV(&buf, results); err != nil {
+		t.Fatal(err)
+	}
+	want := "instance,status,objective,bound,gap,iterations,nodes,time,wall,error\n" +
+		"a.lp,optimal,3.5,,,12,0,0.25,2,\n" +
+		"b.mps.gz,time limit,7,6,0.125,0,40,1,2,\n" +
+		"c.lp,,,,,,,,2,solver failed\n"
+	if buf.String() != want {
+		t.Errorf("got CSV\n%s\nwant\n%s", buf.String(), want)
+	}
+
+	buf.Reset()
+	if err := WriteBatchJSON(&buf, results[:1]); err != nil {
+		t.Fatal(err)
+	}
+	got := strings.Join(strings.Fields(buf.String()), "")
+	wantJSON := `[{"instance":"a.lp","status":"optimal","objective":3.5,"bound":null,"gap":null,` +
+		`"iterations":12,"nodes":0,"time":0.25,"wall":2}]`
+	if got != wantJSON {
+		t.Errorf("got JSON %s, want %s", got, wantJSON)
+	}
+}
+
+func TestSolverArgs(t *testing.T) {
+	p := &SolverParams{TimeLimit: 90 * time.Second, Threads: 4, Seed: 7, NoPresolve: true}
+	for _, test := range []struct {
+		s    *CommandSolver
+		want []string
+	}{
+		{GurobiSolver(), []string{"TimeLimit=90", "Threads=4", "Seed=7", "Presolve=0", "m.lp"}},
+		{CPLEXSolver(), []string{"-c", "read m.lp", "set timelimit 90", "set threads 4",
+			"set randomseed 7", "set preprocessing presolve 0", "optimize", "quit"}},
+		{HiGHSSolver(), []string{"--time_limit", "90", "--random_seed", "7", "--presolve", "off",
+			"--model_file", "m.lp"}},
+	} {
+		if got := test.s.Args("m.lp", p); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: got %q, want %q", test.s.Path, got, test.want)
+		}
+	}
+	if got, want := HiGHSSolver().Args("m.lp", nil), []string{"--model_file", "m.lp"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("nil parameters: got %q, want %q", got, want)
+	}
+
+	for _, test := range []struct {
+		s    *CommandSolver
+		want []string
+	}{
+		{GurobiSolver(), []string{"Threads=4", "ResultFile=m.sol", "m.mps"}},
+		{CPLEXSolver(), []string{"-c", "read m.mps", "set threads 4", "optimize", "write m.sol sol", "quit"}},
+		{HiGHSSolver(), []string{"--solution_file", "m.sol", "--model_file", "m.mps"}},
+	} {
+		if got := test.s.SolutionArgs("m.mps", "m.sol", &SolverParams{Threads: 4}); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s solution: got %q, want %q", test.s.Path, got, test.want)
+		}
+	}
+}
+
+// fakeHiGHS is a highs that checks the name of the sanitized variable in
+// the model and writes a solution.
+const fakeHiGHS = `#!/bin/sh
+while [ $# -gt 0 ]; do
+	case $1 in
+	--model_file) model=$2; shift ;;
+	--solution_file) sol=$2; shift ;;
+	esac
+	shift
+done
+grep -q '^    a_b  c0  1$' "$model" || exit 1
+printf 'Model status\nOptimal\n\n# Primal solution values\nFeasible\nObjective 0\n# Columns 2\na_b 3\nc -1\n# Rows 2\nc0 3\nc1 -1\n\n# Dual solution values\nFeasible\n# Columns 2\na_b 0\nc 0\n# Rows 2\nc0 -1\nc1 0\n\n# Basis\nNone\n' > "$sol"
+echo 'Model   status      : Optimal'
+`
+
+func TestSolveConstraints(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("needs a shell script")
+	}
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "highs"), []byte(fakeHiGHS), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	cons := []Constraint{
+		{Left: []Term{{"a b", 1}}, Right: []Term{{One, 3}}},
+		{Left: []Term{{"c", 1}}, Right: []Term{{One, -1}}},
+	}
+	stats, sol, err := HiGHSSolver().SolveConstraints(context.Background(), cons, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Status != "optimal" {
+		t.Errorf("status %q", stats.Status)
+	}
+	want := &Solution{Values: map[string]float64{"a b": 3, "c": -1}, Duals: []float64{-1, 0}}
+	if !reflect.DeepEqual(sol, want) {
+		t.Errorf("got %+v, want %+v", sol, want)
+	}
+
+	s := &CommandSolver{Path: "highs", Args: HiGHSSolver().Args, ReadLog: ReadHiGHSLog}
+	if _, _, err := s.SolveConstraints(context.Background(), cons, nil); err == nil {
+		t.Errorf("no error from a solver without solutions")
+	}
+}
@@ -29,16 +29,19 @@ OF THE POSSIBILITY OF SUCH DAMAGE.
 
 package benchlp
 
-import "strconv"
+import (
+	"io"
+	"strconv"
+)
 
 type Term struct {
-	Var   string
-	Value float64
+	Var   string  `json:"var"`
+	Value float64 `json:"value"`
 }
 
 type Constraint struct {
-	Left  []Term
-	Right []Term
+	Left  []Term `json:"left,omitempty"`
+	Right []Term `json:"right,omitempty"`
 }
 
 // WriteConstraints writes LP constraints as a string (would normally be written
@@ -54,34 +57,15 @@ type Constraint struct {
 //  (w1-w3)*v1 + w2*v5 - w4*v7 <=0
 // WriteConstraints shifts the variables to one side, and converts the constraint
 // to a []byte (with the real values for wi substituted).
+//
+// WriteConstraints is WriteConstraintsTo with the output discarded, and with
+// the AllocateScratch option set unless preallocate is true. Use
+// WriteConstraintsTo and Options for any other behavior.
 func WriteConstraints(cons []Constraint, preallocate bool) {
-	names, nameMap := IndexVariables(cons)
-
-	// Temporary memory. constraintBytes overwrites and appends to b to reduce
-	// allocations.
-	var b []byte
-
-	// NOTE(btracey): This is the hotspot. If these variables are pre-allocated,
-	// then the GC does not run in the inner loop below, and a large chunck of
-	// the running time is saved.
-	var c1, c2 []float64
-	if preallocate {
-		c1 = make([]float64, len(names))
-		c2 = make([]float64, len(names))
-	}
-
-	// Write constraints
-	for _, c := range cons {
-		b = b[:0]
-		w := CondenseConstraint(c1, c2, c, nameMap)
-		con := 0.0
-		b = termBytes(b, w, names)
-		b = append(b, []byte(" <= ")...)
-
-		str := strconv.FormatFloat(con, 'g', 16, 64)
-		b = append(b, []byte(str)...)
-		b = append(b, []byte("\n")...)
-	}
+	// NOTE(btracey): This is the hotspot. If the scratch variables are
+	// pre-allocated, then the GC does not run in the inner loop, and a large
+	// chunck of the running time is saved.
+	WriteConstraintsTo(io.Discard, cons, &Options{AllocateScratch: !preallocate})
 }
 
 // termBytes appends all of the w_i * v_i terms.
@@ -92,14 +76,13 @@ func termBytes(b []byte, w []float64, names []string) []byte {
 			continue
 		}
 		if !first {
-			b = append(b, []byte(" + ")...)
+			b = append(b, " + "...)
 		} else {
 			first = false
 		}
-		str := strconv.FormatFloat(v, 'g', 16, 64)
-		b = append(b, []byte(str)...)
-		b = append(b, []byte(" ")...)
-		b = append(b, []byte(names[i])...)
+		b = strconv.AppendFloat(b, v, 'g', 16, 64)
+		b = append(b, ' ')
+		b = append(b, names[i]...)
 	}
 	return b
 }
@@ -134,23 +117,9 @@ func addNameIfNew(newName string, names []string, nameMap map[string]int) ([]str
 // CondenseTerms turns the slice of Term into a single weight vector where
 // the value is for the variable with index i.
 func CondenseTerms(w []float64, terms []Term, nameMap map[string]int) []float64 {
-	nVar := len(nameMap)
-	if w == nil {
-		w = make([]float64, nVar)
-	} else {
-		for i := range w {
-			w[i] = 0
-		}
-	}
-	if len(w) != nVar {
-		panic("lp: bad length")
-	}
-	for _, term := range terms {
-		idx, ok := nameMap[term.Var]
-		if !ok {
-			panic("lp: term not present in name map")
-		}
-		w[idx] += term.Value
+	w, err := CondenseTermsErr(w, terms, nameMap)
+	if err != nil {
+		panic(err.Error())
 	}
 	return w
 }
@@ -158,11 +127,11 @@ func CondenseTerms(w []float64, terms []Term, nameMap map[string]int) []float64
 // CondenseConstraints shifts all variables to the left hand side, and combines terms
 // with the same variable.
 func CondenseConstraint(wl, wr []float64, c Constraint, nameMap map[string]int) (w []float64) {
-	wl = CondenseTerms(wl, c.Left, nameMap)
-	wr = CondenseTerms(wr, c.Right, nameMap)
-
-	sub(wl, wr) // move the terms to the left hand side
-	return wl
+	w, err := CondenseConstraintErr(wl, wr, c, nameMap)
+	if err != nil {
+		panic(err.Error())
+	}
+	return w
 }
 
 // sub subtracts b from a
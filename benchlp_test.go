@@ -1,9 +1,23 @@
 package benchlp
 
 import (
-	"math/rand"
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
 	"strconv"
 	"testing"
+	"time"
+)
+
+var (
+	benchVars    = flag.Int("benchlp.nvars", 10000, "number of variables in the benchmark problems")
+	benchCons    = flag.Int("benchlp.ncons", 50000, "number of constraints in the benchmark problems")
+	benchDensity = flag.Float64("benchlp.density", 1, "mean of the exponential distribution of the number of extra terms on each side of a benchmark constraint")
+	benchResults = flag.String("benchlp.results", "", "file to write the results of BenchmarkLPSweep to, as JSON if the name ends in .json and CSV otherwise")
+	benchBudget  = flag.Duration("benchlp.budget", time.Second, "wall-clock budget of each operation of BenchmarkLPBudget")
 )
 
 func BenchmarkLPNoAllocate(b *testing.B) {
@@ -15,38 +29,134 @@ func BenchmarkLPAllocate(b *testing.B) {
 }
 
 func benchmarkLP(b *testing.B, preal bool) {
-	nVars := 10000
-	nConstraints := 50000
-	cons := randomConstraints(nVars, nConstraints)
+	cons := randomSparseConstraints(*benchVars, *benchCons, *benchDensity)
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
 		WriteConstraints(cons, preal)
 	}
 }
 
-// randomConstraints generats a random set of sparse constraints.
-func randomConstraints(nVars, nConstraints int) []Constraint {
-	rnd := rand.New(rand.NewSource(0))
-	var cons []Constraint
-	for i := 0; i < nConstraints; i++ {
-		con := Constraint{}
-
-		nRightVars := int(rnd.ExpFloat64()) + 1
-		for j := 0; j < nRightVars; j++ {
-			idx := rnd.Intn(nVars)
-			str := "v" + strconv.Itoa(idx)
-			con.Left = append(con.Left, Term{str, rnd.Float64()})
+// budgetCheck is the number of constraints written between checks of the
+// clock by BenchmarkLPBudget.
+const budgetCheck = 256
+
+// BenchmarkLPBudget renders as many constraints as it can in the time given
+// by -benchlp.budget, cycling through the same generated problem, and reports
+// the rates as constraints/s and MB/s. The rates depend less on the problem
+// size than ns/op does, so they compare more stably across machines. Each
+// operation takes the whole budget, so ns/op is not meaningful.
+func BenchmarkLPBudget(b *testing.B) {
+	cons := randomSparseConstraints(*benchVars, *benchCons, *benchDensity)
+	names, nameMap := IndexVariables(cons)
+	r := NewRenderer(names, nameMap, nil, nil, nil, nil)
+	var n, bytes int64
+	var elapsed time.Duration
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		start := time.Now()
+		deadline := start.Add(*benchBudget)
+		for j := 0; ; j++ {
+			if j%budgetCheck == 0 && !time.Now().Before(deadline) {
+				break
+			}
+			bytes += int64(len(r.Render(cons[j%len(cons)])))
+			n++
 		}
+		elapsed += time.Since(start)
+	}
+	sec := elapsed.Seconds()
+	b.ReportMetric(float64(n)/sec, "constraints/s")
+	b.ReportMetric(float64(bytes)/1e6/sec, "MB/s")
+}
+
+// sweepResult is the result of one BenchmarkLPSweep sub-benchmark.
+type sweepResult struct {
+	Vars            int     `json:"nvars"`
+	Constraints     int     `json:"ncons"`
+	Density         float64 `json:"density"`
+	N               int     `json:"n"`
+	NsPerOp         float64 `json:"ns_per_op"`
+	NsPerConstraint float64 `json:"ns_per_constraint"`
+}
 
-		nLeftVars := int(rnd.ExpFloat64()) + 1
-		for j := 0; j < nLeftVars; j++ {
-			idx := rnd.Intn(nVars)
-			str := "v" + strconv.Itoa(idx)
-			con.Right = append(con.Right, Term{str, rnd.Float64()})
+// BenchmarkLPSweep runs WriteConstraints, with preallocation, over a range of
+// problem sizes and densities, so the scaling of the dense condenser with the
+// number of variables can be seen in one run. With -benchlp.results the
+// results are also written to a file.
+func BenchmarkLPSweep(b *testing.B) {
+	var results []sweepResult
+	for _, nVars := range []int{1000, 10000, 100000} {
+		for _, nCons := range []int{1000, 10000} {
+			for _, density := range []float64{1, 10} {
+				res := sweepResult{Vars: nVars, Constraints: nCons, Density: density}
+				name := fmt.Sprintf("nvars=%d/ncons=%d/density=%g", nVars, nCons, density)
+				b.Run(name, func(b *testing.B) {
+					cons := randomSparseConstraints(nVars, nCons, density)
+					b.ResetTimer()
+					for i := 0; i < b.N; i++ {
+						WriteConstraints(cons, true)
+					}
+					// The last run has the largest b.N, so its result is kept.
+					res.N = b.N
+					res.NsPerOp = float64(b.Elapsed().Nanoseconds()) / float64(b.N)
+					res.NsPerConstraint = res.NsPerOp / float64(nCons)
+				})
+				if res.N > 0 {
+					results = append(results, res)
+				}
+			}
+		}
+	}
+	if *benchResults != "" {
+		if err := writeSweepResults(*benchResults, results); err != nil {
+			b.Fatal(err)
 		}
-		cons = append(cons, con)
 	}
-	return cons
+}
+
+// writeSweepResults writes the results to the named file as JSON or CSV.
+func writeSweepResults(name string, results []sweepResult) error {
+	f, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	if filepath.Ext(name) == ".json" {
+		enc := json.NewEncoder(f)
+		enc.SetIndent("", "\t")
+		err = enc.Encode(results)
+	} else {
+		w := csv.NewWriter(f)
+		w.Write([]string{"nvars", "ncons", "density", "n", "ns_per_op", "ns_per_constraint"})
+		for _, r := range results {
+			w.Write([]string{
+				strconv.Itoa(r.Vars),
+				strconv.Itoa(r.Constraints),
+				strconv.FormatFloat(r.Density, 'g', -1, 64),
+				strconv.Itoa(r.N),
+				strconv.FormatFloat(r.NsPerOp, 'f', 0, 64),
+				strconv.FormatFloat(r.NsPerConstraint, 'f', 1, 64),
+			})
+		}
+		w.Flush()
+		err = w.Error()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// randomConstraints generats a random set of sparse constraints.
+func randomConstraints(nVars, nConstraints int) []Constraint {
+	return randomSparseConstraints(nVars, nConstraints, 1)
+}
+
+// randomSparseConstraints generates a random set of sparse constraints. The
+// number of terms on each side is one plus the integer part of an exponential
+// random variable with the given mean.
+func randomSparseConstraints(nVars, nConstraints int, density float64) []Constraint {
+	g := &Generator{Vars: nVars, Density: density}
+	return g.Generate(nConstraints)
 }
 
 func BenchmarkAllocate(b *testing.B) {
@@ -58,13 +168,13 @@ func BenchmarkAllocateNoCons(b *testing.B) {
 }
 
 func benchmarkNolp(b *testing.B, withcons bool) {
-	nVars := 10000
-	nConstraints := 50000
+	nVars := *benchVars
+	nConstraints := *benchCons
 	cons := []Constraint{
 		{Left: []Term{{"a", 1}}},
 	}
 	if withcons {
-		cons = randomConstraints(nVars, nConstraints)
+		cons = randomSparseConstraints(nVars, nConstraints, *benchDensity)
 	}
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
@@ -0,0 +1,320 @@
+/*
+Copyright 2017 Brendan Tracey
+
+Redistribution and use in source and binary forms, with or without modification,
+are permitted provided that the following conditions are met:
+
+1. Redistributions of source code must retain the above copyright notice, this
+list of conditions and the following disclaimer.
+
+2. Redistributions in binary form must reproduce the above copyright notice,
+this list of conditions and the following disclaimer in the documentation and/or
+other materials provided with the distribution.
+
+3. Neither the name of the copyright holder nor the names of its contributors may
+be used to endorse or promote products derived from this software without specific
+prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED.
+IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT,
+INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF
+LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE
+OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED
+OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package benchlp
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+	"slices"
+)
+
+// binaryMagic identifies the binary snapshot format and its version.
+const binaryMagic = "BLP\x01"
+
+// WriteBinary writes the constraints to w in a compact binary format that
+// can be read with ReadBinary. The format is
+//
+//	magic      "BLP\x01"
+//	nNames     uvarint
+//	names      nNames × (uvarint length, bytes)
+//	nCons      uvarint
+//	cons       nCons × (left terms, right terms)
+//
+// where each list of terms is a uvarint count followed by, for each term, the
+// uvarint index of its variable in names and the little-endian IEEE 754 bits
+// of its value.
+func WriteBinary(w io.Writer, cons []Constraint) error {
+	names, nameMap := IndexVariables(cons)
+	bw := bufio.NewWriter(w)
+	bw.WriteString(binaryMagic)
+
+	var buf [binary.MaxVarintLen64]byte
+	putUvarint := func(v uint64) {
+		n := binary.PutUvarint(buf[:], v)
+		bw.Write(buf[:n])
+	}
+	putTerms := func(terms []Term) {
+		putUvarint(uint64(len(terms)))
+		for _, term := range terms {
+			putUvarint(uint64(nameMap[term.Var]))
+			binary.LittleEndian.PutUint64(buf[:8], math.Float64bits(term.Value))
+			bw.Write(buf[:8])
+		}
+	}
+
+	putUvarint(uint64(len(names)))
+	for _, name := range names {
+		putUvarint(uint64(len(name)))
+		bw.WriteString(name)
+	}
+	putUvarint(uint64(len(cons)))
+	for _, c := range cons {
+		putTerms(c.Left)
+		putTerms(c.Right)
+	}
+	return bw.Flush()
+}
+
+// ReadBinary reads constraints written by WriteBinary. Terms with the same
+// variable share the storage for its name.
+func ReadBinary(r io.Reader) ([]Constraint, error) {
+	return ReadBinaryContext(context.Background(), r)
+}
+
+// ReadBinaryContext is like ReadBinary, but stops reading if ctx is
+// cancelled, checking after every 1024 constraints. On cancellation it
+// returns ctx.Err() and no constraints, and r is left partway through the
+// data.
+func ReadBinaryContext(ctx context.Context, r io.Reader) ([]Constraint, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	br, err := newBinaryReader(r)
+	if err != nil {
+		return nil, err
+	}
+	done := ctx.Done()
+	cons := make([]Constraint, 0, min(br.nCons, 1<<20))
+	for br.remaining > 0 {
+		if done != nil && len(cons)%cancelInterval == 0 {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+		}
+		c, err := br.next()
+		if err != nil {
+			return nil, err
+		}
+		cons = append(cons, c)
+	}
+	return cons, nil
+}
+
+// BinaryScanner reads constraints written by WriteBinary in batches, so
+// that data larger than memory can be filtered, rewritten, or summarized a
+// batch at a time. Only the variable names and the current batch are held
+// in memory. Its use follows bufio.Scanner:
+//
+//	s, err := NewBinaryScanner(f, 1<<16)
+//	...
+//	for s.Scan() {
+//		for _, c := range s.Batch() {
+//			...
+//		}
+//	}
+//	if err := s.Err(); err != nil {
+//		...
+//	}
+type BinaryScanner struct {
+	br    *binaryReader
+	size  int
+	batch []Constraint
+	index int
+	err   error
+}
+
+// NewBinaryScanner reads the header and variable names of the binary data
+// in r and returns a BinaryScanner that reads its constraints in batches of
+// at most size.
+func NewBinaryScanner(r io.Reader, size int) (*BinaryScanner, error) {
+	if size <= 0 {
+		panic("lp: non-positive batch size")
+	}
+	br, err := newBinaryReader(r)
+	if err != nil {
+		return nil, err
+	}
+	return &BinaryScanner{br: br, size: size}, nil
+}
+
+// Scan reads the next batch of constraints, which is then available from
+// Batch. It returns false when the constraints are exhausted or an error
+// occurs.
+func (s *BinaryScanner) Scan() bool {
+	s.index += len(s.batch)
+	s.batch = s.batch[:0]
+	if s.err != nil {
+		return false
+	}
+	for s.br.remaining > 0 && len(s.batch) < s.size {
+		c, err := s.br.next()
+		if err != nil {
+			s.err = err
+			s.batch = s.batch[:0]
+			return false
+		}
+		s.batch = append(s.batch, c)
+	}
+	return len(s.batch) > 0
+}
+
+// Batch returns the constraints read by the last call to Scan. The slice is
+// reused by the next call to Scan, but the constraints are not.
+func (s *BinaryScanner) Batch() []Constraint { return s.batch }
+
+// Index returns the index in the data of the first constraint of the batch.
+func (s *BinaryScanner) Index() int { return s.index }
+
+// Len returns the total number of constraints in the data.
+func (s *BinaryScanner) Len() int { return s.br.nCons }
+
+// Names returns the variable names of the data, in the order they were
+// indexed when written.
+func (s *BinaryScanner) Names() []string { return s.br.names }
+
+// Err returns the first error encountered while scanning.
+func (s *BinaryScanner) Err() error { return s.err }
+
+var errBinaryFormat = errors.New("lp: malformed binary data")
+
+// binaryReader decodes the binary format one constraint at a time.
+type binaryReader struct {
+	r         *bufio.Reader
+	names     []string
+	nCons     int
+	remaining int
+}
+
+// newBinaryReader reads the header and name table of the binary format.
+func newBinaryReader(r io.Reader) (*binaryReader, error) {
+	br := &binaryReader{r: bufio.NewReader(r)}
+	var magic [len(binaryMagic)]byte
+	if _, err := io.ReadFull(br.r, magic[:]); err != nil {
+		return nil, unexpectedEOF(err)
+	}
+	if string(magic[:]) != binaryMagic {
+		return nil, errBinaryFormat
+	}
+	nNames, err := br.uvarint()
+	if err != nil {
+		return nil, err
+	}
+	br.names = make([]string, 0, min(nNames, 1<<20))
+	var b []byte
+	for i := 0; i < nNames; i++ {
+		n, err := br.uvarint()
+		if err != nil {
+			return nil, err
+		}
+		if b, err = br.read(b[:0], n); err != nil {
+			return nil, err
+		}
+		br.names = append(br.names, string(b))
+	}
+	br.nCons, err = br.uvarint()
+	if err != nil {
+		return nil, err
+	}
+	br.remaining = br.nCons
+	return br, nil
+}
+
+// next decodes the next constraint.
+func (br *binaryReader) next() (Constraint, error) {
+	left, err := br.terms()
+	if err != nil {
+		return Constraint{}, err
+	}
+	right, err := br.terms()
+	if err != nil {
+		return Constraint{}, err
+	}
+	br.remaining--
+	return Constraint{Left: left, Right: right}, nil
+}
+
+// terms decodes a list of terms.
+func (br *binaryReader) terms() ([]Term, error) {
+	n, err := br.uvarint()
+	if err != nil || n == 0 {
+		return nil, err
+	}
+	terms := make([]Term, 0, min(n, 1<<16))
+	var buf [8]byte
+	for i := 0; i < n; i++ {
+		idx, err := br.uvarint()
+		if err != nil {
+			return nil, err
+		}
+		if idx >= len(br.names) {
+			return nil, errBinaryFormat
+		}
+		if _, err := io.ReadFull(br.r, buf[:]); err != nil {
+			return nil, unexpectedEOF(err)
+		}
+		v := math.Float64frombits(binary.LittleEndian.Uint64(buf[:]))
+		terms = append(terms, Term{br.names[idx], v})
+	}
+	return terms, nil
+}
+
+// binaryChunk is the most read at once for a length in the data, so that a
+// corrupt length fails at the end of the data rather than allocating it
+// all.
+const binaryChunk = 64 << 10
+
+// read appends n bytes of the data to b.
+func (br *binaryReader) read(b []byte, n int) ([]byte, error) {
+	for n > 0 {
+		k := min(n, binaryChunk)
+		b = slices.Grow(b, k)
+		if _, err := io.ReadFull(br.r, b[len(b):len(b)+k]); err != nil {
+			return b, unexpectedEOF(err)
+		}
+		b = b[:len(b)+k]
+		n -= k
+	}
+	return b, nil
+}
+
+// uvarint decodes a length or index.
+func (br *binaryReader) uvarint() (int, error) {
+	v, err := binary.ReadUvarint(br.r)
+	if err != nil {
+		return 0, unexpectedEOF(err)
+	}
+	if v > math.MaxInt32 {
+		return 0, errBinaryFormat
+	}
+	return int(v), nil
+}
+
+// unexpectedEOF converts io.EOF into io.ErrUnexpectedEOF, since the data
+// ended before the constraints were complete.
+func unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
@@ -0,0 +1,114 @@
+package benchlp
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math"
+	"reflect"
+	"runtime"
+	"testing"
+)
+
+func TestBinaryRoundTrip(t *testing.T) {
+	cons := randomConstraints(100, 200)
+	cons = append(cons, Constraint{}, Constraint{Right: []Term{{"", -1}}})
+	var buf bytes.Buffer
+	if err := WriteBinary(&buf, cons); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+	got, err := ReadBinary(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, cons) {
+		t.Errorf("round trip mismatch")
+	}
+
+	for i := 0; i < len(data); i += 97 {
+		_, err := ReadBinary(bytes.NewReader(data[:i]))
+		if err == nil {
+			t.Errorf("no error reading %d of %d bytes", i, len(data))
+		}
+		if i > 0 && err != io.ErrUnexpectedEOF && err != errBinaryFormat {
+			t.Errorf("unexpected error reading %d of %d bytes: %v", i, len(data), err)
+		}
+	}
+}
+
+func TestBinaryCorruptLength(t *testing.T) {
+	// A name claiming 2 GiB, followed by a few bytes.
+	data := binary.AppendUvarint([]byte(binaryMagic), 1)
+	data = binary.AppendUvarint(data, math.MaxInt32)
+	data = append(data, "abc"...)
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	_, err := ReadBinary(bytes.NewReader(data))
+	runtime.ReadMemStats(&after)
+	if err != io.ErrUnexpectedEOF {
+		t.Errorf("got error %v, want %v", err, io.ErrUnexpectedEOF)
+	}
+	if n := after.TotalAlloc - before.TotalAlloc; n > 1<<20 {
+		t.Errorf("allocated %d bytes", n)
+	}
+}
+
+func TestBinaryScanner(t *testing.T) {
+	cons := randomConstraints(50, 250)
+	var buf bytes.Buffer
+	if err := WriteBinary(&buf, cons); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+	s, err := NewBinaryScanner(bytes.NewReader(data), 64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Len() != len(cons) {
+		t.Errorf("got length %d, want %d", s.Len(), len(cons))
+	}
+	var got []Constraint
+	var batches int
+	for s.Scan() {
+		if s.Index() != len(got) {
+			t.Errorf("batch %d: got index %d, want %d", batches, s.Index(), len(got))
+		}
+		got = append(got, s.Batch()...)
+		batches++
+	}
+	if err := s.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if batches != 4 || !reflect.DeepEqual(got, cons) {
+		t.Errorf("read %d constraints in %d batches, want %d in 4", len(got), batches, len(cons))
+	}
+
+	s, err = NewBinaryScanner(bytes.NewReader(data[:len(data)-1]), 64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for s.Scan() {
+	}
+	if s.Err() != io.ErrUnexpectedEOF {
+		t.Errorf("truncated data: got error %v", s.Err())
+	}
+}
+
+func BenchmarkWriteBinary(b *testing.B) {
+	cons := randomConstraints(10000, 50000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		WriteBinary(io.Discard, cons)
+	}
+}
+
+func BenchmarkReadBinary(b *testing.B) {
+	var buf bytes.Buffer
+	WriteBinary(&buf, randomConstraints(10000, 50000))
+	data := buf.Bytes()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ReadBinary(bytes.NewReader(data))
+	}
+}
@@ -0,0 +1,123 @@
+/*
+Copyright 2017 Brendan Tracey
+
+Redistribution and use in source and binary forms, with or without modification,
+are permitted provided that the following conditions are met:
+
+1. Redistributions of source code must retain the above copyright notice, this
+list of conditions and the following disclaimer.
+
+2. Redistributions in binary form must reproduce the above copyright notice,
+this list of conditions and the following disclaimer in the documentation and/or
+other materials provided with the distribution.
+
+3. Neither the name of the copyright holder nor the names of its contributors may
+be used to endorse or promote products derived from this software without specific
+prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED.
+IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT,
+INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF
+LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE
+OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED
+OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package benchlp
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// Builder collects constraints from multiple goroutines. AddConstraint is
+// safe for concurrent use; the constraints are spread across shards with
+// separate locks so that concurrent callers rarely contend.
+//
+// The zero value is ready to use, with no limits.
+type Builder struct {
+	// Limits bounds the constraints and nonzeros that may be added.
+	// MaxBytes is ignored. Limits must not be changed once constraints
+	// have been added.
+	Limits Limits
+
+	once   sync.Once
+	next   uint32
+	shards []builderShard
+	n, nnz atomic.Int64
+}
+
+type builderShard struct {
+	mu   sync.Mutex
+	cons []Constraint
+	_    [64]byte // keep shards on separate cache lines
+}
+
+// AddConstraint adds a constraint to the builder. It panics if the
+// constraint would exceed the limits.
+func (b *Builder) AddConstraint(c Constraint) {
+	if err := b.AddConstraintErr(c); err != nil {
+		panic(err.Error())
+	}
+}
+
+// AddConstraintErr is like AddConstraint, but returns a *LimitError, and
+// does not add the constraint, if it would exceed the limits.
+func (b *Builder) AddConstraintErr(c Constraint) error {
+	if err := b.reserve(c); err != nil {
+		return err
+	}
+	b.once.Do(b.init)
+	s := &b.shards[atomic.AddUint32(&b.next, 1)%uint32(len(b.shards))]
+	s.mu.Lock()
+	s.cons = append(s.cons, c)
+	s.mu.Unlock()
+	return nil
+}
+
+// reserve counts the constraint against the limits, returning an error and
+// leaving the counts unchanged if it exceeds them.
+func (b *Builder) reserve(c Constraint) error {
+	if l := int64(b.Limits.MaxConstraints); l > 0 {
+		if b.n.Add(1) > l {
+			b.n.Add(-1)
+			return &LimitError{"constraints", l}
+		}
+	}
+	if l := int64(b.Limits.MaxNonzeros); l > 0 {
+		k := int64(len(c.Left) + len(c.Right))
+		if b.nnz.Add(k) > l {
+			b.nnz.Add(-k)
+			if b.Limits.MaxConstraints > 0 {
+				b.n.Add(-1)
+			}
+			return &LimitError{"nonzeros", l}
+		}
+	}
+	return nil
+}
+
+// Constraints returns the constraints added so far. Constraints added by a
+// single goroutine are not guaranteed to be returned in the order they were
+// added. Constraints must not be called concurrently with AddConstraint.
+func (b *Builder) Constraints() []Constraint {
+	b.once.Do(b.init)
+	var n int
+	for i := range b.shards {
+		n += len(b.shards[i].cons)
+	}
+	cons := make([]Constraint, 0, n)
+	for i := range b.shards {
+		cons = append(cons, b.shards[i].cons...)
+	}
+	return cons
+}
+
+func (b *Builder) init() {
+	b.shards = make([]builderShard, 4*runtime.GOMAXPROCS(0))
+}
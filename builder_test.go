@@ -0,0 +1,48 @@
+package benchlp
+
+import (
+	"sort"
+	"sync"
+	"testing"
+)
+
+func TestBuilder(t *testing.T) {
+	var b Builder
+	var wg sync.WaitGroup
+	workers, n := 8, 1000
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < n; i++ {
+				b.AddConstraint(Constraint{Left: []Term{{"v", float64(w*n + i)}}})
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	cons := b.Constraints()
+	if len(cons) != workers*n {
+		t.Fatalf("got %d constraints, want %d", len(cons), workers*n)
+	}
+	values := make([]float64, len(cons))
+	for i, c := range cons {
+		values[i] = c.Left[0].Value
+	}
+	sort.Float64s(values)
+	for i, v := range values {
+		if v != float64(i) {
+			t.Fatalf("constraint %d missing", i)
+		}
+	}
+}
+
+func BenchmarkBuilder(b *testing.B) {
+	var bl Builder
+	c := Constraint{Left: []Term{{"v", 1}}}
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			bl.AddConstraint(c)
+		}
+	})
+}
@@ -0,0 +1,169 @@
+/*
+Copyright 2017 Brendan Tracey
+
+Redistribution and use in source and binary forms, with or without modification,
+are permitted provided that the following conditions are met:
+
+1. Redistributions of source code must retain the above copyright notice, this
+list of conditions and the following disclaimer.
+
+2. Redistributions in binary form must reproduce the above copyright notice,
+this list of conditions and the following disclaimer in the documentation and/or
+other materials provided with the distribution.
+
+3. Neither the name of the copyright holder nor the names of its contributors may
+be used to endorse or promote products derived from this software without specific
+prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED.
+IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT,
+INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF
+LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE
+OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED
+OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package benchlp
+
+import (
+	"encoding/binary"
+	"hash/maphash"
+	"math"
+)
+
+// RenderCache memoizes the formatted lines of constraints across writes, so
+// that writing a slightly changed set of constraints again only formats the
+// rows that changed. Set it as Options.Cache. Rows are looked up by a
+// 128-bit hash of their terms as given, and a cached line is used only if
+// the variables of the row are still in the same relative order, so the
+// output is the same as without the cache. After each complete write, the
+// lines of constraints that were not written are discarded.
+//
+// A RenderCache must only be used with one set of formatting options,
+// including VarScale and DropTolerance, and is not used when Options.RHS or
+// Options.Names is set, as the lines would depend on the other constraints
+// of the write. It is not safe for concurrent use.
+type RenderCache struct {
+	// Hits and Misses count the lookups since the cache was created.
+	Hits, Misses int
+
+	seeds   [2]maphash.Seed
+	entries map[[2]uint64]*cacheEntry
+	gen     int
+	b       []byte
+}
+
+// cacheEntry is a cached line, with the variables of its non-zero
+// coefficients in index order at the time it was rendered.
+type cacheEntry struct {
+	line    []byte
+	vars    []string
+	dropped int
+	gen     int
+}
+
+// NewRenderCache returns an empty RenderCache.
+func NewRenderCache() *RenderCache {
+	return &RenderCache{
+		seeds:   [2]maphash.Seed{maphash.MakeSeed(), maphash.MakeSeed()},
+		entries: make(map[[2]uint64]*cacheEntry),
+	}
+}
+
+// Len returns the number of cached lines.
+func (rc *RenderCache) Len() int { return len(rc.entries) }
+
+// key returns the hash of the terms of the constraint.
+func (rc *RenderCache) key(c Constraint) [2]uint64 {
+	b := rc.b[:0]
+	for _, terms := range [2][]Term{c.Left, c.Right} {
+		b = binary.AppendUvarint(b, uint64(len(terms)))
+		for _, term := range terms {
+			b = binary.AppendUvarint(b, uint64(len(term.Var)))
+			b = append(b, term.Var...)
+			b = binary.LittleEndian.AppendUint64(b, math.Float64bits(term.Value))
+		}
+	}
+	rc.b = b
+	return [2]uint64{maphash.Bytes(rc.seeds[0], b), maphash.Bytes(rc.seeds[1], b)}
+}
+
+// useCache returns whether the Cache option is used. The lines are cached by
+// the terms of the constraint, so the cache cannot be used when a line also
+// depends on the right hand sides of RHS or on names that SanitizeNames
+// made unique across the variables.
+func (o *Options) useCache() bool {
+	return o.Cache != nil && o.RHS == nil && o.Names.Legal == nil
+}
+
+// start begins a write.
+func (rc *RenderCache) start() {
+	rc.gen++
+}
+
+// sweep discards the lines not used since the write began.
+func (rc *RenderCache) sweep() {
+	for k, e := range rc.entries {
+		if e.gen != rc.gen {
+			delete(rc.entries, k)
+		}
+	}
+}
+
+// cached appends the cached line for the constraint to b, if there is a
+// valid one, and otherwise remembers the constraint for cacheLine.
+func (r *Renderer) cached(b []byte, c Constraint) ([]byte, bool) {
+	rc := r.opts.Cache
+	r.key = rc.key(c)
+	e, ok := rc.entries[r.key]
+	if ok && r.inOrder(e.vars) {
+		rc.Hits++
+		e.gen = rc.gen
+		r.dropped = e.dropped
+		return append(b, e.line...), true
+	}
+	rc.Misses++
+	return b, false
+}
+
+// cacheLine caches the line rendered from the weights wl and wr for the
+// constraint last passed to cached.
+func (r *Renderer) cacheLine(line []byte, wl, wr []float64) {
+	var vars []string
+	for i, v := range wl {
+		if v != 0 || wr != nil && wr[i] != 0 {
+			vars = append(vars, r.names[i])
+		}
+	}
+	rc := r.opts.Cache
+	rc.entries[r.key] = &cacheEntry{
+		line:    append([]byte(nil), line...),
+		vars:    vars,
+		dropped: r.dropped,
+		gen:     rc.gen,
+	}
+}
+
+// inOrder returns whether the variables are in increasing order of their
+// current indices.
+func (r *Renderer) inOrder(vars []string) bool {
+	last := -1
+	for _, name := range vars {
+		var i int
+		var ok bool
+		if r.opts.Indexer != nil {
+			i, ok = r.opts.Indexer.Index(name)
+		} else {
+			i, ok = r.nameMap[name]
+		}
+		if !ok || i <= last {
+			return false
+		}
+		last = i
+	}
+	return true
+}
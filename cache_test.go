@@ -0,0 +1,119 @@
+package benchlp
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+func TestRenderCache(t *testing.T) {
+	cons := randomConstraints(100, 1000)
+	for _, report := range []bool{false, true} {
+		rc := NewRenderCache()
+		write := func(cons []Constraint) {
+			t.Helper()
+			opts := &Options{Cache: rc}
+			if report {
+				opts.Report = &Report{}
+			}
+			var got, want bytes.Buffer
+			if err := WriteConstraintsTo(&got, cons, opts); err != nil {
+				t.Fatal(err)
+			}
+			if err := WriteConstraintsTo(&want, cons, nil); err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got.Bytes(), want.Bytes()) {
+				t.Fatalf("report %v: cached output differs", report)
+			}
+		}
+
+		write(cons)
+		if rc.Hits != 0 || rc.Misses != len(cons) || rc.Len() != len(cons) {
+			t.Errorf("first write: %d hits, %d misses, %d lines", rc.Hits, rc.Misses, rc.Len())
+		}
+		changed := CloneConstraints(cons)
+		for i := 0; i < len(changed); i += 100 {
+			changed[i].Left[0].Value++
+		}
+		write(changed)
+		if rc.Hits != len(cons)-10 || rc.Misses != len(cons)+10 {
+			t.Errorf("second write: %d hits, %d misses", rc.Hits, rc.Misses)
+		}
+		if rc.Len() != len(cons) {
+			t.Errorf("unused lines kept: %d lines", rc.Len())
+		}
+	}
+
+	// The cached line of the second row has a before b, which is out of
+	// date once b appears first.
+	rc := NewRenderCache()
+	var buf bytes.Buffer
+	cons = []Constraint{
+		{Left: []Term{{"a", 1}, {"b", 2}}},
+		{Left: []Term{{"b", 3}, {"a", 4}}},
+	}
+	if err := WriteConstraintsTo(io.Discard, cons, &Options{Cache: rc}); err != nil {
+		t.Fatal(err)
+	}
+	cons[0] = Constraint{Left: []Term{{"b", 1}, {"a", 2}}}
+	if err := WriteConstraintsTo(&buf, cons, &Options{Cache: rc}); err != nil {
+		t.Fatal(err)
+	}
+	if want := "2 a + 1 b <= 0\n4 a + 3 b <= 0\n"; buf.String() == want {
+		t.Errorf("stale line used")
+	}
+	if want := "1 b + 2 a <= 0\n3 b + 4 a <= 0\n"; buf.String() != want {
+		t.Errorf("got\n%s\nwant\n%s", buf.String(), want)
+	}
+}
+
+func TestRenderCacheNames(t *testing.T) {
+	// "a b" is written as a_b until a variable a_b appears, and a_b_1
+	// after.
+	rc := NewRenderCache()
+	opts := &Options{Cache: rc, Names: LPNames}
+	z := []Term{{"z", 1}}
+	if err := WriteConstraintsTo(io.Discard, []Constraint{{Left: []Term{{"a b", 1}}, Right: z}}, opts); err != nil {
+		t.Fatal(err)
+	}
+	cons := []Constraint{
+		{Left: []Term{{"a_b", 1}}},
+		{Left: []Term{{"a b", 1}}, Right: z},
+	}
+	var got, want bytes.Buffer
+	if err := WriteConstraintsTo(&got, cons, opts); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteConstraintsTo(&want, cons, &Options{Names: LPNames}); err != nil {
+		t.Fatal(err)
+	}
+	if got.String() != want.String() {
+		t.Errorf("got\n%s\nwant\n%s", got.String(), want.String())
+	}
+}
+
+func BenchmarkWriteCached(b *testing.B) {
+	cons := randomSparseConstraints(10000, 20000, 0.001)
+	changed := CloneConstraints(cons)
+	for i := 0; i < len(changed); i += 100 {
+		changed[i].Left[0].Value++
+	}
+	b.Run("Uncached", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			WriteConstraintsTo(io.Discard, changed, nil)
+		}
+	})
+	b.Run("Cached", func(b *testing.B) {
+		rc := NewRenderCache()
+		WriteConstraintsTo(io.Discard, cons, &Options{Cache: rc})
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			set := cons
+			if i%2 == 0 {
+				set = changed
+			}
+			WriteConstraintsTo(io.Discard, set, &Options{Cache: rc})
+		}
+	})
+}
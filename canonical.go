@@ -0,0 +1,184 @@
+/*
+Copyright 2017 Brendan Tracey
+
+Redistribution and use in source and binary forms, with or without modification,
+are permitted provided that the following conditions are met:
+
+1. Redistributions of source code must retain the above copyright notice, this
+list of conditions and the following disclaimer.
+
+2. Redistributions in binary form must reproduce the above copyright notice,
+this list of conditions and the following disclaimer in the documentation and/or
+other materials provided with the distribution.
+
+3. Neither the name of the copyright holder nor the names of its contributors may
+be used to endorse or promote products derived from this software without specific
+prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED.
+IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT,
+INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF
+LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE
+OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED
+OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package benchlp
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"math"
+	"slices"
+	"sort"
+)
+
+// Canonicalize returns the canonical form of the constraint, in which all of
+// the terms are on the left hand side, compared against zero. Terms with the
+// same variable are combined, terms whose coefficients sum to zero are
+// dropped, and the remaining terms are sorted by variable name. Constraints
+// with the same canonical form represent the same inequality.
+func Canonicalize(c Constraint) Constraint {
+	coeffs := make(map[string]float64, len(c.Left)+len(c.Right))
+	for _, term := range c.Left {
+		coeffs[term.Var] += term.Value
+	}
+	for _, term := range c.Right {
+		coeffs[term.Var] -= term.Value
+	}
+	var canon Constraint
+	for name, v := range coeffs {
+		if v != 0 {
+			canon.Left = append(canon.Left, Term{name, v})
+		}
+	}
+	sort.Slice(canon.Left, func(i, j int) bool {
+		return canon.Left[i].Var < canon.Left[j].Var
+	})
+	return canon
+}
+
+// Equal returns whether the constraints have the same canonical form, with
+// coefficients of each variable differing by at most tol. A variable missing
+// from one constraint has a coefficient of zero.
+func Equal(a, b Constraint, tol float64) bool {
+	ca := Canonicalize(a).Left
+	cb := Canonicalize(b).Left
+	for len(ca) > 0 || len(cb) > 0 {
+		var va, vb float64
+		switch {
+		case len(cb) == 0 || len(ca) > 0 && ca[0].Var < cb[0].Var:
+			va = ca[0].Value
+			ca = ca[1:]
+		case len(ca) == 0 || cb[0].Var < ca[0].Var:
+			vb = cb[0].Value
+			cb = cb[1:]
+		default:
+			va, vb = ca[0].Value, cb[0].Value
+			ca, cb = ca[1:], cb[1:]
+		}
+		if !(math.Abs(va-vb) <= tol) {
+			return false
+		}
+	}
+	return true
+}
+
+// CoalesceTerms returns the terms with those of the same variable combined,
+// in order of the first appearance of each variable. Combined coefficients
+// whose magnitude is at most tol are dropped, so a tol of zero drops only
+// the terms that cancel exactly. The input is not modified.
+func CoalesceTerms(terms []Term, tol float64) []Term {
+	idx := make(map[string]int, len(terms))
+	var out []Term
+	for _, term := range terms {
+		if i, ok := idx[term.Var]; ok {
+			out[i].Value += term.Value
+			continue
+		}
+		idx[term.Var] = len(out)
+		out = append(out, term)
+	}
+	n := 0
+	for _, term := range out {
+		if math.Abs(term.Value) > tol {
+			out[n] = term
+			n++
+		}
+	}
+	return out[:n]
+}
+
+// EqualConstraints returns whether a and b have the same length and each pair
+// of constraints is Equal with the tolerance.
+func EqualConstraints(a, b []Constraint, tol float64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !Equal(a[i], b[i], tol) {
+			return false
+		}
+	}
+	return true
+}
+
+// CloneConstraints returns a deep copy of the constraints, with all of the
+// terms in a single new backing array.
+func CloneConstraints(cons []Constraint) []Constraint {
+	var n int
+	for _, c := range cons {
+		n += len(c.Left) + len(c.Right)
+	}
+	terms := make([]Term, 0, n)
+	clone := make([]Constraint, len(cons))
+	for i, c := range cons {
+		if c.Left != nil {
+			start := len(terms)
+			terms = append(terms, c.Left...)
+			clone[i].Left = terms[start:len(terms):len(terms)]
+		}
+		if c.Right != nil {
+			start := len(terms)
+			terms = append(terms, c.Right...)
+			clone[i].Right = terms[start:len(terms):len(terms)]
+		}
+	}
+	return clone
+}
+
+// Fingerprint returns a SHA-256 hash of the canonical forms of the
+// constraints, so that sets of constraints can be compared, or cached
+// results looked up, by a short key. Sets with the same canonical forms, in
+// any order, have the same fingerprint, so it does not depend on the order
+// of the terms or the constraints, on which side terms are written, or on
+// the options they are written with. A constraint that appears twice counts
+// twice.
+func Fingerprint(cons []Constraint) [sha256.Size]byte {
+	rows := make([][sha256.Size]byte, len(cons))
+	var b []byte
+	for i, c := range cons {
+		b = b[:0]
+		for _, term := range Canonicalize(c).Left {
+			b = binary.AppendUvarint(b, uint64(len(term.Var)))
+			b = append(b, term.Var...)
+			b = binary.LittleEndian.AppendUint64(b, math.Float64bits(term.Value))
+		}
+		rows[i] = sha256.Sum256(b)
+	}
+	slices.SortFunc(rows, func(a, b [sha256.Size]byte) int {
+		return bytes.Compare(a[:], b[:])
+	})
+	h := sha256.New()
+	for _, r := range rows {
+		h.Write(r[:])
+	}
+	var sum [sha256.Size]byte
+	h.Sum(sum[:0])
+	return sum
+}
@@ -0,0 +1,187 @@
+package benchlp
+
+import (
+	"bytes"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCanonicalize(t *testing.T) {
+	c := Constraint{
+		Left:  []Term{{"b", 1}, {"a", 2}, {"c", 1}},
+		Right: []Term{{"c", 1}, {"d", 3}, {"a", 0.5}},
+	}
+	want := Constraint{Left: []Term{{"a", 1.5}, {"b", 1}, {"d", -3}}}
+	if got := Canonicalize(c); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	if !Equal(c, want, 0) {
+		t.Errorf("constraint not equal to its canonical form")
+	}
+	near := Constraint{Left: []Term{{"a", 1.5 + 1e-12}, {"b", 1}, {"d", -3}, {"e", 1e-12}}}
+	if !Equal(c, near, 1e-9) {
+		t.Errorf("constraints within tolerance not equal")
+	}
+	if Equal(c, near, 0) {
+		t.Errorf("different constraints equal with zero tolerance")
+	}
+	if Equal(c, Constraint{Left: []Term{{"a", math.NaN()}}}, 1) {
+		t.Errorf("NaN coefficient equal")
+	}
+}
+
+func TestReadConstraints(t *testing.T) {
+	in := "2 a + -b + c <= 0\n" +
+		" <= 0\n" +
+		"1e-05 x + -1 y <= 0.0\n" +
+		"-2 a - b - 3 c + d <= 0;\n" +
+		"3 <= x\n" +
+		"x <= -3\n" +
+		"2 x <= y + 1.5 // constant on the right\n" +
+		"x - 3 <= 0;\n" +
+		"inf + 2 <= 0\n"
+	got, err := ReadConstraints(strings.NewReader(in))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Constraint{
+		{Left: []Term{{"a", 2}, {"b", -1}, {"c", 1}}},
+		{},
+		{Left: []Term{{"x", 1e-5}, {"y", -1}}},
+		{Left: []Term{{"a", -2}, {"b", -1}, {"c", -3}, {"d", 1}}},
+		{Left: []Term{{One, 3}}, Right: []Term{{"x", 1}}},
+		{Left: []Term{{"x", 1}}, Right: []Term{{One, -3}}},
+		{Left: []Term{{"x", 2}}, Right: []Term{{"y", 1}, {One, 1.5}}},
+		{Left: []Term{{"x", 1}, {One, -3}}},
+		{Left: []Term{{"inf", 1}, {One, 2}}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	for _, in := range []string{
+		"a + <= 0\n",
+		"a b <= 0\n",
+		"a\n",
+		"a + + b <= 0\n",
+	} {
+		if _, err := ReadConstraints(strings.NewReader(in)); err == nil {
+			t.Errorf("no error reading %q", in)
+		}
+	}
+}
+
+func FuzzRoundTrip(f *testing.F) {
+	f.Add("x", "y", 1.0, -2.5, false)
+	f.Add("inf", "e5", 1.0/3, 1e-300, true)
+	f.Add("a", "a", 1.0, -1.0, true)
+	f.Add("x", "y", -1.0, 1.0, true)
+	f.Fuzz(func(t *testing.T, n1, n2 string, v1, v2 float64, omitUnit bool) {
+		if math.IsNaN(v1) || math.IsInf(v1, 0) || math.IsNaN(v2) || math.IsInf(v2, 0) {
+			t.Skip()
+		}
+		names := SanitizeNames(uniqueNames(n1, n2), LPNames)
+		c := Constraint{
+			Left:  []Term{{names[0], v1}},
+			Right: []Term{{names[len(names)-1], v2}},
+		}
+		for _, dialect := range []Dialect{CPLEXDialect, LPSolveDialect} {
+			var buf bytes.Buffer
+			opts := &Options{Precision: -1, OmitUnit: omitUnit, Dialect: dialect}
+			if err := WriteConstraintsTo(&buf, []Constraint{c}, opts); err != nil {
+				t.Fatal(err)
+			}
+			text := buf.String()
+			got, err := ReadConstraints(&buf)
+			if err != nil {
+				t.Fatalf("reading %q: %v", text, err)
+			}
+			if len(got) != 1 || !Equal(got[0], c, 0) {
+				t.Errorf("round trip of %v gave %v via %q", c, got, text)
+			}
+		}
+	})
+}
+
+// uniqueNames returns the distinct names among a and b.
+func uniqueNames(a, b string) []string {
+	if a == b {
+		return []string{a}
+	}
+	return []string{a, b}
+}
+
+func TestCoalesceTerms(t *testing.T) {
+	terms := []Term{{"a", 1}, {"b", 1e-17}, {"c", 2}, {"a", 0.5}, {"c", -2}, {"d", -1e-3}}
+	for _, test := range []struct {
+		tol  float64
+		want []Term
+	}{
+		{0, []Term{{"a", 1.5}, {"b", 1e-17}, {"d", -1e-3}}},
+		{1e-12, []Term{{"a", 1.5}, {"d", -1e-3}}},
+		{1e-3, []Term{{"a", 1.5}}},
+	} {
+		if got := CoalesceTerms(terms, test.tol); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("tol %v: got %v, want %v", test.tol, got, test.want)
+		}
+	}
+	if terms[0].Value != 1 {
+		t.Errorf("input modified")
+	}
+	if got := CoalesceTerms(nil, 0); len(got) != 0 {
+		t.Errorf("got %v for no terms", got)
+	}
+}
+
+func TestCloneConstraints(t *testing.T) {
+	cons := randomConstraints(20, 50)
+	cons = append(cons, Constraint{}, Constraint{Left: []Term{}})
+	clone := CloneConstraints(cons)
+	if !reflect.DeepEqual(clone, cons) {
+		t.Fatalf("clone differs")
+	}
+	if !EqualConstraints(clone, cons, 0) {
+		t.Errorf("clone not equal")
+	}
+	clone[0].Left[0].Value++
+	if cons[0].Left[0].Value == clone[0].Left[0].Value {
+		t.Errorf("clone shares terms")
+	}
+	if EqualConstraints(clone, cons, 0) {
+		t.Errorf("modified clone equal")
+	}
+	if !EqualConstraints(clone, cons, 1) {
+		t.Errorf("modified clone not equal within tolerance")
+	}
+	if EqualConstraints(clone[1:], cons, 1) {
+		t.Errorf("different lengths equal")
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+	cons := []Constraint{
+		{Left: []Term{{"a", 1}, {"b", 2}}, Right: []Term{{"c", 3}}},
+		{Left: []Term{{"b", 1}}},
+	}
+	same := []Constraint{
+		{Left: []Term{{"b", 0.5}, {"b", 0.5}}, Right: []Term{{"d", 0}}},
+		{Left: []Term{{"b", 2}, {"c", -3}}, Right: []Term{{"a", -1}}},
+	}
+	want := Fingerprint(cons)
+	if got := Fingerprint(same); got != want {
+		t.Errorf("equivalent constraints have different fingerprints")
+	}
+	for _, other := range [][]Constraint{
+		{cons[0]},
+		{cons[0], cons[1], cons[1]},
+		{cons[0], {Left: []Term{{"b", 1.0000000001}}}},
+		{cons[0], {Left: []Term{{"bb", 1}}}},
+		{{Left: []Term{{"a", 1}, {"b", 2}}}, {Left: []Term{{"b", 1}}, Right: []Term{{"c", 3}}}},
+	} {
+		if Fingerprint(other) == want {
+			t.Errorf("%v has the same fingerprint as %v", other, cons)
+		}
+	}
+}
@@ -0,0 +1,285 @@
+/*
+Copyright 2017 Brendan Tracey
+
+Redistribution and use in source and binary forms, with or without modification,
+are permitted provided that the following conditions are met:
+
+1. Redistributions of source code must retain the above copyright notice, this
+list of conditions and the following disclaimer.
+
+2. Redistributions in binary form must reproduce the above copyright notice,
+this list of conditions and the following disclaimer in the documentation and/or
+other materials provided with the distribution.
+
+3. Neither the name of the copyright holder nor the names of its contributors may
+be used to endorse or promote products derived from this software without specific
+prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED.
+IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT,
+INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF
+LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE
+OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED
+OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+// Command benchcmp runs the constraint writing benchmarks of package benchlp
+// several times and prints the results in the format read by benchstat,
+// followed by a summary of the significant differences.
+//
+// Usage:
+//
+//	benchcmp [-count n] [-nvars n] [-ncons n] [-base file] [-threshold pct] [-cpuprofile file]
+//
+// Each of the variants WriteConstraints with and without preallocated
+// scratch memory is run on a sparse and a dense problem. The benchmark lines
+// are written to standard output, so they can be saved and passed to
+// benchstat, and the summary is written to standard error. The summary
+// compares the two allocation variants on each problem and, if -base names
+// the saved output of an earlier run, compares each benchmark to its earlier
+// result. benchcmp exits with status 1 if any benchmark is significantly
+// slower than its earlier result by more than -threshold percent.
+//
+// Differences are tested with the Mann-Whitney U test, as benchstat does,
+// and are significant at p < 0.05. The CPU profile, if requested, covers the
+// benchmark runs but not the generation of the problems.
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"runtime"
+	"runtime/pprof"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/btracey/benchlp"
+)
+
+// alpha is the significance level of the comparisons.
+const alpha = 0.05
+
+// variant is one benchmark.
+type variant struct {
+	name    string
+	density float64
+	preal   bool
+}
+
+var variants = []variant{
+	{"BenchmarkLP/NoAllocate/Sparse", 1, false},
+	{"BenchmarkLP/Allocate/Sparse", 1, true},
+	{"BenchmarkLP/NoAllocate/Dense", 20, false},
+	{"BenchmarkLP/Allocate/Dense", 20, true},
+}
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("benchcmp: ")
+	count := flag.Int("count", 10, "number of runs of each benchmark")
+	nVars := flag.Int("nvars", 10000, "number of variables")
+	nCons := flag.Int("ncons", 10000, "number of constraints")
+	base := flag.String("base", "", "earlier output to compare against")
+	threshold := flag.Float64("threshold", 5, "slowdown, in percent, counted as a regression")
+	cpuProfile := flag.String("cpuprofile", "", "write a CPU profile of the benchmark runs to `file`")
+	flag.Parse()
+	if *count < 1 || *nVars <= 0 || *nCons < 0 {
+		log.Fatal("invalid flags")
+	}
+
+	var old map[string][]float64
+	if *base != "" {
+		var err error
+		old, err = readResults(*base)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	fmt.Printf("goos: %s\ngoarch: %s\npkg: github.com/btracey/benchlp\n", runtime.GOOS, runtime.GOARCH)
+	problems := make(map[float64][]benchlp.Constraint)
+	for _, v := range variants {
+		if _, ok := problems[v.density]; !ok {
+			g := &benchlp.Generator{Vars: *nVars, Density: v.density}
+			problems[v.density] = g.Generate(*nCons)
+		}
+	}
+	stopProfile := func() {}
+	if *cpuProfile != "" {
+		f, err := os.Create(*cpuProfile)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err := pprof.StartCPUProfile(f); err != nil {
+			log.Fatal(err)
+		}
+		stopProfile = func() {
+			pprof.StopCPUProfile()
+			if err := f.Close(); err != nil {
+				log.Fatal(err)
+			}
+		}
+	}
+	results := make(map[string][]float64)
+	// Interleave the runs so drift in the machine affects all variants alike.
+	for i := 0; i < *count; i++ {
+		for _, v := range variants {
+			cons := problems[v.density]
+			r := testing.Benchmark(func(b *testing.B) {
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					benchlp.WriteConstraints(cons, v.preal)
+				}
+			})
+			fmt.Printf("%s\t%d\t%d ns/op\t%d B/op\t%d allocs/op\n",
+				v.name, r.N, r.NsPerOp(), r.AllocedBytesPerOp(), r.AllocsPerOp())
+			results[v.name] = append(results[v.name], float64(r.NsPerOp()))
+		}
+	}
+	stopProfile()
+
+	fmt.Fprintln(os.Stderr)
+	for _, problem := range []string{"Sparse", "Dense"} {
+		x := results["BenchmarkLP/NoAllocate/"+problem]
+		y := results["BenchmarkLP/Allocate/"+problem]
+		fmt.Fprintf(os.Stderr, "%s: Allocate vs NoAllocate: %s\n", problem, compare(x, y))
+	}
+	if old == nil {
+		return
+	}
+	var regressed bool
+	for _, v := range variants {
+		x, ok := old[v.name]
+		if !ok {
+			continue
+		}
+		y := results[v.name]
+		fmt.Fprintf(os.Stderr, "%s vs base: %s\n", v.name, compare(x, y))
+		if mannWhitney(x, y) < alpha && 100*(median(y)/median(x)-1) > *threshold {
+			regressed = true
+		}
+	}
+	if regressed {
+		fmt.Fprintln(os.Stderr, "regression detected")
+		os.Exit(1)
+	}
+}
+
+// compare returns a summary of the change from x to y in the style of
+// benchstat.
+func compare(x, y []float64) string {
+	p := mannWhitney(x, y)
+	delta := "~"
+	if p < alpha {
+		delta = fmt.Sprintf("%+.2f%%", 100*(median(y)/median(x)-1))
+	}
+	return fmt.Sprintf("%s (p=%.3f n=%d+%d)", delta, p, len(x), len(y))
+}
+
+// readResults reads the ns/op values of each benchmark from output in the Go
+// benchmark format. A -GOMAXPROCS suffix on the names is removed.
+func readResults(name string) (map[string][]float64, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	results := make(map[string][]float64)
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		fields := strings.Fields(sc.Text())
+		if len(fields) < 4 || !strings.HasPrefix(fields[0], "Benchmark") {
+			continue
+		}
+		name := fields[0]
+		if i := strings.LastIndexByte(name, '-'); i >= 0 {
+			if _, err := strconv.Atoi(name[i+1:]); err == nil {
+				name = name[:i]
+			}
+		}
+		for i := 2; i+1 < len(fields); i += 2 {
+			if fields[i+1] != "ns/op" {
+				continue
+			}
+			v, err := strconv.ParseFloat(fields[i], 64)
+			if err != nil {
+				return nil, fmt.Errorf("%s: bad value %q", name, fields[i])
+			}
+			results[name] = append(results[name], v)
+		}
+	}
+	return results, sc.Err()
+}
+
+// median returns the median of x.
+func median(x []float64) float64 {
+	s := append([]float64(nil), x...)
+	sort.Float64s(s)
+	n := len(s)
+	if n%2 == 1 {
+		return s[n/2]
+	}
+	return (s[n/2-1] + s[n/2]) / 2
+}
+
+// mannWhitney returns the two-sided p-value of the Mann-Whitney U test that x
+// and y come from the same distribution, using the normal approximation with
+// corrections for ties and continuity.
+func mannWhitney(x, y []float64) float64 {
+	n1, n2 := float64(len(x)), float64(len(y))
+	if n1 == 0 || n2 == 0 {
+		return 1
+	}
+	type obs struct {
+		v     float64
+		fromX bool
+	}
+	all := make([]obs, 0, len(x)+len(y))
+	for _, v := range x {
+		all = append(all, obs{v, true})
+	}
+	for _, v := range y {
+		all = append(all, obs{v, false})
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].v < all[j].v })
+
+	// Sum the ranks of x, giving tied values their mean rank.
+	var r1, ties float64
+	for i := 0; i < len(all); {
+		j := i
+		for j < len(all) && all[j].v == all[i].v {
+			j++
+		}
+		rank := float64(i+j+1) / 2
+		for k := i; k < j; k++ {
+			if all[k].fromX {
+				r1 += rank
+			}
+		}
+		t := float64(j - i)
+		ties += t*t*t - t
+		i = j
+	}
+	n := n1 + n2
+	u := r1 - n1*(n1+1)/2
+	mean := n1 * n2 / 2
+	variance := n1 * n2 / 12 * (n + 1 - ties/(n*(n-1)))
+	if variance <= 0 {
+		return 1
+	}
+	z := (math.Abs(u-mean) - 0.5) / math.Sqrt(variance)
+	if z < 0 {
+		z = 0
+	}
+	return math.Erfc(z / math.Sqrt2)
+}
@@ -0,0 +1,622 @@
+/*
+Copyright 2017 Brendan Tracey
+
+Redistribution and use in source and binary forms, with or without modification,
+are permitted provided that the following conditions are met:
+
+1. Redistributions of source code must retain the above copyright notice, this
+list of conditions and the following disclaimer.
+
+2. Redistributions in binary form must reproduce the above copyright notice,
+this list of conditions and the following disclaimer in the documentation and/or
+other materials provided with the distribution.
+
+3. Neither the name of the copyright holder nor the names of its contributors may
+be used to endorse or promote products derived from this software without specific
+prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED.
+IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT,
+INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF
+LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE
+OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED
+OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+// Command benchlp generates random sparse LP constraint sets and converts them
+// between the formats supported by package benchlp.
+//
+// Usage:
+//
+//	benchlp generate [-nvars n] [-ncons n] [-density d] [-terms dist] [-coeffs dist] [-scale s] [-seed s] [-rng r] [-workers n] [output flags]
+//	benchlp convert [-from f] [output flags] [file]
+//	benchlp diff [-from f] [-tol t] file1 file2
+//	benchlp check [-from f] [-k n] [-tol t] [-json] file solution
+//	benchlp run [-solver s] [-timelimit d] [-threads n] [-json] [-o file] dir
+//
+// The output flags are [-format f] [-o file] [-gzip] [-exact] [-twosided]
+// [-sanitize] [-progress] [-params solver] [-timelimit d] [-threads n]
+// [-symbols file] [-tempdir dir] [-cpuprofile file] [-memprofile file]. With
+// -symbols, the variable names are written to the file one per line in order
+// of index, so that line k names column k of the mtx output. With -gzip,
+// output in any format is compressed. With -exact, coefficients in lp, mtx,
+// mps, ampl, and osil output are written as their exact decimal values. With
+// -twosided, lp output keeps the terms of each side of a constraint on that
+// side. With -sanitize, lp, mps, and ampl output use legal names, for fixed
+// MPS in mps output, and start with comments mapping the changed names back
+// to the originals. With -tempdir, mps output is transposed through a
+// temporary file in the directory rather than in memory. With -params, a
+// parameter file for the solver (gurobi, cplex, or scip) holding the time
+// limit and thread count is written next to the -o file, with the extension
+// replaced by .prm, or .set for SCIP. The CPU profile covers only the
+// writing of the output. Allocation profiles are cumulative, so the memory
+// profile is written before and after the output as file.base and file, and
+//
+//	go tool pprof -diff_base file.base file
+//
+// shows the allocations of the output alone.
+//
+// The formats are lp (one constraint per line), json, bin (the binary
+// snapshot format), proto (the Protocol Buffers wire format), mtx (the
+// Matrix Market coordinate format, output only), mps (free MPS, output
+// only), ampl (a flat AMPL model, output only), and osil (Optimization
+// Services XML, output only). Output goes to standard output unless -o is
+// given, and input is read from standard input unless a file is named. When
+// -from is not given, the input format is taken from the file extension.
+//
+// Diff compares two constraint files, matching rows by position, and prints
+// the added and removed variables and rows and the coefficient changes of
+// each changed row in canonical form. Changes of at most -tol are ignored.
+// The exit status is 0 if the files are the same, 1 if they differ, and 2 on
+// error.
+//
+// Check evaluates the constraints at a solution written by a solver, in the
+// CPLEX XML format if the solution file name ends in .xml and in the Gurobi
+// and SCIP text format otherwise, and lists the -k most violated
+// constraints, as text or, with -json, as JSON. The exit status is 1 if a
+// constraint is violated by more than -tol.
+//
+// Run solves each .lp and .mps file in the directory, optionally
+// compressed, with the solver (gurobi, cplex, or highs), which must be on
+// the path, and writes the status, objective, and times of each run as CSV
+// or, with -json, as JSON. Instances to run can be written to the directory
+// with generate. A run is killed if it continues well past -timelimit.
+package main
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/pprof"
+	"strings"
+	"time"
+
+	"github.com/btracey/benchlp"
+)
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("benchlp: ")
+	if len(os.Args) < 2 {
+		usage()
+	}
+	switch os.Args[1] {
+	case "generate":
+		generate(os.Args[2:])
+	case "convert":
+		convert(os.Args[2:])
+	case "diff":
+		diff(os.Args[2:])
+	case "check":
+		check(os.Args[2:])
+	case "run":
+		run(os.Args[2:])
+	default:
+		usage()
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: benchlp generate|convert|diff|check|run [flags]")
+	os.Exit(2)
+}
+
+func generate(args []string) {
+	fs := flag.NewFlagSet("generate", flag.ExitOnError)
+	nVars := fs.Int("nvars", 10000, "number of variables")
+	nCons := fs.Int("ncons", 50000, "number of constraints")
+	density := fs.Float64("density", 1, "mean number of extra terms on each side of a constraint")
+	terms := fs.String("terms", "exp", "distribution of the number of terms: exp, poisson, or fixed")
+	coeffs := fs.String("coeffs", "uniform", "distribution of the coefficients: uniform, normal, or integer")
+	scale := fs.Float64("scale", 1, "scale of the coefficient distribution")
+	seed := fs.Int64("seed", 0, "random seed")
+	rng := fs.String("rng", "mathrand", "random number generator: mathrand, or xoshiro1 for instances that are the same in every Go release")
+	workers := fs.Int("workers", 0, "generate in parallel with `n` goroutines, giving output that depends on the seed but not on n")
+	var of outputFlags
+	of.register(fs)
+	fs.Parse(args)
+
+	if *nVars <= 0 || *nCons < 0 || *density < 0 {
+		log.Fatal("invalid problem size")
+	}
+	g := &benchlp.Generator{
+		Vars:    *nVars,
+		Density: *density,
+		Scale:   *scale,
+		Seed:    *seed,
+	}
+	switch *terms {
+	case "exp":
+		g.Terms = benchlp.ExponentialTerms
+	case "poisson":
+		g.Terms = benchlp.PoissonTerms
+	case "fixed":
+		g.Terms = benchlp.FixedTerms
+	default:
+		log.Fatalf("unknown term distribution %q", *terms)
+	}
+	switch *coeffs {
+	case "uniform":
+		g.Coeffs = benchlp.UniformCoeffs
+	case "normal":
+		g.Coeffs = benchlp.NormalCoeffs
+	case "integer":
+		g.Coeffs = benchlp.IntegerCoeffs
+		if *scale < 1 {
+			log.Fatal("integer coefficients need a scale of at least one")
+		}
+	default:
+		log.Fatalf("unknown coefficient distribution %q", *coeffs)
+	}
+	switch *rng {
+	case "mathrand":
+		g.RNG = benchlp.MathRand
+	case "xoshiro1":
+		g.RNG = benchlp.Xoshiro1
+	default:
+		log.Fatalf("unknown random number generator %q", *rng)
+	}
+	var cons []benchlp.Constraint
+	if *workers > 0 {
+		cons = g.GenerateParallel(*nCons, *workers)
+	} else {
+		cons = g.Generate(*nCons)
+	}
+	write(cons, &of)
+}
+
+func convert(args []string) {
+	fs := flag.NewFlagSet("convert", flag.ExitOnError)
+	from := fs.String("from", "", "input format")
+	var of outputFlags
+	of.register(fs)
+	fs.Parse(args)
+
+	var r io.Reader = os.Stdin
+	if fs.NArg() > 0 {
+		name := fs.Arg(0)
+		f, err := os.Open(name)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer f.Close()
+		r = f
+		if *from == "" {
+			*from = strings.TrimPrefix(filepath.Ext(name), ".")
+		}
+	}
+	cons, err := read(r, *from)
+	if err != nil {
+		log.Fatal(err)
+	}
+	write(cons, &of)
+}
+
+func diff(args []string) {
+	fs := flag.NewFlagSet("diff", flag.ExitOnError)
+	from := fs.String("from", "", "input format")
+	tol := fs.Float64("tol", 0, "largest coefficient change to ignore")
+	fs.Parse(args)
+	if fs.NArg() != 2 {
+		fmt.Fprintln(os.Stderr, "usage: benchlp diff [-from f] [-tol t] file1 file2")
+		os.Exit(2)
+	}
+	a, err := readFile(fs.Arg(0), *from)
+	if err != nil {
+		log.Print(err)
+		os.Exit(2)
+	}
+	b, err := readFile(fs.Arg(1), *from)
+	if err != nil {
+		log.Print(err)
+		os.Exit(2)
+	}
+
+	d := benchlp.DiffConstraints(a, b, *tol)
+	w := bufio.NewWriter(os.Stdout)
+	for _, name := range d.AddedVars {
+		fmt.Fprintf(w, "+ variable %s\n", name)
+	}
+	for _, name := range d.RemovedVars {
+		fmt.Fprintf(w, "- variable %s\n", name)
+	}
+	for _, i := range d.AddedRows {
+		fmt.Fprintf(w, "+ row %d: %v\n", i, b[i])
+	}
+	for _, i := range d.RemovedRows {
+		fmt.Fprintf(w, "- row %d: %v\n", i, a[i])
+	}
+	for _, c := range d.Changed {
+		fmt.Fprintf(w, "~ row %d:", c.Row)
+		for _, t := range c.Deltas {
+			fmt.Fprintf(w, " %s %+g", t.Var, t.Value)
+		}
+		fmt.Fprintln(w)
+	}
+	if err := w.Flush(); err != nil {
+		log.Print(err)
+		os.Exit(2)
+	}
+	if !d.Empty() {
+		os.Exit(1)
+	}
+}
+
+func check(args []string) {
+	fs := flag.NewFlagSet("check", flag.ExitOnError)
+	from := fs.String("from", "", "input format")
+	k := fs.Int("k", 10, "number of violated constraints to list, or all if not positive")
+	tol := fs.Float64("tol", 1e-6, "largest violation to ignore")
+	asJSON := fs.Bool("json", false, "list the violations as JSON")
+	fs.Parse(args)
+	if fs.NArg() != 2 {
+		fmt.Fprintln(os.Stderr, "usage: benchlp check [-from f] [-k n] [-tol t] [-json] file solution")
+		os.Exit(2)
+	}
+	cons, err := readFile(fs.Arg(0), *from)
+	if err != nil {
+		log.Fatal(err)
+	}
+	f, err := os.Open(fs.Arg(1))
+	if err != nil {
+		log.Fatal(err)
+	}
+	var sol *benchlp.Solution
+	if filepath.Ext(fs.Arg(1)) == ".xml" {
+		sol, err = benchlp.ReadCPLEXSolution(f)
+	} else {
+		sol, err = benchlp.ReadSolution(f)
+	}
+	f.Close()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	vs := benchlp.Violations(cons, sol.Values, *k, *tol)
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "\t")
+		err = enc.Encode(vs)
+	} else {
+		err = benchlp.WriteViolations(os.Stdout, vs)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+	if len(vs) > 0 {
+		os.Exit(1)
+	}
+}
+
+func run(args []string) {
+	fs := flag.NewFlagSet("run", flag.ExitOnError)
+	solver := fs.String("solver", "highs", "solver to run: gurobi, cplex, or highs")
+	timeLimit := fs.Duration("timelimit", 0, "time limit of each run, or none if zero")
+	threads := fs.Int("threads", 0, "number of solver threads, or the solver default if zero")
+	asJSON := fs.Bool("json", false, "write the results as JSON")
+	out := fs.String("o", "", "output file")
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "usage: benchlp run [-solver s] [-timelimit d] [-threads n] [-json] [-o file] dir")
+		os.Exit(2)
+	}
+	var s *benchlp.CommandSolver
+	switch *solver {
+	case "gurobi":
+		s = benchlp.GurobiSolver()
+	case "cplex":
+		s = benchlp.CPLEXSolver()
+	case "highs":
+		s = benchlp.HiGHSSolver()
+	default:
+		log.Fatalf("unknown solver %q", *solver)
+	}
+	p := &benchlp.SolverParams{TimeLimit: *timeLimit, Threads: *threads}
+	results, err := benchlp.RunBatch(context.Background(), fs.Arg(0), s, p)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	w := os.Stdout
+	if *out != "" {
+		if w, err = os.Create(*out); err != nil {
+			log.Fatal(err)
+		}
+	}
+	if *asJSON {
+		err = benchlp.WriteBatchJSON(w, results)
+	} else {
+		err = benchlp.WriteBatchCSV(w, results)
+	}
+	if cerr := w.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// readFile reads the constraints in the named file, in the given format or,
+// if format is empty, the format given by the file extension.
+func readFile(name, format string) ([]benchlp.Constraint, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	if format == "" {
+		format = strings.TrimPrefix(filepath.Ext(name), ".")
+	}
+	return read(f, format)
+}
+
+// read reads constraints in the given format.
+func read(r io.Reader, format string) ([]benchlp.Constraint, error) {
+	switch format {
+	case "lp":
+		return benchlp.ReadConstraints(r)
+	case "json":
+		return benchlp.DecodeJSON(r)
+	case "bin":
+		return benchlp.ReadBinary(r)
+	case "proto":
+		b, err := io.ReadAll(r)
+		if err != nil {
+			return nil, err
+		}
+		return benchlp.UnmarshalProto(b)
+	case "":
+		return nil, fmt.Errorf("input format not specified")
+	}
+	return nil, fmt.Errorf("cannot read format %q", format)
+}
+
+// outputFlags are the flags controlling the output of the subcommands.
+type outputFlags struct {
+	format     string
+	out        string
+	gz         bool
+	exact      bool
+	twoSided   bool
+	sanitize   bool
+	progress   bool
+	params     string
+	timeLimit  time.Duration
+	threads    int
+	symbols    string
+	tempDir    string
+	cpuProfile string
+	memProfile string
+}
+
+func (o *outputFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&o.format, "format", "lp", "output format")
+	fs.StringVar(&o.out, "o", "", "output file")
+	fs.BoolVar(&o.gz, "gzip", false, "compress the output with gzip")
+	fs.BoolVar(&o.exact, "exact", false, "write the exact decimal value of each coefficient")
+	fs.BoolVar(&o.twoSided, "twosided", false, "keep the terms of each side of a constraint on that side in lp output")
+	fs.BoolVar(&o.sanitize, "sanitize", false, "write legal variable names in lp, mps, and ampl output")
+	fs.BoolVar(&o.progress, "progress", false, "report progress of lp output on standard error")
+	fs.StringVar(&o.params, "params", "", "write a parameter file for the `solver` (gurobi, cplex, or scip) next to the output file")
+	fs.DurationVar(&o.timeLimit, "timelimit", 0, "time limit in the parameter file")
+	fs.IntVar(&o.threads, "threads", 0, "number of threads in the parameter file")
+	fs.StringVar(&o.symbols, "symbols", "", "write the variable names in order of index to `file`")
+	fs.StringVar(&o.tempDir, "tempdir", "", "transpose mps output through a temporary file in `dir`")
+	fs.StringVar(&o.cpuProfile, "cpuprofile", "", "write a CPU profile of the output phase to `file`")
+	fs.StringVar(&o.memProfile, "memprofile", "", "write allocation profiles from before and after the output phase to `file`.base and `file`")
+}
+
+// write writes the constraints in the format given by the flags to the
+// output file, or to standard output if there is none, profiling the write
+// if requested.
+func write(cons []benchlp.Constraint, of *outputFlags) {
+	name, format := of.out, of.format
+	if of.params != "" {
+		if name == "" {
+			log.Fatal("-params requires -o")
+		}
+		paramWriter(of.params) // check the solver before writing
+	}
+	w := os.Stdout
+	if name != "" {
+		f, err := os.Create(name)
+		if err != nil {
+			log.Fatal(err)
+		}
+		w = f
+	}
+	if of.memProfile != "" {
+		writeAllocs(of.memProfile + ".base")
+	}
+	if of.cpuProfile != "" {
+		f, err := os.Create(of.cpuProfile)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err := pprof.StartCPUProfile(f); err != nil {
+			log.Fatal(err)
+		}
+		defer func() {
+			pprof.StopCPUProfile()
+			if err := f.Close(); err != nil {
+				log.Fatal(err)
+			}
+		}()
+	}
+	opts := &benchlp.Options{Compress: of.gz, Exact: of.exact, TwoSided: of.twoSided, TempDir: of.tempDir}
+	if of.sanitize {
+		opts.Names = benchlp.LPNames
+		if format == "mps" {
+			opts.Names = benchlp.MPSNames
+		}
+	}
+	if of.progress {
+		opts.Progress = func(p benchlp.Progress) {
+			fmt.Fprintf(os.Stderr, "\r%d/%d constraints, %d bytes, %v elapsed, %v remaining",
+				p.Constraints, p.Total, p.Bytes, p.Elapsed.Round(time.Second), p.ETA().Round(time.Second))
+			if p.Constraints == p.Total {
+				fmt.Fprintln(os.Stderr)
+			}
+		}
+	}
+	// The writers of the text formats compress with opts.Compress, and the
+	// others write to a gzip stream here.
+	var out io.Writer = w
+	var zw *gzip.Writer
+	if of.gz && (format == "json" || format == "bin" || format == "proto") {
+		zw = gzip.NewWriter(w)
+		out = zw
+	}
+	var err error
+	if enc := encoder(format, w, opts, of); enc != nil {
+		err = benchlp.EncodeConstraints(cons, opts.Order, enc)
+	} else {
+		switch format {
+		case "lp":
+			err = benchlp.WriteConstraintsTo(w, cons, opts)
+		case "mps":
+			err = benchlp.WriteMPS(w, cons, opts)
+		case "ampl":
+			err = benchlp.WriteAMPL(w, cons, opts)
+		case "osil":
+			err = benchlp.WriteOSiL(w, cons, opts)
+		case "json":
+			err = benchlp.EncodeJSON(out, cons)
+		case "bin":
+			err = benchlp.WriteBinary(out, cons)
+		case "proto":
+			_, err = out.Write(benchlp.MarshalProto(cons))
+		default:
+			err = fmt.Errorf("cannot write format %q", format)
+		}
+	}
+	if err == nil && zw != nil {
+		err = zw.Close()
+	}
+	if err == nil && name != "" {
+		err = w.Close()
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+	if of.memProfile != "" {
+		writeAllocs(of.memProfile)
+	}
+	if of.params != "" {
+		writeParams(of)
+	}
+	if of.symbols != "" {
+		writeSymbols(of.symbols, cons)
+	}
+}
+
+// encoder returns the encoder writing the format to w, or nil if the format
+// has none or the flags need options of its writer that the encoder does
+// not support: -twosided and -progress for lp, and -tempdir for mps.
+func encoder(format string, w io.Writer, opts *benchlp.Options, of *outputFlags) benchlp.Encoder {
+	switch {
+	case format == "lp" && !of.twoSided && !of.progress:
+		return benchlp.NewLPEncoder(w, opts)
+	case format == "mtx":
+		return benchlp.NewMatrixMarketEncoder(w, opts)
+	case format == "mps" && of.tempDir == "":
+		return benchlp.NewMPSEncoder(w, opts)
+	}
+	return nil
+}
+
+// writeSymbols writes the symbol table of the constraints to the named file.
+func writeSymbols(name string, cons []benchlp.Constraint) {
+	f, err := os.Create(name)
+	if err != nil {
+		log.Fatal(err)
+	}
+	_, err = benchlp.NewSymbolTable(cons, benchlp.FirstAppearance).WriteTo(f)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// paramWriter returns the function writing the parameter file for the named
+// solver, and the extension of the file.
+func paramWriter(solver string) (func(io.Writer, *benchlp.SolverParams) error, string) {
+	switch solver {
+	case "gurobi":
+		return benchlp.WriteGurobiParams, ".prm"
+	case "cplex":
+		return benchlp.WriteCPLEXParams, ".prm"
+	case "scip":
+		return benchlp.WriteSCIPSettings, ".set"
+	}
+	log.Fatalf("unknown solver %q", solver)
+	panic("unreachable")
+}
+
+// writeParams writes the solver parameter file requested by the flags next to
+// the output file.
+func writeParams(of *outputFlags) {
+	writeParams, ext := paramWriter(of.params)
+	p := &benchlp.SolverParams{TimeLimit: of.timeLimit, Threads: of.threads}
+	name := strings.TrimSuffix(of.out, filepath.Ext(of.out)) + ext
+	f, err := os.Create(name)
+	if err != nil {
+		log.Fatal(err)
+	}
+	err = writeParams(f, p)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// writeAllocs writes the allocation profile to the named file.
+func writeAllocs(name string) {
+	runtime.GC() // bring the profile up to date
+	f, err := os.Create(name)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := pprof.Lookup("allocs").WriteTo(f, 0); err != nil {
+		log.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,267 @@
+/*
+Copyright 2017 Brendan Tracey
+
+Redistribution and use in source and binary forms, with or without modification,
+are permitted provided that the following conditions are met:
+
+1. Redistributions of source code must retain the above copyright notice, this
+list of conditions and the following disclaimer.
+
+2. Redistributions in binary form must reproduce the above copyright notice,
+this list of conditions and the following disclaimer in the documentation and/or
+other materials provided with the distribution.
+
+3. Neither the name of the copyright holder nor the names of its contributors may
+be used to endorse or promote products derived from this software without specific
+prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED.
+IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT,
+INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF
+LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE
+OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED
+OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+// Command benchlpd serves the writers of package benchlp over HTTP, so that
+// programs in other languages can use them.
+//
+// Usage:
+//
+//	benchlpd [-addr host:port] [-maxbytes n] [-maxmodels n]
+//
+// The endpoints are
+//
+//	POST /models                 store the JSON constraints in the body,
+//	                             returning {"id": "..."}
+//	GET  /models/{id}?format=f   the stored constraints in format f
+//	GET  /models/{id}/stats      statistics of the stored constraints, as JSON
+//	DELETE /models/{id}          forget the stored constraints
+//	POST /render?format=f        the JSON constraints in the body in format f
+//
+// The JSON schema is that of benchlp.EncodeJSON. The formats are lp (the
+// default), mps, mtx, ampl, osil, json, bin, and proto, and adding gzip=1 to
+// the query compresses the output in any format. Models are kept in memory
+// until deleted or the server exits. Request bodies are limited to -maxbytes
+// bytes, and once -maxmodels models are stored, storing another fails with
+// status 507 until one is deleted.
+package main
+
+import (
+	"compress/gzip"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/btracey/benchlp"
+)
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("benchlpd: ")
+	addr := flag.String("addr", "localhost:8080", "address to listen on")
+	maxBytes := flag.Int64("maxbytes", 1<<30, "maximum size of a request body")
+	maxModels := flag.Int("maxmodels", 100, "maximum number of stored models")
+	flag.Parse()
+
+	s := &server{
+		models:    make(map[string][]benchlp.Constraint),
+		maxBytes:  *maxBytes,
+		maxModels: *maxModels,
+	}
+	log.Fatal(http.ListenAndServe(*addr, s))
+}
+
+// ServeHTTP routes the request to its handler. Routing is done by hand
+// rather than with ServeMux patterns, which need Go 1.22 module semantics.
+func (s *server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	switch {
+	case len(path) == 1 && path[0] == "models":
+		s.route(w, r, "POST", s.create)
+	case len(path) == 2 && path[0] == "models" && r.Method == "DELETE":
+		s.delete(w, r, path[1])
+	case len(path) == 2 && path[0] == "models":
+		s.route(w, r, "GET", func(w http.ResponseWriter, r *http.Request) { s.get(w, r, path[1]) })
+	case len(path) == 3 && path[0] == "models" && path[2] == "stats":
+		s.route(w, r, "GET", func(w http.ResponseWriter, r *http.Request) { s.stats(w, r, path[1]) })
+	case len(path) == 1 && path[0] == "render":
+		s.route(w, r, "POST", s.render)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// route calls h if the request has the method.
+func (s *server) route(w http.ResponseWriter, r *http.Request, method string, h http.HandlerFunc) {
+	if r.Method != method {
+		w.Header().Set("Allow", method)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	h(w, r)
+}
+
+// server holds the stored models.
+type server struct {
+	mu        sync.Mutex
+	models    map[string][]benchlp.Constraint
+	maxBytes  int64
+	maxModels int
+}
+
+// decode reads the JSON constraints in the request body.
+func (s *server) decode(w http.ResponseWriter, r *http.Request) ([]benchlp.Constraint, bool) {
+	cons, err := benchlp.DecodeJSON(http.MaxBytesReader(w, r.Body, s.maxBytes))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return nil, false
+	}
+	return cons, true
+}
+
+// lookup returns the model with the id.
+func (s *server) lookup(w http.ResponseWriter, r *http.Request, id string) ([]benchlp.Constraint, bool) {
+	s.mu.Lock()
+	cons, ok := s.models[id]
+	s.mu.Unlock()
+	if !ok {
+		http.NotFound(w, r)
+	}
+	return cons, ok
+}
+
+func (s *server) create(w http.ResponseWriter, r *http.Request) {
+	cons, ok := s.decode(w, r)
+	if !ok {
+		return
+	}
+	var b [8]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	id := hex.EncodeToString(b[:])
+	s.mu.Lock()
+	full := len(s.models) >= s.maxModels
+	if !full {
+		s.models[id] = cons
+	}
+	s.mu.Unlock()
+	if full {
+		http.Error(w, "too many stored models", http.StatusInsufficientStorage)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(struct {
+		ID string `json:"id"`
+	}{id})
+}
+
+func (s *server) get(w http.ResponseWriter, r *http.Request, id string) {
+	if cons, ok := s.lookup(w, r, id); ok {
+		write(w, r, cons)
+	}
+}
+
+func (s *server) stats(w http.ResponseWriter, r *http.Request, id string) {
+	cons, ok := s.lookup(w, r, id)
+	if !ok {
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(benchlp.ConstraintStats(cons))
+}
+
+func (s *server) delete(w http.ResponseWriter, r *http.Request, id string) {
+	if _, ok := s.lookup(w, r, id); !ok {
+		return
+	}
+	s.mu.Lock()
+	delete(s.models, id)
+	s.mu.Unlock()
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *server) render(w http.ResponseWriter, r *http.Request) {
+	if cons, ok := s.decode(w, r); ok {
+		write(w, r, cons)
+	}
+}
+
+// encode returns a function writing the constraints with the encoder
+// returned by newEncoder.
+func encode(cons []benchlp.Constraint, opts *benchlp.Options, newEncoder func(io.Writer, *benchlp.Options) benchlp.Encoder) func(io.Writer) error {
+	return func(w io.Writer) error {
+		return benchlp.EncodeConstraints(cons, opts.Order, newEncoder(w, opts))
+	}
+}
+
+// write writes the constraints in the format given by the request query.
+func write(w http.ResponseWriter, r *http.Request, cons []benchlp.Constraint) {
+	q := r.URL.Query()
+	gz := q.Get("gzip") == "1"
+	opts := &benchlp.Options{Compress: gz}
+	var fn func(io.Writer) error
+	contentType := "text/plain; charset=utf-8"
+	switch format := q.Get("format"); format {
+	case "", "lp":
+		fn = encode(cons, opts, benchlp.NewLPEncoder)
+	case "mtx":
+		fn = encode(cons, opts, benchlp.NewMatrixMarketEncoder)
+	case "mps":
+		fn = encode(cons, opts, benchlp.NewMPSEncoder)
+	case "ampl":
+		fn = func(w io.Writer) error { return benchlp.WriteAMPL(w, cons, opts) }
+	case "osil":
+		contentType = "application/xml"
+		fn = func(w io.Writer) error { return benchlp.WriteOSiL(w, cons, opts) }
+	case "json":
+		contentType = "application/json"
+		fn = func(w io.Writer) error { return benchlp.EncodeJSON(w, cons) }
+	case "bin":
+		contentType = "application/octet-stream"
+		fn = func(w io.Writer) error { return benchlp.WriteBinary(w, cons) }
+	case "proto":
+		contentType = "application/x-protobuf"
+		fn = func(w io.Writer) error {
+			_, err := w.Write(benchlp.MarshalProto(cons))
+			return err
+		}
+	default:
+		http.Error(w, fmt.Sprintf("unknown format %q", format), http.StatusBadRequest)
+		return
+	}
+	if gz {
+		contentType = "application/gzip"
+		if format := q.Get("format"); format == "json" || format == "bin" || format == "proto" {
+			// Only the writers of the text formats compress with
+			// opts.Compress.
+			write := fn
+			fn = func(w io.Writer) error {
+				zw := gzip.NewWriter(w)
+				if err := write(zw); err != nil {
+					return err
+				}
+				return zw.Close()
+			}
+		}
+	}
+	w.Header().Set("Content-Type", contentType)
+	if err := fn(w); err != nil {
+		// The status has been sent, so the error can only be logged.
+		log.Printf("%s %s: %v", r.Method, r.URL, err)
+	}
+}
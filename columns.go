@@ -0,0 +1,87 @@
+/*
+Copyright 2017 Brendan Tracey
+
+Redistribution and use in source and binary forms, with or without modification,
+are permitted provided that the following conditions are met:
+
+1. Redistributions of source code must retain the above copyright notice, this
+list of conditions and the following disclaimer.
+
+2. Redistributions in binary form must reproduce the above copyright notice,
+this list of conditions and the following disclaimer in the documentation and/or
+other materials provided with the distribution.
+
+3. Neither the name of the copyright holder nor the names of its contributors may
+be used to endorse or promote products derived from this software without specific
+prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED.
+IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT,
+INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF
+LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE
+OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED
+OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package benchlp
+
+// Column is the non-zero coefficients of a variable, listed by increasing
+// constraint index.
+type Column struct {
+	Row   []int
+	Value []float64
+}
+
+// ToColumns returns the condensed coefficient matrix of the constraints in
+// column-major form. Column j holds the coefficients of variable names[j],
+// with variables indexed as by IndexVariables, and the coefficient in
+// constraint i is that of the variable on the left hand side once all terms
+// are moved there. Terms with the same variable are combined and zero
+// coefficients are omitted.
+//
+// The time taken is linear in the number of terms, rather than in the number
+// of constraints times the number of variables.
+func ToColumns(cons []Constraint) (names []string, cols []Column) {
+	names, nameMap := IndexVariables(cons)
+	cols = make([]Column, len(names))
+	wl := make([]float64, len(names))
+	wr := make([]float64, len(names))
+	for i, c := range cons {
+		sparseCondense(wl, wr, c, nameMap, func(j int, v float64) {
+			cols[j].Row = append(cols[j].Row, i)
+			cols[j].Value = append(cols[j].Value, v)
+		})
+	}
+	return names, cols
+}
+
+// sparseCondense calls fn with the index and combined coefficient of each
+// variable of c with a non-zero coefficient, visiting only the terms of c.
+// The variables are visited in order of their first appearance in c. The
+// sides are summed into wl and wr and subtracted once, as by
+// CondenseConstraint, so the coefficients are the same to the bit. wl and wr
+// must have an element for every variable and be all zero, and are left all
+// zero.
+func sparseCondense(wl, wr []float64, c Constraint, nameMap map[string]int, fn func(j int, v float64)) {
+	for _, term := range c.Left {
+		wl[nameMap[term.Var]] += term.Value
+	}
+	for _, term := range c.Right {
+		wr[nameMap[term.Var]] += term.Value
+	}
+	visit := func(terms []Term) {
+		for _, term := range terms {
+			j := nameMap[term.Var]
+			if v := wl[j] - wr[j]; v != 0 {
+				fn(j, v)
+			}
+			wl[j], wr[j] = 0, 0
+		}
+	}
+	visit(c.Left)
+	visit(c.Right)
+}
@@ -0,0 +1,52 @@
+package benchlp
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestToColumns(t *testing.T) {
+	cons := []Constraint{
+		{Left: []Term{{"a", 1}, {"b", 2}}, Right: []Term{{"c", 3}}},
+		{Left: []Term{{"b", 1}, {"a", 1}}, Right: []Term{{"b", 1}}},
+		{Left: []Term{{"c", -1}, {"c", 2}}, Right: []Term{{"a", 4}}},
+	}
+	names, cols := ToColumns(cons)
+	if want := []string{"a", "b", "c"}; !reflect.DeepEqual(names, want) {
+		t.Fatalf("names: got %v, want %v", names, want)
+	}
+	want := []Column{
+		{Row: []int{0, 1, 2}, Value: []float64{1, 1, -4}},
+		{Row: []int{0}, Value: []float64{2}},
+		{Row: []int{0, 2}, Value: []float64{-3, 1}},
+	}
+	if !reflect.DeepEqual(cols, want) {
+		t.Errorf("got %v, want %v", cols, want)
+	}
+
+	// Check against the dense condensed rows.
+	cons = randomConstraints(50, 100)
+	names, cols = ToColumns(cons)
+	_, nameMap := IndexVariables(cons)
+	c1 := make([]float64, len(names))
+	c2 := make([]float64, len(names))
+	dense := make([][]float64, len(cons))
+	for i, c := range cons {
+		dense[i] = append([]float64(nil), CondenseConstraint(c1, c2, c, nameMap)...)
+	}
+	for j, col := range cols {
+		var k int
+		for i := range cons {
+			if dense[i][j] == 0 {
+				continue
+			}
+			if k >= len(col.Row) || col.Row[k] != i || col.Value[k] != dense[i][j] {
+				t.Fatalf("column %d does not match the condensed rows", j)
+			}
+			k++
+		}
+		if k != len(col.Row) {
+			t.Fatalf("column %d has extra entries", j)
+		}
+	}
+}
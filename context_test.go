@@ -0,0 +1,56 @@
+package benchlp
+
+import (
+	"bytes"
+	"context"
+	"testing"
+)
+
+func TestWriteConstraintsContext(t *testing.T) {
+	cons := randomConstraints(100, 10000)
+	for _, instrumented := range []bool{false, true} {
+		ctx, cancel := context.WithCancel(context.Background())
+		var buf bytes.Buffer
+		opts := &Options{BufferSize: 100}
+		var n int
+		w := writerFunc(func(b []byte) (int, error) {
+			if n += len(b); n > 10000 {
+				cancel()
+			}
+			return buf.Write(b)
+		})
+		if instrumented {
+			opts.Report = &Report{}
+		}
+		err := WriteConstraintsContext(ctx, w, cons, opts)
+		if err != context.Canceled {
+			t.Errorf("instrumented %t: got error %v, want %v", instrumented, err, context.Canceled)
+		}
+		out := buf.Bytes()
+		if len(out) == 0 || out[len(out)-1] != '\n' {
+			t.Errorf("instrumented %t: output does not end with a whole line", instrumented)
+		}
+		if lines := bytes.Count(out, []byte("\n")); lines >= len(cons) {
+			t.Errorf("instrumented %t: all %d constraints written", instrumented, lines)
+		}
+	}
+}
+
+func TestReadBinaryContext(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteBinary(&buf, randomConstraints(10, 10)); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := ReadBinaryContext(ctx, &buf); err != context.Canceled {
+		t.Errorf("got error %v, want %v", err, context.Canceled)
+	}
+}
+
+// writerFunc adapts a function to an io.Writer.
+type writerFunc func([]byte) (int, error)
+
+func (f writerFunc) Write(b []byte) (int, error) {
+	return f(b)
+}
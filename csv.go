@@ -0,0 +1,189 @@
+/*
+Copyright 2017 Brendan Tracey
+
+Redistribution and use in source and binary forms, with or without modification,
+are permitted provided that the following conditions are met:
+
+1. Redistributions of source code must retain the above copyright notice, this
+list of conditions and the following disclaimer.
+
+2. Redistributions in binary form must reproduce the above copyright notice,
+this list of conditions and the following disclaimer in the documentation and/or
+other materials provided with the distribution.
+
+3. Neither the name of the copyright holder nor the names of its contributors may
+be used to endorse or promote products derived from this software without specific
+prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED.
+IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT,
+INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF
+LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE
+OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED
+OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package benchlp
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// CSVSchema describes tabular constraint data, such as a database extract,
+// with one coefficient per record. The first record is a header naming the
+// columns, and the fields name the columns that hold each part of the data.
+// Empty names take the defaults "row", "var", "coeff", "sense", and "rhs".
+type CSVSchema struct {
+	// Comma is the field separator. The zero value is ',', and '\t' reads
+	// TSV.
+	Comma rune
+
+	// Row identifies the constraint of a record.
+	Row string
+	// Var and Coeff give the variable and coefficient of a term. A record
+	// with an empty variable adds no term, for a row with only a right
+	// hand side.
+	Var, Coeff string
+	// Sense is the sense of the constraint: "<=", ">=", or "=", or the MPS
+	// letters L, G, and E. If the column is not in the header, every
+	// constraint is <=.
+	Sense string
+	// RHS is the right hand side constant. If the column is not in the
+	// header, or the field is empty, it is zero.
+	RHS string
+}
+
+var (
+	errCSVColumn = errors.New("lp: missing column")
+	errSense     = errors.New("unknown sense")
+	errRowChange = errors.New("sense or right hand side differs from the row's first record")
+)
+
+// ReadConstraintsCSV reads constraints from tabular data described by
+// schema, which may be nil for the defaults. The records of a row may be in
+// any order and need not be adjacent. The rows become constraints in the
+// order they first appear, with a right hand side c written as the term
+// c*One on the right, a >= row written with its sides exchanged, and an =
+// row written as two constraints, <= and then >=. The sense and right hand
+// side may be given on every record of a row, but must then agree. Errors in
+// the data are returned as a *ParseError.
+func ReadConstraintsCSV(r io.Reader, schema *CSVSchema) ([]Constraint, error) {
+	if schema == nil {
+		schema = &CSVSchema{}
+	}
+	cr := csv.NewReader(r)
+	if schema.Comma != 0 {
+		cr.Comma = schema.Comma
+	}
+	cr.FieldsPerRecord = -1
+	cr.ReuseRecord = true
+	header, err := cr.Read()
+	if err != nil {
+		return nil, err
+	}
+	column := func(name, def string) int {
+		if name == "" {
+			name = def
+		}
+		for i, h := range header {
+			if strings.TrimSpace(h) == name {
+				return i
+			}
+		}
+		return -1
+	}
+	rowCol := column(schema.Row, "row")
+	varCol := column(schema.Var, "var")
+	coeffCol := column(schema.Coeff, "coeff")
+	senseCol := column(schema.Sense, "sense")
+	rhsCol := column(schema.RHS, "rhs")
+	if rowCol < 0 || varCol < 0 || coeffCol < 0 {
+		return nil, errCSVColumn
+	}
+
+	type csvRow struct {
+		sense string
+		rhs   float64
+		terms []Term
+	}
+	var rows []*csvRow
+	byID := make(map[string]*csvRow)
+	for {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := cr.FieldPos(0)
+		field := func(i int) string {
+			if i < 0 || i >= len(rec) {
+				return ""
+			}
+			return strings.TrimSpace(rec[i])
+		}
+		sense, err := parseSense(field(senseCol))
+		if err != nil {
+			return nil, &ParseError{Line: line, Err: err}
+		}
+		var rhs float64
+		if s := field(rhsCol); s != "" {
+			if rhs, err = strconv.ParseFloat(s, 64); err != nil {
+				return nil, &ParseError{Line: line, Err: errSyntax}
+			}
+		}
+		id := field(rowCol)
+		row, ok := byID[id]
+		if !ok {
+			row = &csvRow{sense: sense, rhs: rhs}
+			byID[id] = row
+			rows = append(rows, row)
+		} else if row.sense != sense || row.rhs != rhs {
+			return nil, &ParseError{Line: line, Err: errRowChange}
+		}
+		if v := field(varCol); v != "" {
+			coeff, err := strconv.ParseFloat(field(coeffCol), 64)
+			if err != nil {
+				return nil, &ParseError{Line: line, Err: errSyntax}
+			}
+			row.terms = append(row.terms, Term{v, coeff})
+		}
+	}
+
+	var cons []Constraint
+	for _, row := range rows {
+		var rhs []Term
+		if row.rhs != 0 {
+			rhs = []Term{{One, row.rhs}}
+		}
+		if row.sense != ">=" {
+			cons = append(cons, Constraint{Left: row.terms, Right: rhs})
+		}
+		if row.sense != "<=" {
+			cons = append(cons, Constraint{Left: rhs, Right: row.terms})
+		}
+	}
+	return cons, nil
+}
+
+// parseSense returns the sense as "<=", ">=", or "=". An empty sense is <=.
+func parseSense(s string) (string, error) {
+	switch strings.ToUpper(s) {
+	case "", "<=", "<", "L", "LE":
+		return "<=", nil
+	case ">=", ">", "G", "GE":
+		return ">=", nil
+	case "=", "==", "E", "EQ":
+		return "=", nil
+	}
+	return "", errSense
+}
@@ -0,0 +1,63 @@
+package benchlp
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReadConstraintsCSV(t *testing.T) {
+	data := "row,var,coeff,sense,rhs\n" +
+		"cap,x,2,<=,10\n" +
+		"demand,x,1,G,3\n" +
+		"cap,y,3,<=,10\n" +
+		"demand,y,1,G,3\n" +
+		"balance,x,1,=,\n" +
+		"balance,y,-1,=,\n" +
+		"fixed,,,L,-1\n"
+	cons, err := ReadConstraintsCSV(strings.NewReader(data), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Constraint{
+		{Left: []Term{{"x", 2}, {"y", 3}}, Right: []Term{{One, 10}}},
+		{Left: []Term{{One, 3}}, Right: []Term{{"x", 1}, {"y", 1}}},
+		{Left: []Term{{"x", 1}, {"y", -1}}},
+		{Right: []Term{{"x", 1}, {"y", -1}}},
+		{Right: []Term{{One, -1}}},
+	}
+	if !reflect.DeepEqual(cons, want) {
+		t.Errorf("got %v, want %v", cons, want)
+	}
+
+	tsv := "id\tname\tvalue\n" +
+		"r1\ta\t1.5\n" +
+		"r1\tb\t-2\n"
+	cons, err = ReadConstraintsCSV(strings.NewReader(tsv), &CSVSchema{Comma: '\t', Row: "id", Var: "name", Coeff: "value"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []Constraint{{Left: []Term{{"a", 1.5}, {"b", -2}}}}; !reflect.DeepEqual(cons, want) {
+		t.Errorf("TSV: got %v, want %v", cons, want)
+	}
+
+	for _, test := range []struct {
+		data string
+		line int
+	}{
+		{"row,var,coeff\nr,x,abc\n", 2},
+		{"row,var,coeff,sense\nr,x,1,<=\nr,y,1,>=\n", 3},
+		{"row,var,coeff,sense\nr,x,1,~\n", 2},
+		{"row,var,coeff,rhs\nr,x,1,1\nr,y,1,x\n", 3},
+	} {
+		_, err := ReadConstraintsCSV(strings.NewReader(test.data), nil)
+		var perr *ParseError
+		if !errors.As(err, &perr) || perr.Line != test.line {
+			t.Errorf("%q: got error %v, want a parse error at line %d", test.data, err, test.line)
+		}
+	}
+	if _, err := ReadConstraintsCSV(strings.NewReader("row,variable,coeff\n"), nil); err != errCSVColumn {
+		t.Errorf("missing column: got error %v", err)
+	}
+}
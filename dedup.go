@@ -0,0 +1,131 @@
+/*
+Copyright 2017 Brendan Tracey
+
+Redistribution and use in source and binary forms, with or without modification,
+are permitted provided that the following conditions are met:
+
+1. Redistributions of source code must retain the above copyright notice, this
+list of conditions and the following disclaimer.
+
+2. Redistributions in binary form must reproduce the above copyright notice,
+this list of conditions and the following disclaimer in the documentation and/or
+other materials provided with the distribution.
+
+3. Neither the name of the copyright holder nor the names of its contributors may
+be used to endorse or promote products derived from this software without specific
+prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED.
+IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT,
+INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF
+LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE
+OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED
+OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package benchlp
+
+import (
+	"encoding/binary"
+	"hash/fnv"
+	"math"
+)
+
+// CanonicalRow is the canonical form of a condensed constraint. Index holds
+// the indices of the non-zero coefficients in increasing order, and Value
+// holds the corresponding coefficients scaled so that the largest magnitude
+// is one.
+//
+// The constant of a constraint is the coefficient of One and is scaled with
+// the others, so scaling a constraint by a positive factor does not change
+// its meaning, and constraints that are positive multiples of one another
+// have the same canonical form (up to floating point rounding in the
+// scaling).
+type CanonicalRow struct {
+	Index []int
+	Value []float64
+}
+
+// CanonicalizeRow returns the canonical form of the condensed weight
+// vector w, as returned by CondenseConstraint.
+func CanonicalizeRow(w []float64) CanonicalRow {
+	var row CanonicalRow
+	var max float64
+	for i, v := range w {
+		if v == 0 {
+			continue
+		}
+		row.Index = append(row.Index, i)
+		row.Value = append(row.Value, v)
+		max = math.Max(max, math.Abs(v))
+	}
+	for i := range row.Value {
+		row.Value[i] /= max
+	}
+	return row
+}
+
+// Hash returns a hash of the canonical row. Equal rows have equal hashes.
+func (r CanonicalRow) Hash() uint64 {
+	h := fnv.New64a()
+	var buf [8]byte
+	for i, idx := range r.Index {
+		binary.LittleEndian.PutUint64(buf[:], uint64(idx))
+		h.Write(buf[:])
+		binary.LittleEndian.PutUint64(buf[:], math.Float64bits(r.Value[i]))
+		h.Write(buf[:])
+	}
+	return h.Sum64()
+}
+
+// Equal returns whether the two canonical rows are identical.
+func (r CanonicalRow) Equal(s CanonicalRow) bool {
+	if len(r.Index) != len(s.Index) {
+		return false
+	}
+	for i, idx := range r.Index {
+		if idx != s.Index[i] || r.Value[i] != s.Value[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// DeduplicateConstraints removes constraints whose canonical form matches that
+// of an earlier constraint, which removes both exact duplicates and positive
+// multiples. It returns the remaining constraints in their original order along
+// with the indices of the removed constraints. The input slice is not modified.
+func DeduplicateConstraints(cons []Constraint) ([]Constraint, []int) {
+	names, nameMap := IndexVariables(cons)
+	c1 := make([]float64, len(names))
+	c2 := make([]float64, len(names))
+
+	seen := make(map[uint64][]CanonicalRow)
+	var removed []int
+	kept := make([]Constraint, 0, len(cons))
+	for i, c := range cons {
+		row := CanonicalizeRow(CondenseConstraint(c1, c2, c, nameMap))
+		h := row.Hash()
+		if containsRow(seen[h], row) {
+			removed = append(removed, i)
+			continue
+		}
+		seen[h] = append(seen[h], row)
+		kept = append(kept, c)
+	}
+	return kept, removed
+}
+
+// containsRow returns whether row is equal to any of the rows.
+func containsRow(rows []CanonicalRow, row CanonicalRow) bool {
+	for _, r := range rows {
+		if r.Equal(row) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,38 @@
+package benchlp
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCanonicalizeRow(t *testing.T) {
+	row := CanonicalizeRow([]float64{0, -4, 0, 2})
+	want := CanonicalRow{Index: []int{1, 3}, Value: []float64{-1, 0.5}}
+	if !reflect.DeepEqual(row, want) {
+		t.Errorf("canonical mismatch: got %v, want %v", row, want)
+	}
+	if !row.Equal(CanonicalizeRow([]float64{0, -2, 0, 1})) {
+		t.Errorf("scaled row not equal")
+	}
+	if row.Equal(CanonicalizeRow([]float64{0, 2, 0, -1})) {
+		t.Errorf("negated row equal")
+	}
+}
+
+func TestDeduplicateConstraints(t *testing.T) {
+	cons := []Constraint{
+		{Left: []Term{{"a", 1}}, Right: []Term{{"b", 2}}},
+		{Left: []Term{{"a", 2}}, Right: []Term{{"b", 4}}},
+		{Left: []Term{{"b", 2}}, Right: []Term{{"a", 1}}},
+		{Left: []Term{{"a", 1}, {"c", 0}}, Right: []Term{{"b", 1}, {"b", 1}}},
+		{Left: []Term{{"c", 1}}},
+	}
+	got, removed := DeduplicateConstraints(cons)
+	want := []Constraint{cons[0], cons[2], cons[4]}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("constraint mismatch: got %v, want %v", got, want)
+	}
+	if !reflect.DeepEqual(removed, []int{1, 3}) {
+		t.Errorf("removed mismatch: got %v, want %v", removed, []int{1, 3})
+	}
+}
@@ -0,0 +1,131 @@
+/*
+Copyright 2017 Brendan Tracey
+
+Redistribution and use in source and binary forms, with or without modification,
+are permitted provided that the following conditions are met:
+
+1. Redistributions of source code must retain the above copyright notice, this
+list of conditions and the following disclaimer.
+
+2. Redistributions in binary form must reproduce the above copyright notice,
+this list of conditions and the following disclaimer in the documentation and/or
+other materials provided with the distribution.
+
+3. Neither the name of the copyright holder nor the names of its contributors may
+be used to endorse or promote products derived from this software without specific
+prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED.
+IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT,
+INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF
+LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE
+OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED
+OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package benchlp
+
+import (
+	"math"
+	"sort"
+)
+
+// Diff is the difference between two sets of constraints, as returned by
+// DiffConstraints. Constraints are not named, so rows are matched by
+// position.
+type Diff struct {
+	// AddedVars and RemovedVars are the variables appearing only in the
+	// second and only in the first set, sorted by name.
+	AddedVars   []string
+	RemovedVars []string
+	// AddedRows are the indices of the rows of the second set past the end
+	// of the first, and RemovedRows those of the first set past the end of
+	// the second.
+	AddedRows   []int
+	RemovedRows []int
+	// Changed are the rows present in both sets whose canonical forms
+	// differ, in order of row.
+	Changed []RowDiff
+}
+
+// RowDiff is the change in one row of a Diff.
+type RowDiff struct {
+	Row int
+	// Deltas are the changes in the canonical coefficients, the second
+	// minus the first, of the variables whose coefficients changed by more
+	// than the tolerance, sorted by name.
+	Deltas []Term
+}
+
+// Empty returns whether the two sets of constraints had no differences.
+func (d Diff) Empty() bool {
+	return len(d.AddedVars) == 0 && len(d.RemovedVars) == 0 &&
+		len(d.AddedRows) == 0 && len(d.RemovedRows) == 0 && len(d.Changed) == 0
+}
+
+// DiffConstraints returns the differences between a and b. Rows are compared
+// in canonical form, so moving a term to the other side with its sign
+// flipped is not a change, and coefficient changes of at most tol are
+// ignored.
+func DiffConstraints(a, b []Constraint, tol float64) Diff {
+	var d Diff
+	_, va := IndexVariables(a)
+	_, vb := IndexVariables(b)
+	for name := range vb {
+		if _, ok := va[name]; !ok {
+			d.AddedVars = append(d.AddedVars, name)
+		}
+	}
+	for name := range va {
+		if _, ok := vb[name]; !ok {
+			d.RemovedVars = append(d.RemovedVars, name)
+		}
+	}
+	sort.Strings(d.AddedVars)
+	sort.Strings(d.RemovedVars)
+
+	n := min(len(a), len(b))
+	for i := n; i < len(b); i++ {
+		d.AddedRows = append(d.AddedRows, i)
+	}
+	for i := n; i < len(a); i++ {
+		d.RemovedRows = append(d.RemovedRows, i)
+	}
+	for i := 0; i < n; i++ {
+		if deltas := canonicalDeltas(a[i], b[i], tol); deltas != nil {
+			d.Changed = append(d.Changed, RowDiff{Row: i, Deltas: deltas})
+		}
+	}
+	return d
+}
+
+// canonicalDeltas returns the differences of the canonical coefficients of b
+// and a whose magnitude is greater than tol, sorted by variable name.
+func canonicalDeltas(a, b Constraint, tol float64) []Term {
+	ca := Canonicalize(a).Left
+	cb := Canonicalize(b).Left
+	var deltas []Term
+	for len(ca) > 0 || len(cb) > 0 {
+		var name string
+		var va, vb float64
+		switch {
+		case len(cb) == 0 || len(ca) > 0 && ca[0].Var < cb[0].Var:
+			name, va = ca[0].Var, ca[0].Value
+			ca = ca[1:]
+		case len(ca) == 0 || cb[0].Var < ca[0].Var:
+			name, vb = cb[0].Var, cb[0].Value
+			cb = cb[1:]
+		default:
+			name, va, vb = ca[0].Var, ca[0].Value, cb[0].Value
+			ca, cb = ca[1:], cb[1:]
+		}
+		if !(math.Abs(vb-va) <= tol) {
+			deltas = append(deltas, Term{name, vb - va})
+		}
+	}
+	return deltas
+}
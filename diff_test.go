@@ -0,0 +1,45 @@
+package benchlp
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiffConstraints(t *testing.T) {
+	a := []Constraint{
+		{Left: []Term{{"x", 1}, {"y", 2}}},
+		{Left: []Term{{"x", 1}}, Right: []Term{{"z", 3}}},
+		{Left: []Term{{"y", 1}}},
+		{Left: []Term{{"old", 1}}},
+	}
+	b := []Constraint{
+		// Unchanged, with a term moved to the other side.
+		{Left: []Term{{"x", 1}}, Right: []Term{{"y", -2}}},
+		// z changed beyond the tolerance, x within it, and w added.
+		{Left: []Term{{"x", 1.0000001}, {"w", 4}}, Right: []Term{{"z", 2}}},
+		{Left: []Term{{"y", 1}}},
+	}
+	got := DiffConstraints(a, b, 1e-6)
+	want := Diff{
+		AddedVars:   []string{"w"},
+		RemovedVars: []string{"old"},
+		RemovedRows: []int{3},
+		Changed: []RowDiff{
+			{Row: 1, Deltas: []Term{{"w", 4}, {"z", 1}}},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+	if got.Empty() {
+		t.Errorf("diff is empty")
+	}
+
+	got = DiffConstraints(b, a, 1e-6)
+	if !reflect.DeepEqual(got.AddedRows, []int{3}) || got.RemovedRows != nil {
+		t.Errorf("reversed rows: got added %v removed %v", got.AddedRows, got.RemovedRows)
+	}
+	if d := DiffConstraints(a, a, 0); !d.Empty() {
+		t.Errorf("diff of a set with itself: got %+v", d)
+	}
+}
@@ -0,0 +1,149 @@
+/*
+Copyright 2017 Brendan Tracey
+
+Redistribution and use in source and binary forms, with or without modification,
+are permitted provided that the following conditions are met:
+
+1. Redistributions of source code must retain the above copyright notice, this
+list of conditions and the following disclaimer.
+
+2. Redistributions in binary form must reproduce the above copyright notice,
+this list of conditions and the following disclaimer in the documentation and/or
+other materials provided with the distribution.
+
+3. Neither the name of the copyright holder nor the names of its contributors may
+be used to endorse or promote products derived from this software without specific
+prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED.
+IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT,
+INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF
+LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE
+OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED
+OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package benchlp
+
+import (
+	"encoding/binary"
+	"math"
+)
+
+// EliminateVariable returns the constraints with the variable eliminated by
+// Fourier–Motzkin elimination, so that they are satisfied by exactly the
+// values of the other variables for which some value of the variable
+// satisfies cons. Each constraint in which the variable has a positive
+// coefficient is combined with each in which it has a negative coefficient,
+// and the constraints without it are kept. The number of constraints can
+// grow quadratically, so it is meant for the analysis of small models.
+//
+// The result is pruned of redundant constraints: those with no variables
+// other than One that always hold, all but one that never hold, and all but
+// the tightest of those that are positive multiples of one another apart
+// from the term of One. The constraints are returned with all of the terms
+// on the left hand side, in the order of IndexVariables, scaled so that the
+// largest coefficient of a variable other than One has magnitude 1. It
+// panics if the variable is One.
+func EliminateVariable(cons []Constraint, name string) []Constraint {
+	if name == One {
+		panic("lp: cannot eliminate One")
+	}
+	names, nameMap := IndexVariables(cons)
+	k, ok := nameMap[name]
+	one, hasOne := nameMap[One]
+	rows := make([][]float64, len(cons))
+	var pos, neg []int
+	e := eliminator{names: names, one: -1, kept: make(map[string]int)}
+	if hasOne {
+		e.one = one
+	}
+	for i, c := range cons {
+		rows[i] = CondenseConstraint(nil, nil, c, nameMap)
+		switch {
+		case !ok || rows[i][k] == 0:
+			e.add(rows[i])
+		case rows[i][k] > 0:
+			pos = append(pos, i)
+		default:
+			neg = append(neg, i)
+		}
+	}
+	for _, p := range pos {
+		for _, n := range neg {
+			wp, wn := rows[p], rows[n]
+			ap, an := wp[k], -wn[k]
+			w := make([]float64, len(names))
+			for j := range w {
+				w[j] = an*wp[j] + ap*wn[j]
+			}
+			w[k] = 0
+			e.add(w)
+		}
+	}
+	return e.cons
+}
+
+// eliminator collects the pruned constraints of EliminateVariable.
+type eliminator struct {
+	names []string
+	one   int            // index of One, or -1
+	kept  map[string]int // index in cons by normalized coefficients
+	cons  []Constraint
+	key   []byte
+}
+
+// add adds the condensed constraint w unless it is redundant.
+func (e *eliminator) add(w []float64) {
+	var c float64
+	if e.one >= 0 {
+		c = w[e.one]
+	}
+	var scale float64
+	for j, v := range w {
+		if j != e.one {
+			scale = math.Max(scale, math.Abs(v))
+		}
+	}
+	if scale == 0 {
+		if c <= 0 {
+			return // always holds
+		}
+		scale = c
+	}
+	e.key = e.key[:0]
+	for j, v := range w {
+		if j != e.one && v != 0 {
+			e.key = binary.AppendUvarint(e.key, uint64(j))
+			e.key = binary.LittleEndian.AppendUint64(e.key, math.Float64bits(v/scale))
+		}
+	}
+	con := Constraint{}
+	for j, v := range w {
+		if v != 0 {
+			con.Left = append(con.Left, Term{e.names[j], v / scale})
+		}
+	}
+	if i, ok := e.kept[string(e.key)]; ok {
+		if c/scale > oneValue(e.cons[i]) {
+			e.cons[i] = con
+		}
+		return
+	}
+	e.kept[string(e.key)] = len(e.cons)
+	e.cons = append(e.cons, con)
+}
+
+// oneValue returns the coefficient of One in the condensed constraint.
+func oneValue(c Constraint) float64 {
+	for _, term := range c.Left {
+		if term.Var == One {
+			return term.Value
+		}
+	}
+	return 0
+}
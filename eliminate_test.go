@@ -0,0 +1,34 @@
+package benchlp
+
+import "testing"
+
+func TestEliminateVariable(t *testing.T) {
+	cons := []Constraint{
+		{Left: []Term{{"x", 1}}, Right: []Term{{"y", 1}}},                     // x <= y
+		{Left: []Term{{"y", 1}}, Right: []Term{{One, 4}}},                     // y <= 4
+		{Left: []Term{{"z", 1}}, Right: []Term{{"y", 2}}},                     // z <= 2y
+		{Left: []Term{{"x", 2}}, Right: []Term{{"y", 2}, {One, -2}}},          // 2x <= 2y - 2
+		{Left: []Term{{One, 1}}, Right: []Term{{"y", 1}, {"y", -1}}},          // 1 <= 0, never holds
+		{Left: []Term{{One, 2}}, Right: []Term{{"y", 1}, {"y", -1}}},          // 2 <= 0, never holds
+		{Left: []Term{{One, -1}, {"w", 1}}, Right: []Term{{"w", 1}}},          // -1 <= 0, always holds
+		{Left: []Term{{"x", 1}, {"z", 1}}, Right: []Term{{One, 1}, {"x", 1}}}, // z <= 1
+	}
+	got := EliminateVariable(cons, "y")
+	// Combining y <= 4 with each row with -y gives x <= 4, z <= 8 and
+	// x <= 3, which are pruned to the tightest of each pair.
+	want := []Constraint{
+		{Left: []Term{{One, 1}}},            // one of the rows that never hold
+		{Left: []Term{{One, -1}, {"z", 1}}}, // z <= 1
+		{Left: []Term{{"x", 1}, {One, -3}}}, // x <= 3
+	}
+	if !EqualConstraints(got, want, 1e-12) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+	for _, c := range got {
+		for _, term := range c.Left {
+			if term.Var == "y" {
+				t.Errorf("y not eliminated from %+v", c)
+			}
+		}
+	}
+}
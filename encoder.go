@@ -0,0 +1,336 @@
+/*
+Copyright 2017 Brendan Tracey
+
+Redistribution and use in source and binary forms, with or without modification,
+are permitted provided that the following conditions are met:
+
+1. Redistributions of source code must retain the above copyright notice, this
+list of conditions and the following disclaimer.
+
+2. Redistributions in binary form must reproduce the above copyright notice,
+this list of conditions and the following disclaimer in the documentation and/or
+other materials provided with the distribution.
+
+3. Neither the name of the copyright holder nor the names of its contributors may
+be used to endorse or promote products derived from this software without specific
+prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED.
+IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT,
+INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF
+LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE
+OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED
+OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package benchlp
+
+import (
+	"encoding/json"
+	"io"
+	"slices"
+	"strconv"
+)
+
+// ModelInfo describes the constraints written through an Encoder.
+type ModelInfo struct {
+	// Names are the variable names, in order of index.
+	Names []string
+	// Constraints and Nonzeros are the numbers of constraints and of
+	// non-zero condensed coefficients.
+	Constraints int
+	Nonzeros    int
+}
+
+// Encoder writes condensed constraints in an output format. EncodeConstraints
+// calls BeginModel once, then WriteConstraint for each constraint in order,
+// then EndModel, which flushes the output. The weights passed to
+// WriteConstraint are indexed as ModelInfo.Names, and are only valid for the
+// duration of the call.
+type Encoder interface {
+	BeginModel(m ModelInfo) error
+	WriteConstraint(i int, w []float64) error
+	EndModel() error
+}
+
+// EncodeConstraints condenses each constraint once and passes it to each of
+// the encoders, so that several formats can be written in one pass over the
+// constraints. The variables are ordered by order. The number of non-zeros,
+// which some formats need before the constraints, is counted in a first pass
+// over the terms of the constraints. EndModel is called on every encoder
+// even if writing fails, and the first error is returned.
+func EncodeConstraints(cons []Constraint, order VarOrder, encs ...Encoder) (err error) {
+	names, nameMap := IndexVariables(cons)
+	SortVariables(names, nameMap, order)
+	c1 := make([]float64, len(names))
+	c2 := make([]float64, len(names))
+	m := ModelInfo{Names: names, Constraints: len(cons)}
+	for _, c := range cons {
+		sparseCondense(c1, c2, c, nameMap, func(int, float64) { m.Nonzeros++ })
+	}
+
+	defer func() {
+		for _, enc := range encs {
+			if eerr := enc.EndModel(); err == nil {
+				err = eerr
+			}
+		}
+	}()
+	for _, enc := range encs {
+		if err := enc.BeginModel(m); err != nil {
+			return err
+		}
+	}
+	for i, c := range cons {
+		w := CondenseConstraint(c1, c2, c, nameMap)
+		for _, enc := range encs {
+			if err := enc.WriteConstraint(i, w); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// encoderOutput is the output of an encoder, opened by BeginModel with the
+// buffering and compression of the options.
+type encoderOutput struct {
+	w     io.Writer
+	opts  *Options
+	out   *outputWriter
+	names []string
+	b     []byte
+}
+
+func newEncoderOutput(w io.Writer, opts *Options) encoderOutput {
+	if opts == nil {
+		opts = &Options{}
+	}
+	return encoderOutput{w: w, opts: opts}
+}
+
+func (e *encoderOutput) begin(m ModelInfo) error {
+	if err := e.opts.Limits.checkModel(m); err != nil {
+		return err
+	}
+	out, err := e.opts.output(e.w)
+	if err != nil {
+		return err
+	}
+	e.out = out
+	e.names = m.Names
+	return nil
+}
+
+func (e *encoderOutput) write() error {
+	_, err := e.out.Write(e.b)
+	return err
+}
+
+func (e *encoderOutput) end() error {
+	if e.out == nil {
+		return nil
+	}
+	err := e.out.Close()
+	e.out = nil
+	return err
+}
+
+// NewLPEncoder returns an Encoder writing the constraints to w in the form of
+// WriteConstraintsTo, formatted according to opts, with the comments, the
+// variable attributes, and the names of opts.Names. The limits of
+// opts.Limits apply, with the non-zeros counted after condensing. The
+// options that change what is written rather than how, such as Cache,
+// DropTolerance, RHS, TwoSided, and VarScale, and the row attributes, are
+// ignored.
+func NewLPEncoder(w io.Writer, opts *Options) Encoder {
+	return &lpEncoder{newEncoderOutput(w, opts)}
+}
+
+type lpEncoder struct{ encoderOutput }
+
+func (e *lpEncoder) BeginModel(m ModelInfo) error {
+	if err := e.begin(m); err != nil {
+		return err
+	}
+	if err := e.opts.writeComments(e.out); err != nil {
+		return err
+	}
+	var err error
+	e.names, err = e.opts.writeNames(e.out, e.opts.commentPrefix(), m.Names)
+	return err
+}
+
+func (e *lpEncoder) WriteConstraint(i int, w []float64) error {
+	e.b = e.opts.appendConstraint(e.b[:0], w, e.names, 0)
+	return e.write()
+}
+
+func (e *lpEncoder) EndModel() error { return e.end() }
+
+// NewMatrixMarketEncoder returns an Encoder writing the constraints to w in
+// the form of WriteMatrixMarket. The limits of opts.Limits apply, with the
+// non-zeros counted after condensing.
+func NewMatrixMarketEncoder(w io.Writer, opts *Options) Encoder {
+	return &mtxEncoder{newEncoderOutput(w, opts)}
+}
+
+type mtxEncoder struct{ encoderOutput }
+
+func (e *mtxEncoder) BeginModel(m ModelInfo) error {
+	if err := e.begin(m); err != nil {
+		return err
+	}
+	e.b = append(e.b[:0], "%%MatrixMarket matrix coordinate real general\n"...)
+	e.b = strconv.AppendInt(e.b, int64(m.Constraints), 10)
+	e.b = append(e.b, ' ')
+	e.b = strconv.AppendInt(e.b, int64(len(m.Names)), 10)
+	e.b = append(e.b, ' ')
+	e.b = strconv.AppendInt(e.b, int64(m.Nonzeros), 10)
+	e.b = append(e.b, '\n')
+	return e.write()
+}
+
+func (e *mtxEncoder) WriteConstraint(i int, w []float64) error {
+	e.b = e.b[:0]
+	for j, v := range w {
+		if v == 0 {
+			continue
+		}
+		e.b = strconv.AppendInt(e.b, int64(i+1), 10)
+		e.b = append(e.b, ' ')
+		e.b = strconv.AppendInt(e.b, int64(j+1), 10)
+		e.b = append(e.b, ' ')
+		e.b = e.opts.appendFloat(e.b, v)
+		e.b = append(e.b, '\n')
+	}
+	return e.write()
+}
+
+func (e *mtxEncoder) EndModel() error { return e.end() }
+
+// NewMPSEncoder returns an Encoder writing the constraints to w in the form
+// of WriteMPS, with the names of opts.Names. The limits of opts.Limits
+// apply, with the non-zeros counted after condensing. The coefficients are
+// listed by column, so they are held in memory until EndModel, and
+// opts.TempDir is ignored, as are DropTolerance and Report.
+func NewMPSEncoder(w io.Writer, opts *Options) Encoder {
+	return &mpsEncoder{encoderOutput: newEncoderOutput(w, opts)}
+}
+
+type mpsEncoder struct {
+	encoderOutput
+	m   SparseMatrix
+	one int // index of One, or -1
+}
+
+func (e *mpsEncoder) BeginModel(m ModelInfo) error {
+	if err := e.begin(m); err != nil {
+		return err
+	}
+	e.m = SparseMatrix{
+		Rows:  m.Constraints,
+		Cols:  len(m.Names),
+		Ptr:   make([]int, 1, m.Constraints+1),
+		Index: make([]int, 0, m.Nonzeros),
+		Value: make([]float64, 0, m.Nonzeros),
+	}
+	e.one = slices.Index(m.Names, One)
+	var err error
+	e.names, err = e.opts.writeNames(e.out, "* ", m.Names)
+	return err
+}
+
+func (e *mpsEncoder) WriteConstraint(i int, w []float64) error {
+	for j, v := range w {
+		if v != 0 {
+			e.m.Index = append(e.m.Index, j)
+			e.m.Value = append(e.m.Value, v)
+		}
+	}
+	e.m.Ptr = append(e.m.Ptr, len(e.m.Index))
+	return nil
+}
+
+func (e *mpsEncoder) EndModel() error {
+	if e.out == nil {
+		return nil
+	}
+	err := e.writeMPS()
+	if cerr := e.end(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// writeMPS writes the rows, the transposed coefficients, and the bounds.
+func (e *mpsEncoder) writeMPS() error {
+	mw := &mpsWriter{w: e.out, opts: e.opts, one: e.one}
+	if err := mw.rows(e.m.Rows); err != nil {
+		return err
+	}
+	m := csrToCSC(e.m)
+	for j, name := range e.names {
+		lo, hi := m.Ptr[j], m.Ptr[j+1]
+		if err := mw.column(j, name, m.Index[lo:hi], m.Value[lo:hi]); err != nil {
+			return err
+		}
+	}
+	return mw.bounds(e.names)
+}
+
+// NewJSONEncoder returns an Encoder writing the constraints to w in the JSON
+// form of EncodeJSON, with each constraint condensed onto its left hand
+// side. Unlike EncodeJSON, it streams the constraints rather than holding
+// them all.
+func NewJSONEncoder(w io.Writer) Encoder {
+	return &jsonEncoder{encoderOutput: newEncoderOutput(w, nil)}
+}
+
+type jsonEncoder struct {
+	encoderOutput
+	terms []Term
+}
+
+func (e *jsonEncoder) BeginModel(m ModelInfo) error {
+	if err := e.begin(m); err != nil {
+		return err
+	}
+	e.b = append(e.b[:0], `{"constraints":[`...)
+	return e.write()
+}
+
+func (e *jsonEncoder) WriteConstraint(i int, w []float64) error {
+	e.terms = e.terms[:0]
+	for j, v := range w {
+		if v != 0 {
+			e.terms = append(e.terms, Term{e.names[j], v})
+		}
+	}
+	row, err := json.Marshal(Constraint{Left: e.terms})
+	if err != nil {
+		return err
+	}
+	e.b = e.b[:0]
+	if i > 0 {
+		e.b = append(e.b, ',')
+	}
+	e.b = append(e.b, row...)
+	return e.write()
+}
+
+func (e *jsonEncoder) EndModel() error {
+	if e.out == nil {
+		return nil
+	}
+	e.b = append(e.b[:0], "]}\n"...)
+	err := e.write()
+	if cerr := e.end(); err == nil {
+		err = cerr
+	}
+	return err
+}
@@ -0,0 +1,116 @@
+package benchlp
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+)
+
+func TestEncodeConstraints(t *testing.T) {
+	cons := randomSparseConstraints(50, 200, 2)
+	opts := &Options{Precision: -1, Order: Lexicographic, Comments: []string{"encoded"}}
+	var lp, mps, mtx, js bytes.Buffer
+	err := EncodeConstraints(cons, opts.Order,
+		NewLPEncoder(&lp, opts),
+		NewMPSEncoder(&mps, opts),
+		NewMatrixMarketEncoder(&mtx, opts),
+		NewJSONEncoder(&js),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range []struct {
+		name  string
+		got   []byte
+		write func(io.Writer, []Constraint, *Options) error
+	}{
+		{"lp", lp.Bytes(), WriteConstraintsTo},
+		{"mps", mps.Bytes(), WriteMPS},
+		{"mtx", mtx.Bytes(), WriteMatrixMarket},
+	} {
+		var want bytes.Buffer
+		if err := test.write(&want, cons, opts); err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(test.got, want.Bytes()) {
+			t.Errorf("%s: encoder output differs from the writer", test.name)
+		}
+	}
+	got, err := DecodeJSON(&js)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !EqualConstraints(got, cons, 1e-12) {
+		t.Errorf("JSON does not decode to the constraints")
+	}
+}
+
+// failingEncoder fails to write constraint fail, and records whether
+// EndModel was called.
+type failingEncoder struct {
+	fail  int
+	ended bool
+}
+
+func (e *failingEncoder) BeginModel(ModelInfo) error { return nil }
+
+func (e *failingEncoder) WriteConstraint(i int, w []float64) error {
+	if i == e.fail {
+		return errors.New("write failed")
+	}
+	return nil
+}
+
+func (e *failingEncoder) EndModel() error {
+	e.ended = true
+	return nil
+}
+
+func TestEncodeConstraintsError(t *testing.T) {
+	cons := randomConstraints(10, 20)
+	fail := &failingEncoder{fail: 5}
+	var buf bytes.Buffer
+	err := EncodeConstraints(cons, FirstAppearance, NewLPEncoder(&buf, nil), fail)
+	if err == nil || err.Error() != "write failed" {
+		t.Errorf("got error %v", err)
+	}
+	if !fail.ended || bytes.Count(buf.Bytes(), []byte("\n")) != 6 {
+		t.Errorf("encoders not ended: got %d lines", bytes.Count(buf.Bytes(), []byte("\n")))
+	}
+}
+
+func TestEncodeConstraintsOptions(t *testing.T) {
+	cons := []Constraint{
+		{Left: []Term{{"a b", 1}, {"c", 2}}, Right: []Term{{One, 1}}},
+		{Left: []Term{{"c", 1}}, Right: []Term{{"a b", 1}}},
+	}
+	for _, test := range []struct {
+		name  string
+		enc   func(io.Writer, *Options) Encoder
+		write func(io.Writer, []Constraint, *Options) error
+		rules NameRules
+	}{
+		{"lp", NewLPEncoder, WriteConstraintsTo, LPNames},
+		{"mps", NewMPSEncoder, WriteMPS, MPSNames},
+	} {
+		opts := &Options{Names: test.rules}
+		var got, want bytes.Buffer
+		if err := EncodeConstraints(cons, FirstAppearance, test.enc(&got, opts)); err != nil {
+			t.Fatal(err)
+		}
+		if err := test.write(&want, cons, opts); err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got.Bytes(), want.Bytes()) {
+			t.Errorf("%s: encoder output\n%s\ndiffers from the writer\n%s", test.name, got.String(), want.String())
+		}
+
+		opts = &Options{Limits: Limits{MaxNonzeros: 4}}
+		err := EncodeConstraints(cons, FirstAppearance, test.enc(io.Discard, opts))
+		var lerr *LimitError
+		if !errors.As(err, &lerr) || lerr.Limit != "nonzeros" {
+			t.Errorf("%s: got error %v, want the non-zero limit", test.name, err)
+		}
+	}
+}
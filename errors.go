@@ -0,0 +1,134 @@
+/*
+Copyright 2017 Brendan Tracey
+
+Redistribution and use in source and binary forms, with or without modification,
+are permitted provided that the following conditions are met:
+
+1. Redistributions of source code must retain the above copyright notice, this
+list of conditions and the following disclaimer.
+
+2. Redistributions in binary form must reproduce the above copyright notice,
+this list of conditions and the following disclaimer in the documentation and/or
+other materials provided with the distribution.
+
+3. Neither the name of the copyright holder nor the names of its contributors may
+be used to endorse or promote products derived from this software without specific
+prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED.
+IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT,
+INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF
+LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE
+OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED
+OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package benchlp
+
+import (
+	"errors"
+	"io"
+	"runtime"
+)
+
+var (
+	// ErrBadLength is returned when a weight vector does not have one
+	// element per variable.
+	ErrBadLength = errors.New("lp: bad length")
+	// ErrUnknownVariable is returned when a term's variable is not in the
+	// name map.
+	ErrUnknownVariable = errors.New("lp: term not present in name map")
+)
+
+// CondenseTermsErr is like CondenseTerms, but returns an error instead of
+// panicking if w has the wrong length or a variable is not in nameMap.
+func CondenseTermsErr(w []float64, terms []Term, nameMap map[string]int) ([]float64, error) {
+	nVar := len(nameMap)
+	if w == nil {
+		w = make([]float64, nVar)
+	} else {
+		for i := range w {
+			w[i] = 0
+		}
+	}
+	if len(w) != nVar {
+		return nil, ErrBadLength
+	}
+	for _, term := range terms {
+		idx, ok := nameMap[term.Var]
+		if !ok {
+			return nil, ErrUnknownVariable
+		}
+		w[idx] += term.Value
+	}
+	return w, nil
+}
+
+// CondenseConstraintErr is like CondenseConstraint, but returns an error
+// instead of panicking.
+func CondenseConstraintErr(wl, wr []float64, c Constraint, nameMap map[string]int) ([]float64, error) {
+	wl, err := CondenseTermsErr(wl, c.Left, nameMap)
+	if err != nil {
+		return nil, err
+	}
+	wr, err = CondenseTermsErr(wr, c.Right, nameMap)
+	if err != nil {
+		return nil, err
+	}
+	sub(wl, wr) // move the terms to the left hand side
+	return wl, nil
+}
+
+// WriteConstraintsErr is like WriteConstraints, but returns an error instead
+// of panicking. It is WriteConstraintsTo with the output discarded, which
+// recovers the panics of the writer; use WriteConstraintsTo to keep the
+// output.
+func WriteConstraintsErr(cons []Constraint, preallocate bool) error {
+	return WriteConstraintsTo(io.Discard, cons, &Options{AllocateScratch: !preallocate})
+}
+
+// recoverWrite is deferred by the writers to return the panic of an
+// invalid constraint or option as an error in *err, unless the Strict option
+// is set. An unknown variable of the Indexer option is reported as a
+// TermError for the first term that has one. Panics from the runtime,
+// which are bugs, are not recovered.
+func (o *Options) recoverWrite(err *error, cons []Constraint) {
+	if o.Strict {
+		return
+	}
+	r := recover()
+	if r == nil {
+		return
+	}
+	var perr error
+	switch v := r.(type) {
+	case runtime.Error:
+		panic(v)
+	case error:
+		perr = v
+	case string:
+		switch v {
+		case ErrBadLength.Error():
+			perr = ErrBadLength
+		case ErrUnknownVariable.Error():
+			perr = ErrUnknownVariable
+		default:
+			perr = errors.New(v)
+		}
+	default:
+		panic(v)
+	}
+	if perr == ErrUnknownVariable && o.Indexer != nil {
+		for i, c := range cons {
+			if terr := unknownVariable(i, c, o.Indexer); terr != nil {
+				perr = terr
+				break
+			}
+		}
+	}
+	*err = perr
+}
@@ -0,0 +1,74 @@
+package benchlp
+
+import (
+	"errors"
+	"io"
+	"testing"
+)
+
+func TestCondenseErr(t *testing.T) {
+	nameMap := map[string]int{"a": 0, "b": 1}
+	c := Constraint{Left: []Term{{"a", 1}}, Right: []Term{{"b", 2}, {"a", 3}}}
+	w, err := CondenseConstraintErr(nil, nil, c, nameMap)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w[0] != -2 || w[1] != -2 {
+		t.Errorf("got %v, want [-2 -2]", w)
+	}
+
+	if _, err := CondenseConstraintErr(make([]float64, 3), nil, c, nameMap); err != ErrBadLength {
+		t.Errorf("got error %v, want %v", err, ErrBadLength)
+	}
+	c.Right = append(c.Right, Term{"x", 1})
+	if _, err := CondenseConstraintErr(nil, nil, c, nameMap); err != ErrUnknownVariable {
+		t.Errorf("got error %v, want %v", err, ErrUnknownVariable)
+	}
+
+	func() {
+		defer func() {
+			if r := recover(); r != "lp: term not present in name map" {
+				t.Errorf("unexpected panic value %v", r)
+			}
+		}()
+		CondenseConstraint(nil, nil, c, nameMap)
+	}()
+}
+
+func TestWriteConstraintsErr(t *testing.T) {
+	for _, preallocate := range []bool{false, true} {
+		if err := WriteConstraintsErr(randomConstraints(10, 20), preallocate); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	}
+}
+
+func TestRecoverWrite(t *testing.T) {
+	cons := []Constraint{
+		{Left: []Term{{"v0", 1}}, Right: []Term{{"v1", 2}}},
+		{Left: []Term{{"v1", 1}}, Right: []Term{{"v7", 1}}},
+		{Left: []Term{{"v9", 1}}},
+	}
+	x := NumericIndexer{"v", 5}
+	valid, errs := ValidateRows(cons, x)
+	if len(valid) != 1 || len(errs) != 2 || errs[0].Constraint != 1 || errs[1].Constraint != 2 {
+		t.Fatalf("got valid %v and errors %v", valid, errs)
+	}
+	if want := `lp: constraint 1, right term 0: unknown variable "v7"`; errs[0].Error() != want {
+		t.Errorf("got message %q, want %q", errs[0].Error(), want)
+	}
+
+	opts := &Options{Indexer: x}
+	err := WriteConstraintsTo(io.Discard, cons, opts)
+	var terr *TermError
+	if !errors.As(err, &terr) || terr.Constraint != 1 || !errors.Is(err, ErrUnknownVariable) {
+		t.Errorf("got error %v, want a TermError for constraint 1", err)
+	}
+	_, err = WriteShards([]io.Writer{io.Discard}, cons[:1], RangePartition, &Options{VarScale: map[string]float64{"v0": -1}})
+	if err == nil || err.Error() != "lp: scale factor not positive" {
+		t.Errorf("invalid option: got error %v", err)
+	}
+	if err := WriteConstraintsTo(io.Discard, valid, opts); err != nil {
+		t.Errorf("valid rows: %v", err)
+	}
+}
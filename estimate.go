@@ -0,0 +1,147 @@
+/*
+Copyright 2017 Brendan Tracey
+
+Redistribution and use in source and binary forms, with or without modification,
+are permitted provided that the following conditions are met:
+
+1. Redistributions of source code must retain the above copyright notice, this
+list of conditions and the following disclaimer.
+
+2. Redistributions in binary form must reproduce the above copyright notice,
+this list of conditions and the following disclaimer in the documentation and/or
+other materials provided with the distribution.
+
+3. Neither the name of the copyright holder nor the names of its contributors may
+be used to endorse or promote products derived from this software without specific
+prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED.
+IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT,
+INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF
+LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE
+OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED
+OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package benchlp
+
+// The sizes in bytes of the values held for a set of constraints, for a
+// 64-bit platform. A map entry's size includes an allowance for the unused
+// slots and overflow buckets of the map.
+const (
+	constraintMem = 48 // two slice headers
+	termMem       = 24 // a string header and a float64
+	stringMem     = 16 // a string header
+	mapEntryMem   = 48
+	gzipMem       = 1 << 20
+)
+
+// estimateSamples is the number of terms formatted by EstimateWrite to
+// predict the width of a term.
+const estimateSamples = 4096
+
+// MemoryFootprint returns the approximate number of bytes of memory held by
+// the constraints: the constraints, their terms, and the variable names.
+// Each distinct name is counted once, as if all terms of a variable share
+// its string, so the footprint of constraints whose names were built
+// separately for each term is larger.
+func MemoryFootprint(cons []Constraint) int64 {
+	seen := make(map[string]bool)
+	n := int64(len(cons)) * constraintMem
+	for _, c := range cons {
+		for _, terms := range [2][]Term{c.Left, c.Right} {
+			n += int64(cap(terms)) * termMem
+			for _, term := range terms {
+				if !seen[term.Var] {
+					seen[term.Var] = true
+					n += int64(len(term.Var))
+				}
+			}
+		}
+	}
+	return n
+}
+
+// WriteEstimate is the predicted cost of writing constraints, as returned
+// by EstimateWrite.
+type WriteEstimate struct {
+	// Footprint is the memory held by the constraints, as returned by
+	// MemoryFootprint.
+	Footprint int64
+	// Peak is the memory used by the write in addition to Footprint: the
+	// variable index, the scratch weight vectors, the output buffer, the
+	// compressor, and the lines held by a Cache.
+	Peak int64
+	// Bytes is the size of the output before compression.
+	Bytes int64
+	// Nonzeros is the number of non-zero coefficients after condensing.
+	Nonzeros int64
+}
+
+// EstimateWrite predicts the memory used and the bytes written by
+// WriteConstraintsTo with the options, so that the resources for a large
+// write can be planned without trying it. It indexes the variables and
+// condenses the constraints as the write does, in a few passes over the
+// terms, but formats only a sample of the terms, which is much faster than
+// writing. The size of the output is predicted from the number of non-zeros
+// and the width of the sampled terms, so the formatting options are taken
+// into account. The comments, attributes, right hand sides, and dropped
+// coefficients are not, and TwoSided output is estimated as one-sided.
+func EstimateWrite(cons []Constraint, opts *Options) WriteEstimate {
+	if opts == nil {
+		opts = &Options{}
+	}
+	names, nameMap := IndexVariables(cons)
+	est := WriteEstimate{Footprint: MemoryFootprint(cons)}
+	var terms int
+	for _, c := range cons {
+		terms += len(c.Left) + len(c.Right)
+	}
+	step := max(terms/estimateSamples, 1)
+	var b []byte
+	var sampled, width, rows int64
+	wl := make([]float64, len(names))
+	wr := make([]float64, len(names))
+	for _, c := range cons {
+		var n int64
+		sparseCondense(wl, wr, c, nameMap, func(j int, v float64) {
+			if est.Nonzeros%int64(step) == 0 {
+				b = opts.appendTerm(b[:0], v, names[j], false)
+				width += int64(len(b))
+				sampled++
+			}
+			est.Nonzeros++
+			n++
+		})
+		if n > 0 {
+			rows++
+		}
+	}
+	if sampled > 0 {
+		est.Bytes = est.Nonzeros * width / sampled
+	}
+	// The first term of a row has no separator, and an empty row is
+	// written as its right hand side alone.
+	est.Bytes -= rows * int64(len(" + "))
+	b = opts.appendConstraint(b[:0], nil, nil, 0)
+	est.Bytes += int64(len(cons)) * int64(len(b))
+
+	size := opts.BufferSize
+	if size == 0 {
+		size = 64 << 10
+	}
+	// Each variable has a name in the index and the name map, and an
+	// element of each of the two weight vectors.
+	est.Peak = int64(len(names))*(stringMem+mapEntryMem+2*8) + int64(size)
+	if opts.Compress {
+		est.Peak += gzipMem
+	}
+	if opts.useCache() {
+		est.Peak += est.Bytes + int64(len(cons))*mapEntryMem
+	}
+	return est
+}
@@ -0,0 +1,45 @@
+package benchlp
+
+import (
+	"bytes"
+	"math"
+	"testing"
+)
+
+func TestMemoryFootprint(t *testing.T) {
+	cons := []Constraint{
+		{Left: []Term{{"x", 1}, {"yy", 2}}, Right: []Term{{"x", 1}}},
+		{Left: []Term{{"yy", 1}}},
+	}
+	want := int64(2*constraintMem + 4*termMem + len("x") + len("yy"))
+	if got := MemoryFootprint(cons); got != want {
+		t.Errorf("got %d, want %d", got, want)
+	}
+}
+
+func TestEstimateWrite(t *testing.T) {
+	g := &Generator{Vars: 2000, Density: 3}
+	cons := g.Generate(5000)
+	stats := ConstraintStats(cons)
+	for _, opts := range []*Options{
+		nil,
+		{Precision: -1},
+		{Format: 'e', Precision: 3, OmitUnit: true},
+		{Dialect: LPSolveDialect, Integers: true},
+	} {
+		est := EstimateWrite(cons, opts)
+		if est.Nonzeros != int64(stats.Nonzeros) {
+			t.Errorf("%+v: got %d non-zeros, want %d", opts, est.Nonzeros, stats.Nonzeros)
+		}
+		var buf bytes.Buffer
+		if err := WriteConstraintsTo(&buf, cons, opts); err != nil {
+			t.Fatal(err)
+		}
+		if n := int64(buf.Len()); math.Abs(float64(est.Bytes-n)) > 0.02*float64(n) {
+			t.Errorf("%+v: estimated %d bytes, wrote %d", opts, est.Bytes, n)
+		}
+		if est.Footprint <= 0 || est.Peak < 64<<10 {
+			t.Errorf("%+v: got %+v", opts, est)
+		}
+	}
+}
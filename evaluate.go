@@ -0,0 +1,84 @@
+/*
+Copyright 2017 Brendan Tracey
+
+Redistribution and use in source and binary forms, with or without modification,
+are permitted provided that the following conditions are met:
+
+1. Redistributions of source code must retain the above copyright notice, this
+list of conditions and the following disclaimer.
+
+2. Redistributions in binary form must reproduce the above copyright notice,
+this list of conditions and the following disclaimer in the documentation and/or
+other materials provided with the distribution.
+
+3. Neither the name of the copyright holder nor the names of its contributors may
+be used to endorse or promote products derived from this software without specific
+prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED.
+IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT,
+INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF
+LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE
+OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED
+OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package benchlp
+
+// Evaluation is the evaluation of a constraint at a candidate solution.
+type Evaluation struct {
+	// Activity is sum(Left) - sum(Right), which the constraint requires
+	// to be at most zero.
+	Activity float64
+	// Slack is -Activity, by how much the constraint is satisfied.
+	Slack float64
+	// Violation is max(0, Activity), by how much the constraint is
+	// violated.
+	Violation float64
+}
+
+// EvaluateConstraints evaluates the constraints at the candidate solution x,
+// such as the Values of a Solution, directly from their terms rather than
+// from the condensed form that was written. Variables missing from x have
+// the value zero, except One, which is one unless x gives it.
+func EvaluateConstraints(cons []Constraint, x map[string]float64) []Evaluation {
+	evals := make([]Evaluation, len(cons))
+	for i, c := range cons {
+		var left, right float64
+		for _, term := range c.Left {
+			left += term.Value * evalValue(x, term.Var)
+		}
+		for _, term := range c.Right {
+			right += term.Value * evalValue(x, term.Var)
+		}
+		a := left - right
+		evals[i] = Evaluation{Activity: a, Slack: -a, Violation: max(a, 0)}
+	}
+	return evals
+}
+
+// evalValue returns the value of the variable in x, with One defaulting to
+// one.
+func evalValue(x map[string]float64, name string) float64 {
+	v, ok := x[name]
+	if !ok && name == One {
+		return 1
+	}
+	return v
+}
+
+// Violated returns the indices of the constraints violated by more than tol
+// at x, as evaluated by EvaluateConstraints. A NaN activity is a violation.
+func Violated(cons []Constraint, x map[string]float64, tol float64) []int {
+	var rows []int
+	for i, e := range EvaluateConstraints(cons, x) {
+		if !(e.Activity <= tol) {
+			rows = append(rows, i)
+		}
+	}
+	return rows
+}
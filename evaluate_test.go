@@ -0,0 +1,39 @@
+package benchlp
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestEvaluateConstraints(t *testing.T) {
+	cons := []Constraint{
+		{Left: []Term{{"x", 2}, {"x", 1}}, Right: []Term{{"y", 1}}},    // 3x <= y
+		{Left: []Term{{"y", 1}}, Right: []Term{{One, 4}}},              // y <= 4
+		{Left: []Term{{"z", 1}}},                                       // z <= 0
+		{Left: []Term{{"x", 1}}, Right: []Term{{"y", 1}, {"w", 1e-9}}}, // x <= y + 1e-9 w
+	}
+	x := map[string]float64{"x": 2, "y": 5}
+	got := EvaluateConstraints(cons, x)
+	want := []Evaluation{
+		{Activity: 1, Slack: -1, Violation: 1},
+		{Activity: 1, Slack: -1, Violation: 1},
+		{Activity: 0, Slack: 0, Violation: 0},
+		{Activity: -3, Slack: 3, Violation: 0},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+	if got, want := Violated(cons, x, 1e-6), []int{0, 1}; !reflect.DeepEqual(got, want) {
+		t.Errorf("violated: got %v, want %v", got, want)
+	}
+
+	x[One] = 5
+	if got, want := Violated(cons, x, 0), []int{0}; !reflect.DeepEqual(got, want) {
+		t.Errorf("with One given: got %v, want %v", got, want)
+	}
+	x["z"] = math.NaN()
+	if got, want := Violated(cons, x, 0), []int{0, 2}; !reflect.DeepEqual(got, want) {
+		t.Errorf("with NaN: got %v, want %v", got, want)
+	}
+}
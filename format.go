@@ -0,0 +1,176 @@
+/*
+Copyright 2017 Brendan Tracey
+
+Redistribution and use in source and binary forms, with or without modification,
+are permitted provided that the following conditions are met:
+
+1. Redistributions of source code must retain the above copyright notice, this
+list of conditions and the following disclaimer.
+
+2. Redistributions in binary form must reproduce the above copyright notice,
+this list of conditions and the following disclaimer in the documentation and/or
+other materials provided with the distribution.
+
+3. Neither the name of the copyright holder nor the names of its contributors may
+be used to endorse or promote products derived from this software without specific
+prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED.
+IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT,
+INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF
+LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE
+OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED
+OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package benchlp
+
+import (
+	"bytes"
+	"math"
+	"strconv"
+)
+
+// Coefficients in generated models are frequently short decimals such as 1,
+// 0.5, or 2.25. appendShortest formats these directly, which is about twice
+// as fast as the general shortest formatting in strconv, and falls back to
+// strconv for other values at a small cost. BenchmarkFormat compares the
+// formatting paths.
+
+// pow10 holds the powers of ten used by the short decimal path.
+var pow10 = [...]float64{1, 1e1, 1e2, 1e3, 1e4, 1e5, 1e6}
+
+// appendShortest appends v formatted as strconv.AppendFloat(b, v, 'g', -1, 64)
+// does.
+func appendShortest(b []byte, v float64) []byte {
+	if b, ok := appendShortDecimal(b, v); ok {
+		return b
+	}
+	return strconv.AppendFloat(b, v, 'g', -1, 64)
+}
+
+// appendShortDecimal appends v if it is the nearest float64 to a decimal with
+// at most six digits after the decimal point and 1e-4 <= |v| < 1e6, and
+// returns whether it did.
+//
+// Any decimal with at most 15 significant digits is the unique shortest
+// representation of its nearest float64, so for such v the shortest
+// representation is that decimal. Within the magnitude range 'g' uses
+// positional rather than exponent notation.
+func appendShortDecimal(b []byte, v float64) ([]byte, bool) {
+	a := math.Abs(v)
+	if !(a >= 1e-4 && a < 1e6) {
+		return b, false
+	}
+	// A decimal with fewer places is also a decimal with six places, so a
+	// single test rejects values that are not short decimals.
+	if math.Round(a*1e6)/1e6 != a {
+		return b, false
+	}
+	places := 0
+	for math.Round(a*pow10[places])/pow10[places] != a {
+		places++
+	}
+	u := uint64(math.Round(a * pow10[places]))
+	scale := uint64(pow10[places])
+	if v < 0 {
+		b = append(b, '-')
+	}
+	b = strconv.AppendUint(b, u/scale, 10)
+	if places > 0 {
+		b = append(b, '.')
+		frac := u % scale
+		for scale /= 10; scale > 0; scale /= 10 {
+			b = append(b, byte('0'+frac/scale))
+			frac %= scale
+		}
+	}
+	return b, true
+}
+
+// appendExact appends the exact decimal value of v. Every finite float64 is a
+// dyadic rational m * 2^e, and for e < 0 its decimal expansion ends after
+// exactly -e digits, so formatting with that many digits involves no
+// rounding.
+func appendExact(b []byte, v float64) []byte {
+	if v == 0 || math.IsInf(v, 0) || math.IsNaN(v) {
+		return strconv.AppendFloat(b, v, 'g', -1, 64)
+	}
+	frac, e := math.Frexp(v)
+	m := int64(frac * (1 << 53))
+	e -= 53
+	for m&1 == 0 {
+		m >>= 1
+		e++
+	}
+	prec := 0
+	if e < 0 {
+		prec = -e
+	}
+	return strconv.AppendFloat(b, v, 'f', prec, 64)
+}
+
+// roundDigits returns v rounded to n significant digits.
+func roundDigits(v float64, n int) float64 {
+	if v == 0 || math.IsInf(v, 0) || math.IsNaN(v) {
+		return v
+	}
+	var buf [32]byte
+	r, err := strconv.ParseFloat(string(strconv.AppendFloat(buf[:0], v, 'e', n-1, 64)), 64)
+	if err != nil {
+		return v // n-1 digits of a finite float64 always parse
+	}
+	return r
+}
+
+// styleFloat rewrites the number appended to b at start according to the
+// Exponent, ShortExponent, and ForcePoint options. Numbers without digits,
+// such as Inf and NaN, are left alone.
+func (o *Options) styleFloat(b []byte, start int) []byte {
+	if o.Exponent == 0 && !o.ShortExponent && !o.ForcePoint {
+		return b
+	}
+	num := b[start:]
+	end := bytes.IndexAny(num, "eE")
+	if end < 0 {
+		end = len(num)
+	}
+	if end == 0 || num[end-1] < '0' || num[end-1] > '9' {
+		return b
+	}
+	var tmp [32]byte
+	out := append(tmp[:0], num[:end]...)
+	if o.ForcePoint && bytes.IndexByte(out, '.') < 0 {
+		out = append(out, '.', '0')
+	}
+	if end < len(num) {
+		exp := num[end+1:]
+		sign := byte('+')
+		if exp[0] == '+' || exp[0] == '-' {
+			sign = exp[0]
+			exp = exp[1:]
+		}
+		e := o.Exponent
+		if e == 0 {
+			e = num[end]
+		}
+		out = append(out, e)
+		if o.ShortExponent {
+			if sign == '-' {
+				out = append(out, '-')
+			}
+			exp = bytes.TrimLeft(exp, "0")
+			if len(exp) == 0 {
+				exp = []byte("0")
+			}
+		} else {
+			out = append(out, sign)
+		}
+		out = append(out, exp...)
+	}
+	return append(b[:start], out...)
+}
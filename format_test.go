@@ -0,0 +1,176 @@
+package benchlp
+
+import (
+	"math"
+	"math/big"
+	"math/rand"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestAppendShortest(t *testing.T) {
+	values := []float64{
+		0, 1, -1, 0.5, 2.25, 1e-4, 1.5e-4, 9.99e-5, 0.000123, 123456.5, 999999.999999,
+		1e6, 1e7, 0.1, 0.3, 9.3, 1.0 / 3, 12, 100, 1000000.5, math.Inf(1), math.NaN(),
+		math.Copysign(0, -1), 0.123456, 0.1234567, math.Nextafter(0.5, 1),
+	}
+	rnd := rand.New(rand.NewSource(1))
+	for i := 0; i < 10000; i++ {
+		k := rnd.Intn(8)
+		n := rnd.Int63n(1e10)
+		values = append(values, float64(n)/math.Pow(10, float64(k)), -float64(n)/math.Pow(10, float64(k+3)))
+		values = append(values, rnd.NormFloat64()*math.Pow(10, float64(rnd.Intn(12)-6)))
+	}
+	for _, v := range values {
+		want := strconv.FormatFloat(v, 'g', -1, 64)
+		got := string(appendShortest(nil, v))
+		if got != want {
+			t.Errorf("%v: got %s, want %s", v, got, want)
+		}
+	}
+}
+
+func TestAppendExact(t *testing.T) {
+	values := []float64{
+		0, 1, -1, 0.5, 0.1, -0.3, 1.0 / 3, 1e-20, 1e20, 1e300, -2.5e-300,
+		math.SmallestNonzeroFloat64, math.MaxFloat64, math.Nextafter(1, 2),
+	}
+	rnd := rand.New(rand.NewSource(1))
+	for i := 0; i < 1000; i++ {
+		values = append(values, rnd.NormFloat64()*math.Pow(10, float64(rnd.Intn(40)-20)))
+	}
+	for _, v := range values {
+		got := string(appendExact(nil, v))
+		r, ok := new(big.Rat).SetString(got)
+		if !ok {
+			t.Errorf("%v: cannot parse %s", v, got)
+			continue
+		}
+		if want := new(big.Rat).SetFloat64(v); r.Cmp(want) != 0 {
+			t.Errorf("%v: %s is not exact", v, got)
+		}
+		if strings.Contains(got, ".") && strings.HasSuffix(got, "0") {
+			t.Errorf("%v: %s has trailing zeros", v, got)
+		}
+	}
+}
+
+// solverNumber matches the numbers read by the LP and MPS readers of CPLEX,
+// Gurobi, and HiGHS: an optionally signed decimal with an optional exponent.
+var solverNumber = regexp.MustCompile(`^[+-]?([0-9]+\.?[0-9]*|\.[0-9]+)([eE][+-]?[0-9]+)?$`)
+
+func TestNumberStyle(t *testing.T) {
+	for i, test := range []struct {
+		opts Options
+		v    float64
+		want string
+	}{
+		{Options{}, 1e20, "1e+20"},
+		{Options{Exponent: 'E'}, 1e20, "1E+20"},
+		{Options{ShortExponent: true}, 1e20, "1e20"},
+		{Options{ShortExponent: true, Precision: -1}, -2.5e-7, "-2.5e-7"},
+		{Options{ShortExponent: true, Format: 'e', Precision: 1}, 1, "1.0e0"},
+		{Options{ForcePoint: true}, 2, "2.0"},
+		{Options{ForcePoint: true}, -3e30, "-3.0e+30"},
+		{Options{ForcePoint: true}, 0.25, "0.25"},
+		{Options{ForcePoint: true, Integers: true}, 2, "2"},
+		{Options{ForcePoint: true, Exact: true}, 4, "4.0"},
+		{Options{MaxDigits: 3, Precision: -1}, 1.0 / 3, "0.333"},
+		{Options{MaxDigits: 2, Precision: -1, ShortExponent: true}, 123456789, "1.2e8"},
+		{Options{ForcePoint: true, ShortExponent: true}, math.Inf(-1), "-Inf"},
+	} {
+		if got := string(test.opts.appendFloat([]byte("x "), test.v)); got != "x "+test.want {
+			t.Errorf("case %d: %v: got %q, want %q", i, test.v, got, "x "+test.want)
+		}
+	}
+
+	rnd := rand.New(rand.NewSource(1))
+	values := []float64{0, 1, -1, 0.1, 1e-300, 1e300, math.SmallestNonzeroFloat64}
+	for i := 0; i < 500; i++ {
+		values = append(values, rnd.NormFloat64()*math.Pow(10, float64(rnd.Intn(60)-30)))
+	}
+	for i, opts := range []Options{
+		{},
+		{Precision: -1, ShortExponent: true},
+		{Format: 'e', Exponent: 'E', ForcePoint: true},
+		{Format: 'f', Precision: -1, ForcePoint: true},
+		{MaxDigits: 6, ShortExponent: true, ForcePoint: true},
+		{Exact: true, ForcePoint: true},
+		{Integers: true, Precision: -1, Exponent: 'E'},
+	} {
+		for _, v := range values {
+			got := string(opts.appendFloat(nil, v))
+			if !solverNumber.MatchString(got) {
+				t.Errorf("options %d: %v: %q is not a solver number", i, v, got)
+				continue
+			}
+			r, err := strconv.ParseFloat(got, 64)
+			if err != nil {
+				t.Errorf("options %d: %v: %v", i, v, err)
+				continue
+			}
+			tol := 1e-15
+			if opts.MaxDigits > 0 {
+				tol = math.Pow(10, float64(1-opts.MaxDigits))
+			}
+			if math.Abs(r-v) > tol*math.Abs(v) {
+				t.Errorf("options %d: %v: %q parses to %v", i, v, got, r)
+			}
+		}
+	}
+}
+
+// formatValues returns coefficients typical of generated models, either
+// short decimals or full-precision random values.
+func formatValues(short bool) []float64 {
+	rnd := rand.New(rand.NewSource(1))
+	values := make([]float64, 1000)
+	for i := range values {
+		if short {
+			values[i] = float64(rnd.Intn(1000)) / 4
+		} else {
+			values[i] = rnd.Float64()
+		}
+	}
+	return values
+}
+
+func BenchmarkFormat(b *testing.B) {
+	for _, bm := range []struct {
+		name string
+		f    func([]byte, float64) []byte
+	}{
+		{"FormatFloat16", func(buf []byte, v float64) []byte {
+			return append(buf, []byte(strconv.FormatFloat(v, 'g', 16, 64))...)
+		}},
+		{"AppendFloat16", func(buf []byte, v float64) []byte {
+			return strconv.AppendFloat(buf, v, 'g', 16, 64)
+		}},
+		{"AppendFloat15", func(buf []byte, v float64) []byte {
+			return strconv.AppendFloat(buf, v, 'g', 15, 64)
+		}},
+		{"AppendFloatShortest", func(buf []byte, v float64) []byte {
+			return strconv.AppendFloat(buf, v, 'g', -1, 64)
+		}},
+		{"AppendShortest", appendShortest},
+	} {
+		for _, short := range []bool{true, false} {
+			name := bm.name + "/Full"
+			if short {
+				name = bm.name + "/Short"
+			}
+			b.Run(name, func(b *testing.B) {
+				values := formatValues(short)
+				var buf []byte
+				for i := 0; i < b.N; i++ {
+					buf = buf[:0]
+					for _, v := range values {
+						buf = bm.f(buf, v)
+					}
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,235 @@
+/*
+Copyright 2017 Brendan Tracey
+
+Redistribution and use in source and binary forms, with or without modification,
+are permitted provided that the following conditions are met:
+
+1. Redistributions of source code must retain the above copyright notice, this
+list of conditions and the following disclaimer.
+
+2. Redistributions in binary form must reproduce the above copyright notice,
+this list of conditions and the following disclaimer in the documentation and/or
+other materials provided with the distribution.
+
+3. Neither the name of the copyright holder nor the names of its contributors may
+be used to endorse or promote products derived from this software without specific
+prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED.
+IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT,
+INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF
+LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE
+OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED
+OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package benchlp
+
+import (
+	"math"
+	"runtime"
+	"strconv"
+	"sync"
+)
+
+// TermDist is a distribution of the number of terms on each side of a
+// generated constraint.
+type TermDist int
+
+const (
+	// ExponentialTerms gives one term plus the integer part of an
+	// exponential random variable with mean Density.
+	ExponentialTerms TermDist = iota
+	// PoissonTerms gives one term plus a Poisson random variable with mean
+	// Density.
+	PoissonTerms
+	// FixedTerms gives one term plus the integer part of Density.
+	FixedTerms
+)
+
+// CoeffDist is a distribution of the coefficients of generated terms.
+type CoeffDist int
+
+const (
+	// UniformCoeffs are uniform on [0, Scale).
+	UniformCoeffs CoeffDist = iota
+	// NormalCoeffs are normal with mean zero and standard deviation Scale.
+	NormalCoeffs
+	// IntegerCoeffs are integers uniform on [-Scale, Scale] excluding zero,
+	// with Scale truncated to an integer.
+	IntegerCoeffs
+)
+
+// Generator generates random sparse constraints. Generation is reproducible:
+// a Generator with the same fields always generates the same constraints.
+type Generator struct {
+	// Vars is the number of variables. Each term uses a variable chosen
+	// uniformly at random.
+	Vars int
+	// Prefix is the prefix of the variable names, which are the prefix
+	// followed by the variable index. The zero value is "v".
+	Prefix string
+
+	// Terms is the distribution of the number of terms on each side.
+	Terms TermDist
+	// Density is the mean number of terms beyond the first on each side.
+	Density float64
+
+	// Coeffs is the distribution of the term coefficients.
+	Coeffs CoeffDist
+	// Scale is the scale of the coefficient distribution. The zero value
+	// is 1.
+	Scale float64
+
+	// Seed seeds the random number generator.
+	Seed int64
+	// RNG is the random number generator. The zero value is MathRand.
+	RNG RNG
+}
+
+// Generate returns n random constraints.
+func (g *Generator) Generate(n int) []Constraint {
+	return g.generate(g.newRand(g.Seed), n)
+}
+
+// GenerateParallel returns n random constraints generated by the given
+// number of goroutines, or GOMAXPROCS goroutines if workers is not positive.
+//
+// The constraints are generated in blocks of 4096, each from its own random
+// stream seeded by Seed and the index of the block, so the result depends on
+// the fields of g but not on the number of workers. It is not the same as
+// the result of Generate, which uses a single stream.
+func (g *Generator) GenerateParallel(n, workers int) []Constraint {
+	prefix, scale := g.params()
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	blocks := (n + generateBlock - 1) / generateBlock
+	workers = min(workers, blocks)
+	cons := make([]Constraint, n)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for b := w; b < blocks; b += workers {
+				rnd := g.newRand(blockSeed(g.Seed, b))
+				lo, hi := b*generateBlock, min((b+1)*generateBlock, n)
+				g.fill(rnd, cons[lo:hi], prefix, scale)
+			}
+		}(w)
+	}
+	wg.Wait()
+	return cons
+}
+
+// generateBlock is the number of constraints generated from each random
+// stream by GenerateParallel. Changing it changes the generated constraints.
+const generateBlock = 4096
+
+// blockSeed returns the seed of the random stream of the given block, the
+// output of SplitMix64 for the block, so that the streams of neighboring
+// blocks and seeds are unrelated.
+func blockSeed(seed int64, block int) int64 {
+	return int64(mix64(uint64(seed) + uint64(block+1)*splitMixGamma))
+}
+
+// generate returns n random constraints using the random source rnd.
+func (g *Generator) generate(rnd randSource, n int) []Constraint {
+	prefix, scale := g.params()
+	cons := make([]Constraint, n)
+	g.fill(rnd, cons, prefix, scale)
+	return cons
+}
+
+// fill sets cons to random constraints using the random source rnd.
+func (g *Generator) fill(rnd randSource, cons []Constraint, prefix string, scale float64) {
+	terms := func() []Term {
+		t := make([]Term, g.termCount(rnd))
+		for i := range t {
+			t[i] = g.term(rnd, prefix, scale)
+		}
+		return t
+	}
+	for i := range cons {
+		cons[i].Left = terms()
+		cons[i].Right = terms()
+	}
+}
+
+// params checks the generator and returns the variable prefix and the
+// coefficient scale, with their defaults applied.
+func (g *Generator) params() (prefix string, scale float64) {
+	if g.Vars <= 0 {
+		panic("lp: generator has no variables")
+	}
+	prefix = g.Prefix
+	if prefix == "" {
+		prefix = "v"
+	}
+	scale = g.Scale
+	if scale == 0 {
+		scale = 1
+	}
+	if g.Coeffs == IntegerCoeffs && int(scale) < 1 {
+		panic("lp: integer coefficient scale less than one")
+	}
+	return prefix, scale
+}
+
+// term returns a random term.
+func (g *Generator) term(rnd randSource, prefix string, scale float64) Term {
+	return Term{prefix + strconv.Itoa(rnd.Intn(g.Vars)), g.coeff(rnd, scale)}
+}
+
+// termCount returns a random number of terms for one side of a constraint.
+func (g *Generator) termCount(rnd randSource) int {
+	switch g.Terms {
+	case ExponentialTerms:
+		return int(rnd.ExpFloat64()*g.Density) + 1
+	case PoissonTerms:
+		return poisson(rnd, g.Density) + 1
+	case FixedTerms:
+		return int(g.Density) + 1
+	}
+	panic("lp: unknown term distribution")
+}
+
+// coeff returns a random coefficient.
+func (g *Generator) coeff(rnd randSource, scale float64) float64 {
+	switch g.Coeffs {
+	case UniformCoeffs:
+		return rnd.Float64() * scale
+	case NormalCoeffs:
+		return rnd.NormFloat64() * scale
+	case IntegerCoeffs:
+		m := int(scale)
+		v := rnd.Intn(2*m) - m
+		if v >= 0 {
+			v++
+		}
+		return float64(v)
+	}
+	panic("lp: unknown coefficient distribution")
+}
+
+// poisson returns a Poisson random variable with the given mean. Large means
+// are split into pieces to avoid underflow in exp(-mean).
+func poisson(rnd randSource, mean float64) int {
+	var n int
+	for mean > 0 {
+		m := math.Min(mean, 500)
+		mean -= m
+		limit := math.Exp(-m)
+		p := rnd.Float64()
+		for p > limit {
+			p *= rnd.Float64()
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,86 @@
+package benchlp
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestGenerator(t *testing.T) {
+	for _, g := range []Generator{
+		{Vars: 50, Density: 1},
+		{Vars: 50, Prefix: "x", Terms: PoissonTerms, Density: 3, Coeffs: NormalCoeffs, Scale: 10, Seed: 1},
+		{Vars: 50, Terms: FixedTerms, Density: 2, Coeffs: IntegerCoeffs, Scale: 3, Seed: 2},
+	} {
+		n := 2000
+		cons := g.Generate(n)
+		if !reflect.DeepEqual(cons, g.Generate(n)) {
+			t.Errorf("%+v: generation not reproducible", g)
+		}
+		var nTerms int
+		for _, c := range cons {
+			for _, term := range append(append([]Term{}, c.Left...), c.Right...) {
+				v := term.Value
+				switch g.Coeffs {
+				case UniformCoeffs:
+					if v < 0 || v >= 1 {
+						t.Errorf("%+v: uniform coefficient %v out of range", g, v)
+					}
+				case IntegerCoeffs:
+					if v != math.Trunc(v) || v == 0 || math.Abs(v) > g.Scale {
+						t.Errorf("%+v: bad integer coefficient %v", g, v)
+					}
+				}
+			}
+			if g.Terms == FixedTerms && (len(c.Left) != 3 || len(c.Right) != 3) {
+				t.Errorf("%+v: got %d and %d terms, want 3", g, len(c.Left), len(c.Right))
+			}
+			nTerms += len(c.Left) + len(c.Right)
+		}
+		if g.Terms == PoissonTerms {
+			mean := float64(nTerms) / float64(2*n)
+			if math.Abs(mean-(1+g.Density)) > 0.1 {
+				t.Errorf("%+v: mean terms per side %v, want %v", g, mean, 1+g.Density)
+			}
+		}
+	}
+}
+
+func TestGenerateParallel(t *testing.T) {
+	const n = 3*generateBlock + 100
+	g := &Generator{Vars: 1000, Density: 2, Coeffs: NormalCoeffs, Seed: 5}
+	want := g.GenerateParallel(n, 1)
+	if len(want) != n {
+		t.Fatalf("got %d constraints, want %d", len(want), n)
+	}
+	for _, workers := range []int{2, 3, 8, 0} {
+		if got := g.GenerateParallel(n, workers); !reflect.DeepEqual(got, want) {
+			t.Errorf("%d workers: constraints differ from one worker", workers)
+		}
+	}
+	if reflect.DeepEqual(want[0], want[generateBlock]) {
+		t.Errorf("blocks start with the same constraint")
+	}
+	other := *g
+	other.Seed++
+	if reflect.DeepEqual(other.GenerateParallel(10, 1), want[:10]) {
+		t.Errorf("seeds %d and %d generate the same constraints", g.Seed, other.Seed)
+	}
+	if got := g.GenerateParallel(0, 4); len(got) != 0 {
+		t.Errorf("got %d constraints, want 0", len(got))
+	}
+}
+
+func BenchmarkGenerate(b *testing.B) {
+	g := &Generator{Vars: *benchVars, Density: *benchDensity}
+	b.Run("Serial", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			g.Generate(*benchCons)
+		}
+	})
+	b.Run("Parallel", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			g.GenerateParallel(*benchCons, 0)
+		}
+	})
+}
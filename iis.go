@@ -0,0 +1,108 @@
+/*
+Copyright 2017 Brendan Tracey
+
+Redistribution and use in source and binary forms, with or without modification,
+are permitted provided that the following conditions are met:
+
+1. Redistributions of source code must retain the above copyright notice, this
+list of conditions and the following disclaimer.
+
+2. Redistributions in binary form must reproduce the above copyright notice,
+this list of conditions and the following disclaimer in the documentation and/or
+other materials provided with the distribution.
+
+3. Neither the name of the copyright holder nor the names of its contributors may
+be used to endorse or promote products derived from this software without specific
+prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED.
+IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT,
+INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF
+LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE
+OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED
+OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+package benchlp
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// errFeasible is returned by FindIIS when the constraints are feasible.
+var errFeasible = errors.New("lp: constraints are feasible")
+
+// FindIIS finds an irreducible infeasible subsystem of the constraints with
+// a deletion filter: each constraint in turn is removed, and left out if the
+// rest are still infeasible. The result is the indices of the constraints
+// of the subsystem, in increasing order, which are infeasible together but
+// feasible if any one is removed. The subsystem is not necessarily the
+// smallest.
+//
+// Each check writes the constraints kept so far to a temporary MPS file and
+// solves it with s and the parameters p, so FindIIS solves as many problems
+// as there are constraints. A status of "infeasible" or "infeasible or
+// unbounded", which with no objective can only be infeasible, is
+// infeasible, and "optimal" is feasible. Any other status is an error, as
+// is a set of constraints that is feasible.
+func FindIIS(ctx context.Context, cons []Constraint, s Solver, p *SolverParams) ([]int, error) {
+	dir, err := os.MkdirTemp("", "benchlp")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+	model := filepath.Join(dir, "iis.mps")
+	infeasible := func(rows []int) (bool, error) {
+		sub := make([]Constraint, len(rows))
+		for k, i := range rows {
+			sub[k] = cons[i]
+		}
+		if err := writeMPSFile(model, sub, &Options{Precision: -1, Names: MPSNames}); err != nil {
+			return false, err
+		}
+		stats, err := s.Solve(ctx, model, p)
+		if err != nil {
+			return false, err
+		}
+		switch stats.Status {
+		case "infeasible", "infeasible or unbounded":
+			return true, nil
+		case "optimal":
+			return false, nil
+		}
+		return false, errors.New("lp: solver status " + strconv.Quote(stats.Status) + " is not feasible or infeasible")
+	}
+
+	rows := make([]int, len(cons))
+	for i := range rows {
+		rows[i] = i
+	}
+	ok, err := infeasible(rows)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, errFeasible
+	}
+	trial := make([]int, 0, len(rows))
+	for k := 0; k < len(rows); {
+		trial = append(append(trial[:0], rows[:k]...), rows[k+1:]...)
+		ok, err := infeasible(trial)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			rows, trial = trial, rows
+		} else {
+			k++
+		}
+	}
+	return rows, nil
+}
@@ -0,0 +1,92 @@
+package benchlp
+
+import (
+	"bufio"
+	"context"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// screenSolver is a Solver that reads back the MPS files written by
+// FindIIS and reports them infeasible if ScreenBounds finds a constraint
+// that cannot hold. It counts the problems solved.
+type screenSolver struct{ solves int }
+
+func (s *screenSolver) Solve(ctx context.Context, model string, p *SolverParams) (*RunStats, error) {
+	s.solves++
+	cons, err := readTestMPS(model)
+	if err != nil {
+		return nil, err
+	}
+	if len(ScreenBounds(cons, 1e-9)) > 0 {
+		return &RunStats{Status: "infeasible"}, nil
+	}
+	return &RunStats{Status: "optimal"}, nil
+}
+
+// readTestMPS reads the rows of an MPS file written by WriteMPS, as
+// constraints with the constants of the RHS section on the right.
+func readTestMPS(name string) ([]Constraint, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var cons []Constraint
+	var section string
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		line := sc.Text()
+		if !strings.HasPrefix(line, " ") {
+			section = line
+			continue
+		}
+		fields := strings.Fields(line)
+		switch section {
+		case "ROWS":
+			if fields[0] == "L" {
+				cons = append(cons, Constraint{})
+			}
+		case "COLUMNS", "RHS":
+			if fields[1] == "OBJ" {
+				continue
+			}
+			i, _ := strconv.Atoi(fields[1][1:])
+			v, _ := strconv.ParseFloat(fields[2], 64)
+			if section == "RHS" {
+				cons[i].Right = append(cons[i].Right, Term{One, v})
+			} else {
+				cons[i].Left = append(cons[i].Left, Term{fields[0], v})
+			}
+		}
+	}
+	return cons, sc.Err()
+}
+
+func TestFindIIS(t *testing.T) {
+	cons := []Constraint{
+		{Left: []Term{{"y", 1}}, Right: []Term{{One, 5}}},            // y <= 5
+		{Left: []Term{{"x", 1}}, Right: []Term{{One, 1}}},            // x <= 1
+		{Left: []Term{{"x", 1}, {"y", 1}}, Right: []Term{{One, 10}}}, // x + y <= 10
+		{Left: []Term{{One, 3}}, Right: []Term{{"x", 1}}},            // 3 <= x
+		{Left: []Term{{One, 2}}, Right: []Term{{"x", 1}}},            // 2 <= x
+	}
+	s := &screenSolver{}
+	rows, err := FindIIS(context.Background(), cons, s, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []int{1, 4}; !reflect.DeepEqual(rows, want) {
+		t.Errorf("got %v, want %v", rows, want)
+	}
+	if s.solves != len(cons)+1 {
+		t.Errorf("%d problems solved, want %d", s.solves, len(cons)+1)
+	}
+
+	if _, err := FindIIS(context.Background(), cons[:3], &screenSolver{}, nil); err != errFeasible {
+		t.Errorf("feasible constraints: got error %v", err)
+	}
+}
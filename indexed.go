@@ -0,0 +1,193 @@
+/*
+Copyright 2017 Brendan Tracey
+
+Redistribution and use in source and binary forms, with or without modification,
+are permitted provided that the following conditions are met:
+
+1. Redistributions of source code must retain the above copyright notice, this
+list of conditions and the following disclaimer.
+
+2. Redistributions in binary form must reproduce the above copyright notice,
+this list of conditions and the following disclaimer in the documentation and/or
+other materials provided with the distribution.
+
+3. Neither the name of the copyright holder nor the names of its contributors may
+be used to endorse or promote products derived from this software without specific
+prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED.
+IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT,
+INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF
+LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE
+OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED
+OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package benchlp
+
+import "io"
+
+// IndexedTerm is a term whose variable is identified by its index rather than
+// its name.
+type IndexedTerm struct {
+	VarID int
+	Value float64
+}
+
+// IndexedConstraint is a constraint made of IndexedTerms. It represents the
+// same inequality as a Constraint, sum(Left) <= sum(Right).
+type IndexedConstraint struct {
+	Left  []IndexedTerm
+	Right []IndexedTerm
+}
+
+// IndexConstraints converts the constraints to their indexed form, returning
+// the variable names indexed by VarID.
+func IndexConstraints(cons []Constraint) ([]IndexedConstraint, []string) {
+	names, nameMap := IndexVariables(cons)
+	indexed := make([]IndexedConstraint, len(cons))
+	for i, c := range cons {
+		indexed[i].Left = indexTerms(c.Left, nameMap)
+		indexed[i].Right = indexTerms(c.Right, nameMap)
+	}
+	return indexed, names
+}
+
+func indexTerms(terms []Term, nameMap map[string]int) []IndexedTerm {
+	if terms == nil {
+		return nil
+	}
+	indexed := make([]IndexedTerm, len(terms))
+	for i, term := range terms {
+		indexed[i] = IndexedTerm{nameMap[term.Var], term.Value}
+	}
+	return indexed
+}
+
+// CondenseIndexedTerms is the indexed equivalent of CondenseTerms for a
+// problem with nVar variables. If w is nil a new slice is allocated.
+func CondenseIndexedTerms(w []float64, terms []IndexedTerm, nVar int) []float64 {
+	if w == nil {
+		w = make([]float64, nVar)
+	} else {
+		for i := range w {
+			w[i] = 0
+		}
+	}
+	if len(w) != nVar {
+		panic("lp: bad length")
+	}
+	for _, term := range terms {
+		if term.VarID < 0 || term.VarID >= nVar {
+			panic("lp: variable index out of range")
+		}
+		w[term.VarID] += term.Value
+	}
+	return w
+}
+
+// CondenseIndexedConstraint is the indexed equivalent of CondenseConstraint
+// for a problem with nVar variables.
+func CondenseIndexedConstraint(wl, wr []float64, c IndexedConstraint, nVar int) []float64 {
+	wl = CondenseIndexedTerms(wl, c.Left, nVar)
+	wr = CondenseIndexedTerms(wr, c.Right, nVar)
+
+	sub(wl, wr) // move the terms to the left hand side
+	return wl
+}
+
+// WriteIndexedConstraints is the indexed equivalent of WriteConstraints for a
+// problem whose variables have the given names. No map lookups are needed to
+// condense the constraints.
+func WriteIndexedConstraints(cons []IndexedConstraint, names []string, preallocate bool) {
+	var b []byte
+	var c1, c2 []float64
+	if preallocate {
+		c1 = make([]float64, len(names))
+		c2 = make([]float64, len(names))
+	}
+	for _, c := range cons {
+		b = b[:0]
+		w := CondenseIndexedConstraint(c1, c2, c, len(names))
+		b = termBytes(b, w, names)
+		b = append(b, " <= 0\n"...)
+	}
+}
+
+// WriteIndexedConstraintsTo is the indexed equivalent of WriteConstraintsTo
+// for a problem whose variables have the given names.
+func WriteIndexedConstraintsTo(w io.Writer, cons []IndexedConstraint, names []string, opts *Options) (err error) {
+	if opts == nil {
+		opts = &Options{}
+	}
+	defer opts.recoverWrite(&err, nil)
+	if err := opts.Limits.checkIndexed(cons); err != nil {
+		return err
+	}
+	out, err := opts.output(w)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cerr := out.Close(); err == nil {
+			err = cerr
+		}
+	}()
+
+	// Condense into the sorted order and permute the names to match.
+	var perm []int
+	if opts.Order != FirstAppearance {
+		sorted := append([]string(nil), names...)
+		nameMap := make(map[string]int, len(names))
+		for i, name := range sorted {
+			nameMap[name] = i
+		}
+		SortVariables(sorted, nameMap, opts.Order)
+		perm = make([]int, len(names))
+		for i, name := range names {
+			perm[i] = nameMap[name]
+		}
+		names = sorted
+	}
+
+	c1 := make([]float64, len(names))
+	c2 := make([]float64, len(names))
+	var c3 []float64
+	if opts.TwoSided {
+		c3 = make([]float64, len(names))
+	}
+	var b []byte
+	for _, c := range cons {
+		if opts.TwoSided {
+			wl := CondenseIndexedTerms(c1, c.Left, len(names))
+			wr := CondenseIndexedTerms(c2, c.Right, len(names))
+			if perm != nil {
+				permute(wl, c3, perm)
+				permute(wr, c3, perm)
+			}
+			b = opts.appendTwoSided(b[:0], wl, wr, names, 0)
+		} else {
+			wt := CondenseIndexedConstraint(c1, c2, c, len(names))
+			if perm != nil {
+				permute(wt, c2, perm)
+			}
+			b = opts.appendConstraint(b[:0], wt, names, 0)
+		}
+		if _, err := out.Write(b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// permute moves w[i] to w[perm[i]], using tmp as scratch.
+func permute(w, tmp []float64, perm []int) {
+	for i, v := range w {
+		tmp[perm[i]] = v
+	}
+	copy(w, tmp)
+}
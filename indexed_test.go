@@ -0,0 +1,39 @@
+package benchlp
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestWriteIndexedConstraintsTo(t *testing.T) {
+	cons := randomConstraints(50, 200)
+	indexed, names := IndexConstraints(cons)
+	for _, opts := range []*Options{nil, {Order: Natural, Precision: -1}} {
+		var want, got bytes.Buffer
+		if err := WriteConstraintsTo(&want, cons, opts); err != nil {
+			t.Fatal(err)
+		}
+		if err := WriteIndexedConstraintsTo(&got, indexed, names, opts); err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got.Bytes(), want.Bytes()) {
+			t.Errorf("options %+v: indexed output differs", opts)
+		}
+	}
+}
+
+func BenchmarkLPIndexedNoAllocate(b *testing.B) {
+	benchmarkLPIndexed(b, false)
+}
+
+func BenchmarkLPIndexedAllocate(b *testing.B) {
+	benchmarkLPIndexed(b, true)
+}
+
+func benchmarkLPIndexed(b *testing.B, preal bool) {
+	cons, names := IndexConstraints(randomSparseConstraints(*benchVars, *benchCons, *benchDensity))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		WriteIndexedConstraints(cons, names, preal)
+	}
+}
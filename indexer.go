@@ -0,0 +1,174 @@
+/*
+Copyright 2017 Brendan Tracey
+
+Redistribution and use in source and binary forms, with or without modification,
+are permitted provided that the following conditions are met:
+
+1. Redistributions of source code must retain the above copyright notice, this
+list of conditions and the following disclaimer.
+
+2. Redistributions in binary form must reproduce the above copyright notice,
+this list of conditions and the following disclaimer in the documentation and/or
+other materials provided with the distribution.
+
+3. Neither the name of the copyright holder nor the names of its contributors may
+be used to endorse or promote products derived from this software without specific
+prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED.
+IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT,
+INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF
+LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE
+OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED
+OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package benchlp
+
+import "strconv"
+
+// VarIndexer assigns each variable a unique index in [0, Len()).
+type VarIndexer interface {
+	// Len returns the number of variables.
+	Len() int
+	// Name returns the name of the variable with index i.
+	Name(i int) string
+	// Index returns the index of the named variable, and whether the
+	// variable is known.
+	Index(name string) (int, bool)
+}
+
+// MapIndexer is a VarIndexer using the names and name map returned by
+// IndexVariables.
+type MapIndexer struct {
+	Names []string
+	Map   map[string]int
+}
+
+func (x MapIndexer) Len() int          { return len(x.Names) }
+func (x MapIndexer) Name(i int) string { return x.Names[i] }
+
+func (x MapIndexer) Index(name string) (int, bool) {
+	i, ok := x.Map[name]
+	return i, ok
+}
+
+// NumericIndexer is a VarIndexer for the variables named by Prefix followed
+// by a decimal integer in [0, N), such as "v0" to "v999", as produced by
+// Generator. Variable i is named Prefix followed by i, and names are parsed
+// directly rather than looked up in a map. Names with leading zeros or a
+// sign are not known.
+type NumericIndexer struct {
+	Prefix string
+	N      int
+}
+
+func (x NumericIndexer) Len() int          { return x.N }
+func (x NumericIndexer) Name(i int) string { return x.Prefix + strconv.Itoa(i) }
+
+func (x NumericIndexer) Index(name string) (int, bool) {
+	if len(name) <= len(x.Prefix) || name[:len(x.Prefix)] != x.Prefix {
+		return 0, false
+	}
+	digits := name[len(x.Prefix):]
+	if len(digits) > 1 && digits[0] == '0' {
+		return 0, false
+	}
+	var i int
+	for j := 0; j < len(digits); j++ {
+		c := digits[j]
+		if !isDigit(c) {
+			return 0, false
+		}
+		i = i*10 + int(c-'0')
+		if i >= x.N {
+			return 0, false
+		}
+	}
+	return i, true
+}
+
+// condenseIndexed is CondenseConstraint with the variables indexed by x. It
+// panics if a variable is not known to x.
+func condenseIndexed(wl, wr []float64, c Constraint, x VarIndexer) []float64 {
+	condenseIndexedTerms(wl, c.Left, x)
+	condenseIndexedTerms(wr, c.Right, x)
+	sub(wl, wr)
+	return wl
+}
+
+// condenseIndexedTerms is CondenseTerms with the variables indexed by x.
+func condenseIndexedTerms(w []float64, terms []Term, x VarIndexer) []float64 {
+	for i := range w {
+		w[i] = 0
+	}
+	for _, term := range terms {
+		i, ok := x.Index(term.Var)
+		if !ok {
+			panic(ErrUnknownVariable.Error())
+		}
+		w[i] += term.Value
+	}
+	return w
+}
+
+// Index assigns indices to variables incrementally, in order of first
+// appearance, so that variables can be indexed as constraints are generated
+// rather than in a separate pass as by IndexVariables. An Index is a
+// VarIndexer, and can be passed as the Indexer option when writing. The zero
+// value is an empty Index ready to use.
+type Index struct {
+	names   []string
+	nameMap map[string]int
+}
+
+// Add indexes the variable of the term if it is new.
+func (x *Index) Add(term Term) {
+	x.addName(term.Var)
+}
+
+// AddConstraint indexes the new variables of the constraint, left hand side
+// first, as IndexVariables does.
+func (x *Index) AddConstraint(c Constraint) {
+	for _, term := range c.Left {
+		x.addName(term.Var)
+	}
+	for _, term := range c.Right {
+		x.addName(term.Var)
+	}
+}
+
+// Merge indexes the variables of other that are new to x, in the order of
+// other. If other indexed constraints following those indexed by x, the
+// result is as if x had indexed them all.
+func (x *Index) Merge(other *Index) {
+	for _, name := range other.names {
+		x.addName(name)
+	}
+}
+
+func (x *Index) addName(name string) {
+	if x.nameMap == nil {
+		x.nameMap = make(map[string]int)
+	}
+	x.names, x.nameMap = addNameIfNew(name, x.names, x.nameMap)
+}
+
+// Names returns the names and name map of the variables, in the form
+// returned by IndexVariables. They are shared with x and must not be
+// modified, and are only valid until the next variable is added.
+func (x *Index) Names() ([]string, map[string]int) {
+	return x.names, x.nameMap
+}
+
+func (x *Index) Len() int          { return len(x.names) }
+func (x *Index) Name(i int) string { return x.names[i] }
+
+func (x *Index) Index(name string) (int, bool) {
+	i, ok := x.nameMap[name]
+	return i, ok
+}
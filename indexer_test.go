@@ -0,0 +1,139 @@
+package benchlp
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+)
+
+func TestNumericIndexer(t *testing.T) {
+	x := NumericIndexer{Prefix: "v", N: 100}
+	for _, test := range []struct {
+		name string
+		idx  int
+		ok   bool
+	}{
+		{"v0", 0, true},
+		{"v7", 7, true},
+		{"v99", 99, true},
+		{"v100", 0, false},
+		{"v07", 0, false},
+		{"v", 0, false},
+		{"v-1", 0, false},
+		{"w1", 0, false},
+		{"v1a", 0, false},
+		{"v99999999999999999999999", 0, false},
+	} {
+		idx, ok := x.Index(test.name)
+		if idx != test.idx || ok != test.ok {
+			t.Errorf("%q: got %d, %t, want %d, %t", test.name, idx, ok, test.idx, test.ok)
+		}
+	}
+	for i := 0; i < x.N; i++ {
+		if idx, ok := x.Index(x.Name(i)); !ok || idx != i {
+			t.Errorf("name %q of %d indexes to %d", x.Name(i), i, idx)
+		}
+	}
+}
+
+func TestWriteConstraintsIndexer(t *testing.T) {
+	cons := randomConstraints(50, 200)
+	var want, got bytes.Buffer
+	if err := WriteConstraintsTo(&want, cons, &Options{Order: Natural}); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteConstraintsTo(&got, cons, &Options{Indexer: NumericIndexer{"v", 50}}); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got.Bytes(), want.Bytes()) {
+		t.Errorf("output with NumericIndexer differs from natural order")
+	}
+
+	names, nameMap := IndexVariables(cons)
+	got.Reset()
+	if err := WriteConstraintsTo(&got, cons, &Options{Indexer: MapIndexer{names, nameMap}}); err != nil {
+		t.Fatal(err)
+	}
+	want.Reset()
+	if err := WriteConstraintsTo(&want, cons, nil); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got.Bytes(), want.Bytes()) {
+		t.Errorf("output with MapIndexer differs")
+	}
+
+	err := WriteConstraintsTo(io.Discard, cons, &Options{Indexer: NumericIndexer{"v", 10}})
+	if !errors.Is(err, ErrUnknownVariable) {
+		t.Errorf("got error %v for unknown variable, want %v", err, ErrUnknownVariable)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("no panic for unknown variable in strict mode")
+		}
+	}()
+	WriteConstraintsTo(io.Discard, cons, &Options{Indexer: NumericIndexer{"v", 10}, Strict: true})
+}
+
+func BenchmarkIndexer(b *testing.B) {
+	cons := randomSparseConstraints(*benchVars, *benchCons, *benchDensity)
+	for _, bm := range []struct {
+		name string
+		opts *Options
+	}{
+		{"Map", &Options{Order: Natural}},
+		{"Numeric", &Options{Indexer: NumericIndexer{"v", *benchVars}}},
+	} {
+		b.Run(bm.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				WriteConstraintsTo(io.Discard, cons, bm.opts)
+			}
+		})
+	}
+}
+
+func TestIndex(t *testing.T) {
+	cons := randomConstraints(100, 300)
+	wantNames, wantMap := IndexVariables(cons)
+
+	var x Index
+	for _, c := range cons {
+		x.AddConstraint(c)
+	}
+	names, nameMap := x.Names()
+	if !reflect.DeepEqual(names, wantNames) || !reflect.DeepEqual(nameMap, wantMap) {
+		t.Errorf("AddConstraint does not match IndexVariables")
+	}
+
+	var a, b Index
+	for i, c := range cons {
+		dst := &a
+		if i >= len(cons)/2 {
+			dst = &b
+		}
+		for _, term := range c.Left {
+			dst.Add(term)
+		}
+		for _, term := range c.Right {
+			dst.Add(term)
+		}
+	}
+	a.Merge(&b)
+	names, nameMap = a.Names()
+	if !reflect.DeepEqual(names, wantNames) || !reflect.DeepEqual(nameMap, wantMap) {
+		t.Errorf("merged indices do not match IndexVariables")
+	}
+
+	var want, got bytes.Buffer
+	if err := WriteConstraintsTo(&want, cons, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteConstraintsTo(&got, cons, &Options{Indexer: &a}); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got.Bytes(), want.Bytes()) {
+		t.Errorf("output with Index differs")
+	}
+}
@@ -0,0 +1,154 @@
+/*
+Copyright 2017 Brendan Tracey
+
+Redistribution and use in source and binary forms, with or without modification,
+are permitted provided that the following conditions are met:
+
+1. Redistributions of source code must retain the above copyright notice, this
+list of conditions and the following disclaimer.
+
+2. Redistributions in binary form must reproduce the above copyright notice,
+this list of conditions and the following disclaimer in the documentation and/or
+other materials provided with the distribution.
+
+3. Neither the name of the copyright holder nor the names of its contributors may
+be used to endorse or promote products derived from this software without specific
+prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED.
+IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT,
+INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF
+LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE
+OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED
+OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package benchlp
+
+import (
+	"context"
+	"io"
+	"time"
+)
+
+// Progress describes how far a write has got.
+type Progress struct {
+	Constraints int   // constraints written so far
+	Total       int   // total number of constraints
+	Bytes       int64 // bytes written so far, before compression
+	Elapsed     time.Duration
+}
+
+// ETA estimates the time remaining in the write, assuming the remaining
+// constraints are written at the average rate so far.
+func (p Progress) ETA() time.Duration {
+	if p.Constraints == 0 {
+		return 0
+	}
+	remaining := float64(p.Total - p.Constraints)
+	return time.Duration(float64(p.Elapsed) * remaining / float64(p.Constraints))
+}
+
+// instruments holds the optional instrumentation of a write.
+type instruments struct {
+	ctx    context.Context
+	rep    *Report
+	labels *phaseLabels
+
+	progress func(Progress)
+	interval int
+	start    time.Time
+
+	checkpoint func(Checkpoint)
+	cpInterval int
+	out        *outputWriter
+	written    *countingWriter
+	first      int // index of the first constraint to write
+}
+
+// writeInstrumented renders the constraints to w, starting from in.first,
+// recording the condensing and formatting times, labelling the phases,
+// reporting progress and checkpoints, and checking for cancellation as
+// requested by in. Constraint attributes are written before each
+// constraint.
+func (r *Renderer) writeInstrumented(w io.Writer, cons []Constraint, in *instruments) error {
+	var t0, t1 time.Time
+	var bytes int64
+	rep, labels := in.rep, in.labels
+	cache := r.opts.useCache()
+	done := in.ctx.Done()
+	for i := in.first; i < len(cons); i++ {
+		c := cons[i]
+		if done != nil && i%cancelInterval == 0 {
+			if err := in.ctx.Err(); err != nil {
+				return err
+			}
+		}
+		b := r.buf[:0]
+		if r.opts.Attributes != nil && !r.opts.TrailingAttributes {
+			b = r.opts.appendRowAttributes(b, i)
+		}
+		if rep != nil {
+			t0 = time.Now()
+		}
+		var hit bool
+		if cache {
+			b, hit = r.cached(b, c)
+		}
+		var wl, wr []float64
+		if !hit {
+			labels.set(labels.condense())
+			wl, wr = r.condense(c)
+		}
+		if rep != nil {
+			t1 = time.Now()
+			if r.dropped > 0 {
+				if rep.Dropped == nil {
+					rep.Dropped = make(map[int]int)
+				}
+				rep.Dropped[i] = r.dropped
+			}
+		}
+		if !hit {
+			labels.set(labels.format())
+			start := len(b)
+			b = r.format(b, wl, wr)
+			if cache {
+				r.cacheLine(b[start:], wl, wr)
+			}
+		}
+		if r.opts.Attributes != nil && r.opts.TrailingAttributes {
+			b = r.opts.appendTrailingAttributes(b, i)
+		}
+		r.buf = b
+		n, err := w.Write(r.buf)
+		bytes += int64(n)
+		if rep != nil {
+			rep.CondenseTime += t1.Sub(t0)
+			rep.FormatTime += time.Since(t1)
+			rep.Bytes += int64(n)
+		}
+		if err != nil {
+			return err
+		}
+		if in.progress != nil && ((i+1)%in.interval == 0 || i == len(cons)-1) {
+			in.progress(Progress{
+				Constraints: i + 1,
+				Total:       len(cons),
+				Bytes:       bytes,
+				Elapsed:     time.Since(in.start),
+			})
+		}
+		if in.checkpoint != nil && (i+1)%in.cpInterval == 0 {
+			if err := in.out.checkpoint(); err != nil {
+				return err
+			}
+			in.checkpoint(Checkpoint{Constraints: i + 1, Bytes: in.written.n})
+		}
+	}
+	return nil
+}
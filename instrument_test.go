@@ -0,0 +1,42 @@
+package benchlp
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestWriteConstraintsToProgress(t *testing.T) {
+	cons := randomConstraints(100, 1050)
+	var buf bytes.Buffer
+	var calls []Progress
+	opts := &Options{
+		BufferSize:       1,
+		ProgressInterval: 100,
+		Progress: func(p Progress) {
+			calls = append(calls, p)
+		},
+	}
+	if err := WriteConstraintsTo(&buf, cons, opts); err != nil {
+		t.Fatal(err)
+	}
+	if len(calls) != 11 {
+		t.Fatalf("got %d progress calls, want 11", len(calls))
+	}
+	for i, p := range calls[:10] {
+		if p.Constraints != 100*(i+1) || p.Total != len(cons) {
+			t.Errorf("call %d: got %d of %d constraints", i, p.Constraints, p.Total)
+		}
+	}
+	last := calls[len(calls)-1]
+	if last.Constraints != len(cons) || last.Bytes != int64(buf.Len()) || last.ETA() != 0 {
+		t.Errorf("unexpected final progress %+v", last)
+	}
+}
+
+func TestProgressETA(t *testing.T) {
+	p := Progress{Constraints: 25, Total: 100, Elapsed: time.Minute}
+	if p.ETA() != 3*time.Minute {
+		t.Errorf("got ETA %v, want %v", p.ETA(), 3*time.Minute)
+	}
+}
@@ -0,0 +1,105 @@
+/*
+Copyright 2017 Brendan Tracey
+
+Redistribution and use in source and binary forms, with or without modification,
+are permitted provided that the following conditions are met:
+
+1. Redistributions of source code must retain the above copyright notice, this
+list of conditions and the following disclaimer.
+
+2. Redistributions in binary form must reproduce the above copyright notice,
+this list of conditions and the following disclaimer in the documentation and/or
+other materials provided with the distribution.
+
+3. Neither the name of the copyright holder nor the names of its contributors may
+be used to endorse or promote products derived from this software without specific
+prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED.
+IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT,
+INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF
+LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE
+OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED
+OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package benchlp
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+)
+
+// jsonModel is the top-level JSON representation of a set of constraints.
+type jsonModel struct {
+	Constraints []Constraint `json:"constraints"`
+	Attributes  *Attributes  `json:"attributes,omitempty"`
+}
+
+// EncodeJSON writes the constraints to w as JSON. The schema is
+//
+//	{
+//	  "constraints": [
+//	    {
+//	      "left":  [{"var": "v1", "value": 2.5}, ...],
+//	      "right": [{"var": "v7", "value": 1}, ...]
+//	    },
+//	    ...
+//	  ]
+//	}
+//
+// where each constraint represents sum(left) <= sum(right). Either side may
+// be omitted when it has no terms. Coefficients must be finite.
+func EncodeJSON(w io.Writer, cons []Constraint) error {
+	return EncodeJSONAttributes(w, cons, nil)
+}
+
+// EncodeJSONAttributes is like EncodeJSON, but also writes the attributes,
+// if not nil, as
+//
+//	"attributes": {
+//	  "vars": {"v1": {"unit": "kg"}, ...},
+//	  "rows": {"0": {"source": "plant.go:12"}, ...}
+//	}
+//
+// after the constraints.
+func EncodeJSONAttributes(w io.Writer, cons []Constraint, attrs *Attributes) error {
+	return json.NewEncoder(w).Encode(jsonModel{Constraints: cons, Attributes: attrs})
+}
+
+// DecodeJSON reads constraints from r in the format written by EncodeJSON.
+// Unknown fields and terms without a variable name are rejected. Attributes
+// are ignored.
+func DecodeJSON(r io.Reader) ([]Constraint, error) {
+	cons, _, err := DecodeJSONAttributes(r)
+	return cons, err
+}
+
+// DecodeJSONAttributes is like DecodeJSON, but also returns the attributes,
+// or nil if there are none.
+func DecodeJSONAttributes(r io.Reader) ([]Constraint, *Attributes, error) {
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	var m jsonModel
+	if err := dec.Decode(&m); err != nil {
+		return nil, nil, err
+	}
+	for _, c := range m.Constraints {
+		for _, term := range c.Left {
+			if term.Var == "" {
+				return nil, nil, errors.New("lp: term with empty variable name")
+			}
+		}
+		for _, term := range c.Right {
+			if term.Var == "" {
+				return nil, nil, errors.New("lp: term with empty variable name")
+			}
+		}
+	}
+	return m.Constraints, m.Attributes, nil
+}
@@ -0,0 +1,46 @@
+package benchlp
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestJSONRoundTrip(t *testing.T) {
+	cons := randomConstraints(20, 30)
+	cons = append(cons, Constraint{Left: []Term{{"a", 1}}})
+	var buf bytes.Buffer
+	if err := EncodeJSON(&buf, cons); err != nil {
+		t.Fatal(err)
+	}
+	got, err := DecodeJSON(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, cons) {
+		t.Errorf("round trip mismatch")
+	}
+}
+
+func TestDecodeJSON(t *testing.T) {
+	in := `{"constraints": [{"left": [{"var": "x", "value": 2}], "right": [{"var": "y", "value": 1.5}]}]}`
+	got, err := DecodeJSON(strings.NewReader(in))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Constraint{{Left: []Term{{"x", 2}}, Right: []Term{{"y", 1.5}}}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	for _, in := range []string{
+		`{"constraints": [{"left": [{"value": 2}]}]}`,
+		`{"constraints": [{"lhs": []}]}`,
+		`{"constraints": [`,
+	} {
+		if _, err := DecodeJSON(strings.NewReader(in)); err == nil {
+			t.Errorf("no error decoding %s", in)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+/*
+Copyright 2017 Brendan Tracey
+
+Redistribution and use in source and binary forms, with or without modification,
+are permitted provided that the following conditions are met:
+
+1. Redistributions of source code must retain the above copyright notice, this
+list of conditions and the following disclaimer.
+
+2. Redistributions in binary form must reproduce the above copyright notice,
+this list of conditions and the following disclaimer in the documentation and/or
+other materials provided with the distribution.
+
+3. Neither the name of the copyright holder nor the names of its contributors may
+be used to endorse or promote products derived from this software without specific
+prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED.
+IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT,
+INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF
+LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE
+OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED
+OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package benchlp
+
+import (
+	"context"
+	"runtime/pprof"
+)
+
+// PhaseLabel is the pprof label key set by the writers when
+// Options.ProfileLabels is set. Its values are "index", "condense", and
+// "format".
+const PhaseLabel = "benchlp.phase"
+
+// phaseLabels holds the label contexts for the phases of a write, created
+// once so that switching phases does not allocate.
+type phaseLabels struct {
+	base, index, condenseCtx, formatCtx context.Context
+}
+
+func newPhaseLabels(base context.Context) *phaseLabels {
+	return &phaseLabels{
+		base:        base,
+		index:       pprof.WithLabels(base, pprof.Labels(PhaseLabel, "index")),
+		condenseCtx: pprof.WithLabels(base, pprof.Labels(PhaseLabel, "condense")),
+		formatCtx:   pprof.WithLabels(base, pprof.Labels(PhaseLabel, "format")),
+	}
+}
+
+func (l *phaseLabels) condense() context.Context {
+	if l == nil {
+		return nil
+	}
+	return l.condenseCtx
+}
+
+func (l *phaseLabels) format() context.Context {
+	if l == nil {
+		return nil
+	}
+	return l.formatCtx
+}
+
+// set sets the labels of the current goroutine to those of ctx. It does
+// nothing if l is nil.
+func (l *phaseLabels) set(ctx context.Context) {
+	if l != nil {
+		pprof.SetGoroutineLabels(ctx)
+	}
+}
@@ -0,0 +1,43 @@
+package benchlp
+
+import (
+	"bytes"
+	"io"
+	"runtime/pprof"
+	"testing"
+)
+
+func TestWriteConstraintsToProfileLabels(t *testing.T) {
+	cons := randomConstraints(100, 100)
+	var want, got bytes.Buffer
+	if err := WriteConstraintsTo(&want, cons, nil); err != nil {
+		t.Fatal(err)
+	}
+	labels := &profileWriter{}
+	if err := WriteConstraintsTo(io.MultiWriter(&got, labels), cons, &Options{ProfileLabels: true, BufferSize: 1}); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got.Bytes(), want.Bytes()) {
+		t.Errorf("output differs with profile labels")
+	}
+	if !labels.format {
+		t.Errorf("format label not set during writes")
+	}
+}
+
+// profileWriter records whether the goroutine calling Write was labelled
+// with the format phase, using the labels reported in the goroutine profile.
+type profileWriter struct {
+	checked bool
+	format  bool
+}
+
+func (w *profileWriter) Write(b []byte) (int, error) {
+	if !w.checked {
+		w.checked = true
+		var buf bytes.Buffer
+		pprof.Lookup("goroutine").WriteTo(&buf, 1)
+		w.format = bytes.Contains(buf.Bytes(), []byte(`"`+PhaseLabel+`":"format"`))
+	}
+	return len(b), nil
+}
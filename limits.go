@@ -0,0 +1,127 @@
+/*
+Copyright 2017 Brendan Tracey
+
+Redistribution and use in source and binary forms, with or without modification,
+are permitted provided that the following conditions are met:
+
+1. Redistributions of source code must retain the above copyright notice, this
+list of conditions and the following disclaimer.
+
+2. Redistributions in binary form must reproduce the above copyright notice,
+this list of conditions and the following disclaimer in the documentation and/or
+other materials provided with the distribution.
+
+3. Neither the name of the copyright holder nor the names of its contributors may
+be used to endorse or promote products derived from this software without specific
+prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED.
+IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT,
+INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF
+LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE
+OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED
+OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package benchlp
+
+import (
+	"io"
+	"strconv"
+)
+
+// Limits bounds the size of a problem, so that a generator in error fails
+// with a LimitError rather than filling the disk or exhausting memory. A
+// zero field is no limit.
+//
+// Nonzeros are counted as terms before condensing, which is an upper bound
+// on the nonzeros of the condensed constraints that is known without
+// indexing the variables. The encoders, which condense the constraints
+// first, count the condensed non-zeros.
+type Limits struct {
+	MaxConstraints int
+	MaxNonzeros    int
+	// MaxBytes bounds the bytes written to each output, after compression.
+	MaxBytes int64
+}
+
+// LimitError is returned when a limit is exceeded.
+type LimitError struct {
+	Limit string // "constraints", "nonzeros", or "bytes"
+	Max   int64
+}
+
+func (e *LimitError) Error() string {
+	return "lp: limit of " + strconv.FormatInt(e.Max, 10) + " " + e.Limit + " exceeded"
+}
+
+// check returns a *LimitError if the constraints exceed the limits.
+func (l Limits) check(cons []Constraint) error {
+	if l.MaxConstraints > 0 && len(cons) > l.MaxConstraints {
+		return &LimitError{"constraints", int64(l.MaxConstraints)}
+	}
+	if l.MaxNonzeros > 0 {
+		var nnz int
+		for _, c := range cons {
+			nnz += len(c.Left) + len(c.Right)
+			if nnz > l.MaxNonzeros {
+				return &LimitError{"nonzeros", int64(l.MaxNonzeros)}
+			}
+		}
+	}
+	return nil
+}
+
+// checkIndexed is check for indexed constraints.
+func (l Limits) checkIndexed(cons []IndexedConstraint) error {
+	if l.MaxConstraints > 0 && len(cons) > l.MaxConstraints {
+		return &LimitError{"constraints", int64(l.MaxConstraints)}
+	}
+	if l.MaxNonzeros > 0 {
+		var nnz int
+		for _, c := range cons {
+			nnz += len(c.Left) + len(c.Right)
+			if nnz > l.MaxNonzeros {
+				return &LimitError{"nonzeros", int64(l.MaxNonzeros)}
+			}
+		}
+	}
+	return nil
+}
+
+// checkModel returns a *LimitError if the condensed constraints described by
+// m exceed the limits.
+func (l Limits) checkModel(m ModelInfo) error {
+	if l.MaxConstraints > 0 && m.Constraints > l.MaxConstraints {
+		return &LimitError{"constraints", int64(l.MaxConstraints)}
+	}
+	if l.MaxNonzeros > 0 && m.Nonzeros > l.MaxNonzeros {
+		return &LimitError{"nonzeros", int64(l.MaxNonzeros)}
+	}
+	return nil
+}
+
+// limitWriter fails any write that would take the bytes written to w past
+// max, and every write after it, so the output is cut at a write boundary.
+type limitWriter struct {
+	w      io.Writer
+	n, max int64
+	err    error
+}
+
+func (w *limitWriter) Write(b []byte) (int, error) {
+	if w.err != nil {
+		return 0, w.err
+	}
+	if w.n+int64(len(b)) > w.max {
+		w.err = &LimitError{"bytes", w.max}
+		return 0, w.err
+	}
+	n, err := w.w.Write(b)
+	w.n += int64(n)
+	return n, err
+}
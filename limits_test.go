@@ -0,0 +1,87 @@
+package benchlp
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+)
+
+func TestLimits(t *testing.T) {
+	g := &Generator{Vars: 50, Seed: 1}
+	cons := g.Generate(100)
+	var nnz int
+	for _, c := range cons {
+		nnz += len(c.Left) + len(c.Right)
+	}
+	for _, test := range []struct {
+		limits Limits
+		limit  string
+	}{
+		{Limits{MaxConstraints: 100, MaxNonzeros: nnz, MaxBytes: 1 << 30}, ""},
+		{Limits{MaxConstraints: 99}, "constraints"},
+		{Limits{MaxNonzeros: nnz - 1}, "nonzeros"},
+		{Limits{MaxBytes: 1000}, "bytes"},
+	} {
+		var buf bytes.Buffer
+		opts := &Options{Limits: test.limits, BufferSize: 100}
+		err := WriteConstraintsTo(&buf, cons, opts)
+		var lerr *LimitError
+		if test.limit == "" {
+			if err != nil {
+				t.Errorf("%+v: unexpected error %v", test.limits, err)
+			}
+			continue
+		}
+		if !errors.As(err, &lerr) || lerr.Limit != test.limit {
+			t.Errorf("%+v: got error %v, want %s limit", test.limits, err, test.limit)
+		}
+		if test.limit == "bytes" {
+			if buf.Len() > 1000 {
+				t.Errorf("wrote %d bytes past the limit", buf.Len())
+			}
+		} else if buf.Len() != 0 {
+			t.Errorf("%+v: wrote %d bytes", test.limits, buf.Len())
+		}
+	}
+
+	opts := &Options{Limits: Limits{MaxConstraints: 1}}
+	if err := WriteAMPL(io.Discard, cons, opts); err == nil {
+		t.Error("WriteAMPL: no error")
+	}
+	icons, names := IndexConstraints(cons)
+	if err := WriteIndexedConstraintsTo(io.Discard, icons, names, opts); err == nil {
+		t.Error("WriteIndexedConstraintsTo: no error")
+	}
+}
+
+func TestBuilderLimits(t *testing.T) {
+	b := Builder{Limits: Limits{MaxConstraints: 3, MaxNonzeros: 4}}
+	c := Constraint{Left: []Term{{"x", 1}}, Right: []Term{{"y", 1}}}
+	if err := b.AddConstraintErr(c); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.AddConstraintErr(c); err != nil {
+		t.Fatal(err)
+	}
+	err := b.AddConstraintErr(c)
+	var lerr *LimitError
+	if !errors.As(err, &lerr) || lerr.Limit != "nonzeros" || lerr.Max != 4 {
+		t.Errorf("got error %v, want nonzeros limit", err)
+	}
+	if err := b.AddConstraintErr(Constraint{}); err != nil {
+		t.Errorf("empty constraint: %v", err)
+	}
+	if err := b.AddConstraintErr(Constraint{}); err == nil {
+		t.Error("no error past the constraint limit")
+	}
+	if n := len(b.Constraints()); n != 3 {
+		t.Errorf("got %d constraints, want 3", n)
+	}
+	defer func() {
+		if recover() == nil {
+			t.Error("AddConstraint did not panic")
+		}
+	}()
+	b.AddConstraint(c)
+}
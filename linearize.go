@@ -0,0 +1,95 @@
+/*
+Copyright 2017 Brendan Tracey
+
+Redistribution and use in source and binary forms, with or without modification,
+are permitted provided that the following conditions are met:
+
+1. Redistributions of source code must retain the above copyright notice, this
+list of conditions and the following disclaimer.
+
+2. Redistributions in binary form must reproduce the above copyright notice,
+this list of conditions and the following disclaimer in the documentation and/or
+other materials provided with the distribution.
+
+3. Neither the name of the copyright holder nor the names of its contributors may
+be used to endorse or promote products derived from this software without specific
+prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED.
+IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT,
+INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF
+LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE
+OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED
+OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package benchlp
+
+// The helpers below introduce an auxiliary variable, named by a prefix on
+// the given name, and the constraints that tie it to linear expressions.
+// Variables in this package are free and continuous, so each helper gives a
+// one-sided bound on the auxiliary variable: it equals the abs, max, or min
+// at an optimum whose objective pushes it toward that bound, as when
+// minimizing an absolute deviation.
+
+// AbsVar returns the variable "abs_"+name and the constraints
+//
+//	expr <= abs_name,  -expr <= abs_name
+//
+// so that abs_name >= |expr|.
+func AbsVar(name string, expr []Term) (string, []Constraint) {
+	t := "abs_" + name
+	return t, []Constraint{
+		{Left: copyTerms(expr, 1), Right: []Term{{t, 1}}},
+		{Left: copyTerms(expr, -1), Right: []Term{{t, 1}}},
+	}
+}
+
+// MaxVar returns the variable "max_"+name and a constraint expr <= max_name
+// for each of the expressions, so that max_name >= max(exprs).
+func MaxVar(name string, exprs ...[]Term) (string, []Constraint) {
+	t := "max_" + name
+	cons := make([]Constraint, len(exprs))
+	for i, expr := range exprs {
+		cons[i] = Constraint{Left: copyTerms(expr, 1), Right: []Term{{t, 1}}}
+	}
+	return t, cons
+}
+
+// MinVar returns the variable "min_"+name and a constraint min_name <= expr
+// for each of the expressions, so that min_name <= min(exprs).
+func MinVar(name string, exprs ...[]Term) (string, []Constraint) {
+	t := "min_" + name
+	cons := make([]Constraint, len(exprs))
+	for i, expr := range exprs {
+		cons[i] = Constraint{Left: []Term{{t, 1}}, Right: copyTerms(expr, 1)}
+	}
+	return t, cons
+}
+
+// Implies returns the big-M constraint that enforces c when the variable b
+// is 1 and relaxes it by bigM when b is 0,
+//
+//	sum(c.Left) - sum(c.Right) <= bigM*(1 - b)
+//
+// written as c.Left + bigM*b <= c.Right + bigM*One. b must be declared
+// binary to the solver, as this package has no integrality, and bigM must
+// bound sum(c.Left) - sum(c.Right) over the feasible region.
+func Implies(b string, c Constraint, bigM float64) Constraint {
+	left := append(copyTerms(c.Left, 1), Term{b, bigM})
+	right := append(copyTerms(c.Right, 1), Term{One, bigM})
+	return Constraint{Left: left, Right: right}
+}
+
+// copyTerms returns a copy of the terms with the values multiplied by scale.
+func copyTerms(terms []Term, scale float64) []Term {
+	out := make([]Term, len(terms), len(terms)+1)
+	for i, term := range terms {
+		out[i] = Term{term.Var, scale * term.Value}
+	}
+	return out
+}
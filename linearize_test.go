@@ -0,0 +1,59 @@
+package benchlp
+
+import "testing"
+
+func TestLinearize(t *testing.T) {
+	expr := []Term{{"x", 2}, {"y", -1}}
+	x := map[string]float64{"x": 1, "y": 5} // expr = -3
+
+	abs, cons := AbsVar("d", expr)
+	if abs != "abs_d" {
+		t.Errorf("got variable %q", abs)
+	}
+	for _, test := range []struct {
+		v  float64
+		ok bool
+	}{{3, true}, {4, true}, {2.9, false}} {
+		x[abs] = test.v
+		if satisfied(cons, x) != test.ok {
+			t.Errorf("abs_d = %v: satisfied %t", test.v, !test.ok)
+		}
+	}
+
+	other := []Term{{"x", 1}} // 1
+	max, cons := MaxVar("m", expr, other)
+	for _, test := range []struct {
+		v  float64
+		ok bool
+	}{{1, true}, {0.5, false}} {
+		x[max] = test.v
+		if satisfied(cons, x) != test.ok {
+			t.Errorf("max_m = %v: satisfied %t", test.v, !test.ok)
+		}
+	}
+	min, cons := MinVar("m", expr, other)
+	for _, test := range []struct {
+		v  float64
+		ok bool
+	}{{-3, true}, {-2, false}} {
+		x[min] = test.v
+		if satisfied(cons, x) != test.ok {
+			t.Errorf("min_m = %v: satisfied %t", test.v, !test.ok)
+		}
+	}
+
+	// x <= 0 when b is 1, with x <= 10 always.
+	c := Constraint{Left: []Term{{"x", 1}}}
+	imp := []Constraint{Implies("b", c, 10)}
+	for _, test := range []struct {
+		b, x float64
+		ok   bool
+	}{{1, 0, true}, {1, 1, false}, {0, 10, true}, {0, 11, false}} {
+		if satisfied(imp, map[string]float64{"b": test.b, "x": test.x}) != test.ok {
+			t.Errorf("b = %v, x = %v: satisfied %t", test.b, test.x, !test.ok)
+		}
+	}
+	if len(c.Left) != 1 || len(expr) != 2 || expr[1].Value != -1 {
+		t.Errorf("inputs modified")
+	}
+}
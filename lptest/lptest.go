@@ -0,0 +1,188 @@
+/*
+Copyright 2017 Brendan Tracey
+
+Redistribution and use in source and binary forms, with or without modification,
+are permitted provided that the following conditions are met:
+
+1. Redistributions of source code must retain the above copyright notice, this
+list of conditions and the following disclaimer.
+
+2. Redistributions in binary form must reproduce the above copyright notice,
+this list of conditions and the following disclaimer in the documentation and/or
+other materials provided with the distribution.
+
+3. Neither the name of the copyright holder nor the names of its contributors may
+be used to endorse or promote products derived from this software without specific
+prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED.
+IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT,
+INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF
+LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE
+OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED
+OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+// Package lptest helps programs that generate constraints with package
+// benchlp lock down their output in their own tests. Golden writes the
+// constraints with fixed options and compares the output with a golden
+// file, reporting the differing lines, and rewrites the golden files when
+// the tests are run with -lptest.update.
+package lptest
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/btracey/benchlp"
+)
+
+// Update is whether Golden rewrites the golden files rather than comparing
+// with them. It is set by the -lptest.update flag.
+var Update = flag.Bool("lptest.update", false, "rewrite the golden files of lptest.Golden")
+
+// maxDiffLines is the number of differing lines reported by Golden.
+const maxDiffLines = 20
+
+// Golden writes the constraints with the options, in the format given by
+// the extension of the golden file, .lp or .mps, and reports an error
+// through t if the output differs from the file, listing the differing
+// lines. The comparison is exact, so the options should fix everything
+// that affects the output, and comments that vary between runs, such as
+// those of benchlp.Provenance, should not be used. With Update set the
+// file, and its directory, are written instead.
+func Golden(t testing.TB, golden string, cons []benchlp.Constraint, opts *benchlp.Options) {
+	t.Helper()
+	var buf bytes.Buffer
+	var err error
+	switch ext := filepath.Ext(golden); ext {
+	case ".lp":
+		err = benchlp.WriteConstraintsTo(&buf, cons, opts)
+	case ".mps":
+		err = benchlp.WriteMPS(&buf, cons, opts)
+	default:
+		t.Fatalf("lptest: unknown golden file extension %q", ext)
+		return
+	}
+	if err != nil {
+		t.Fatalf("lptest: writing %s: %v", golden, err)
+		return
+	}
+	if *Update {
+		if err := os.MkdirAll(filepath.Dir(golden), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(golden, buf.Bytes(), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatalf("lptest: %v; run with -lptest.update to create it", err)
+		return
+	}
+	if d := Diff(want, buf.Bytes()); d != "" {
+		t.Errorf("lptest: output differs from %s (-want +got):\n%s", golden, d)
+	}
+}
+
+// Diff returns the lines that differ between want and got, with each line
+// of want that is missing from got prefixed by "-" and each line of got
+// that is missing from want by "+", after its line number in that input.
+// Lines are matched by a longest common subsequence, so a row inserted or
+// removed is reported as a single line. It returns the empty string only if
+// the inputs are equal, and lists at most 20 lines. Inputs that differ only
+// in whether they end in a newline are reported as such.
+func Diff(want, got []byte) string {
+	if bytes.Equal(want, got) {
+		return ""
+	}
+	a, b := lines(want), lines(got)
+	// Trim the common prefix and suffix, which for golden files is most
+	// of the input, before the quadratic matching.
+	p := 0
+	for p < len(a) && p < len(b) && a[p] == b[p] {
+		p++
+	}
+	s := 0
+	for s < len(a)-p && s < len(b)-p && a[len(a)-1-s] == b[len(b)-1-s] {
+		s++
+	}
+	a, b = a[p:len(a)-s], b[p:len(b)-s]
+	if len(a) == 0 && len(b) == 0 {
+		// lines drops the final newline, so it is all that differs.
+		return "inputs differ in the newline at the end\n"
+	}
+
+	var out strings.Builder
+	n := 0
+	emit := func(sign byte, line int, text string) {
+		if n < maxDiffLines {
+			fmt.Fprintf(&out, "%c%d: %s\n", sign, p+line+1, text)
+		} else if n == maxDiffLines {
+			out.WriteString("...\n")
+		}
+		n++
+	}
+	if len(a)*len(b) > 1<<24 {
+		// Too large to match, so compare line by line.
+		for i := 0; i < max(len(a), len(b)); i++ {
+			if i < len(a) {
+				emit('-', i, a[i])
+			}
+			if i < len(b) {
+				emit('+', i, b[i])
+			}
+		}
+		return out.String()
+	}
+	// lcs[i][j] is the length of the longest common subsequence of a[i:]
+	// and b[j:].
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			i++
+			j++
+		case j == len(b) || i < len(a) && lcs[i+1][j] >= lcs[i][j+1]:
+			emit('-', i, a[i])
+			i++
+		default:
+			emit('+', j, b[j])
+			j++
+		}
+	}
+	return out.String()
+}
+
+// lines splits the input into lines, without their newlines.
+func lines(b []byte) []string {
+	s := strings.TrimSuffix(string(b), "\n")
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, "\n")
+}
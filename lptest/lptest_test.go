@@ -0,0 +1,98 @@
+package lptest
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/btracey/benchlp"
+)
+
+var cons = []benchlp.Constraint{
+	{Left: []benchlp.Term{{Var: "x", Value: 2}}, Right: []benchlp.Term{{Var: "y", Value: 1}}},
+	{Left: []benchlp.Term{{Var: "y", Value: 1}, {Var: "z", Value: -0.5}}},
+}
+
+// recorder records the failures of a test rather than failing it.
+type recorder struct {
+	testing.TB
+	failures []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...any) {
+	r.failures = append(r.failures, fmt.Sprintf(format, args...))
+}
+
+func (r *recorder) Fatalf(format string, args ...any) {
+	r.Errorf(format, args...)
+}
+
+func TestGolden(t *testing.T) {
+	opts := &benchlp.Options{Precision: -1}
+	Golden(t, "testdata/small.lp", cons, opts)
+	Golden(t, "testdata/small.mps", cons, opts)
+
+	// The failures below must be reported even when updating.
+	defer func(update bool) { *Update = update }(*Update)
+	*Update = false
+	changed := append([]benchlp.Constraint(nil), cons...)
+	changed[1].Left = []benchlp.Term{{Var: "y", Value: 1}}
+	r := &recorder{TB: t}
+	Golden(r, "testdata/small.lp", changed, opts)
+	if len(r.failures) != 1 || !strings.Contains(r.failures[0], "-2: 1 y + -0.5 z <= 0\n+2: 1 y <= 0\n") {
+		t.Errorf("got failures %q", r.failures)
+	}
+
+	// A golden file without the final newline is not the output.
+	var buf bytes.Buffer
+	if err := benchlp.WriteConstraintsTo(&buf, cons, opts); err != nil {
+		t.Fatal(err)
+	}
+	trimmed := filepath.Join(t.TempDir(), "trimmed.lp")
+	if err := os.WriteFile(trimmed, bytes.TrimSuffix(buf.Bytes(), []byte("\n")), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	r = &recorder{TB: t}
+	Golden(r, trimmed, cons, opts)
+	if len(r.failures) != 1 || !strings.Contains(r.failures[0], "newline at the end") {
+		t.Errorf("trimmed file: got failures %q", r.failures)
+	}
+
+	r = &recorder{TB: t}
+	Golden(r, filepath.Join(t.TempDir(), "missing.lp"), cons, opts)
+	if len(r.failures) != 1 || !strings.Contains(r.failures[0], "-lptest.update") {
+		t.Errorf("missing file: got failures %q", r.failures)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	for _, test := range []struct {
+		want, got, diff string
+	}{
+		{"a\nb\n", "a\nb\n", ""},
+		{"a\nb\nc\n", "a\nc\n", "-2: b\n"},
+		{"a\nc\n", "a\nb\nc\n", "+2: b\n"},
+		{"a\nb\nc\n", "a\nx\nc\n", "-2: b\n+2: x\n"},
+		{"", "a\n", "+1: a\n"},
+		{"a\nb\n", "a\nb", "inputs differ in the newline at the end\n"},
+		{"", "\n", "inputs differ in the newline at the end\n"},
+	} {
+		if got := Diff([]byte(test.want), []byte(test.got)); got != test.diff {
+			t.Errorf("Diff(%q, %q) = %q, want %q", test.want, test.got, got, test.diff)
+		}
+	}
+
+	var want, got strings.Builder
+	for i := 0; i < 100; i++ {
+		fmt.Fprintf(&want, "%d\n", i)
+		fmt.Fprintf(&got, "%d\n", i+1)
+	}
+	if d := Diff([]byte(want.String()), []byte(got.String())); d != "-1: 0\n+100: 100\n" {
+		t.Errorf("shifted lines: got %q", d)
+	}
+}
@@ -0,0 +1,15 @@
+NAME
+ROWS
+ N  OBJ
+ L  c0
+ L  c1
+COLUMNS
+    x  c0  2
+    y  c0  -1
+    y  c1  1
+    z  c1  -0.5
+BOUNDS
+ FR BND x
+ FR BND y
+ FR BND z
+ENDATA
@@ -0,0 +1,83 @@
+/*
+Copyright 2017 Brendan Tracey
+
+Redistribution and use in source and binary forms, with or without modification,
+are permitted provided that the following conditions are met:
+
+1. Redistributions of source code must retain the above copyright notice, this
+list of conditions and the following disclaimer.
+
+2. Redistributions in binary form must reproduce the above copyright notice,
+this list of conditions and the following disclaimer in the documentation and/or
+other materials provided with the distribution.
+
+3. Neither the name of the copyright holder nor the names of its contributors may
+be used to endorse or promote products derived from this software without specific
+prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED.
+IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT,
+INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF
+LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE
+OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED
+OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package benchlp
+
+import (
+	"io"
+	"sort"
+	"strconv"
+)
+
+// The writers below produce the companion files of MIP warm-start workflows.
+// This package has no integrality, so the variables must be declared integer
+// to the solver separately, as for Implies.
+
+// WriteMIPStart writes a MIP start, a possibly partial solution keyed by
+// variable name, to w in the MST format read by Gurobi, with a "name value"
+// line per variable in order of name. Values are written exactly, in the
+// shortest form that parses back to the same value. ReadSolution reads the
+// file back.
+func WriteMIPStart(w io.Writer, values map[string]float64) error {
+	b := []byte("# MIP start\n")
+	for _, name := range sortedKeys(values) {
+		b = append(b, name...)
+		b = append(b, ' ')
+		b = strconv.AppendFloat(b, values[name], 'g', -1, 64)
+		b = append(b, '\n')
+	}
+	_, err := w.Write(b)
+	return err
+}
+
+// WriteBranchPriorities writes branching priorities keyed by variable name to
+// w in the ORD format read by Gurobi, with a "name priority" line per
+// variable in order of name. Variables with higher priorities are branched
+// on first, and those not listed have priority zero.
+func WriteBranchPriorities(w io.Writer, priorities map[string]int) error {
+	b := []byte("# branching priorities\n")
+	for _, name := range sortedKeys(priorities) {
+		b = append(b, name...)
+		b = append(b, ' ')
+		b = strconv.AppendInt(b, int64(priorities[name]), 10)
+		b = append(b, '\n')
+	}
+	_, err := w.Write(b)
+	return err
+}
+
+// sortedKeys returns the keys of m in increasing order.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
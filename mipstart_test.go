@@ -0,0 +1,37 @@
+package benchlp
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestWriteMIPStart(t *testing.T) {
+	values := map[string]float64{"y": 1, "x": 0.1, "z": -3}
+	var buf bytes.Buffer
+	if err := WriteMIPStart(&buf, values); err != nil {
+		t.Fatal(err)
+	}
+	want := "# MIP start\nx 0.1\ny 1\nz -3\n"
+	if buf.String() != want {
+		t.Errorf("got\n%s\nwant\n%s", buf.String(), want)
+	}
+	sol, err := ReadSolution(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(sol.Values, values) {
+		t.Errorf("read back %v, want %v", sol.Values, values)
+	}
+}
+
+func TestWriteBranchPriorities(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteBranchPriorities(&buf, map[string]int{"b": 2, "a": 10, "c": -1}); err != nil {
+		t.Fatal(err)
+	}
+	want := "# branching priorities\na 10\nb 2\nc -1\n"
+	if buf.String() != want {
+		t.Errorf("got\n%s\nwant\n%s", buf.String(), want)
+	}
+}
@@ -0,0 +1,117 @@
+//go:build linux
+
+/*
+Copyright 2017 Brendan Tracey
+
+Redistribution and use in source and binary forms, with or without modification,
+are permitted provided that the following conditions are met:
+
+1. Redistributions of source code must retain the above copyright notice, this
+list of conditions and the following disclaimer.
+
+2. Redistributions in binary form must reproduce the above copyright notice,
+this list of conditions and the following disclaimer in the documentation and/or
+other materials provided with the distribution.
+
+3. Neither the name of the copyright holder nor the names of its contributors may
+be used to endorse or promote products derived from this software without specific
+prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED.
+IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT,
+INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF
+LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE
+OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED
+OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package benchlp
+
+import (
+	"os"
+	"syscall"
+)
+
+// MappedFile is an output file written through a shared memory mapping
+// rather than with write calls, so the output is copied once, into the
+// mapped pages of the page cache. The file is extended, and the mapping
+// grown, as needed. Elsewhere than on Linux, a MappedFile writes to the
+// file normally.
+type MappedFile struct {
+	f    *os.File
+	data []byte // the mapping, covering the whole file
+	n    int    // bytes written
+}
+
+// CreateMapped creates the named file for writing through a memory mapping,
+// truncating it if it exists. The file is first extended to size bytes,
+// which should be an estimate of the output size, and is truncated to the
+// size of the output on Close.
+func CreateMapped(name string, size int64) (*MappedFile, error) {
+	f, err := os.Create(name)
+	if err != nil {
+		return nil, err
+	}
+	m := &MappedFile{f: f}
+	if err := m.grow(int(max(size, 1))); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return m, nil
+}
+
+// grow extends the file and the mapping to at least size bytes, rounded up
+// to a whole number of pages.
+func (m *MappedFile) grow(size int) error {
+	page := os.Getpagesize()
+	size = (size + page - 1) / page * page
+	if m.data != nil {
+		if err := syscall.Munmap(m.data); err != nil {
+			return err
+		}
+		m.data = nil
+	}
+	fd := int(m.f.Fd())
+	// Allocate the blocks now, so that a full disk is an error here rather
+	// than a SIGBUS when the pages are written. Filesystems without
+	// fallocate get a sparse file.
+	if err := syscall.Fallocate(fd, 0, 0, int64(size)); err != nil {
+		if err := m.f.Truncate(int64(size)); err != nil {
+			return err
+		}
+	}
+	data, err := syscall.Mmap(fd, 0, size, syscall.PROT_READ|syscall.PROT_WRITE, syscall.MAP_SHARED)
+	if err != nil {
+		return err
+	}
+	m.data = data
+	return nil
+}
+
+// Write copies b into the mapping, growing the file if it is full.
+func (m *MappedFile) Write(b []byte) (int, error) {
+	if m.n+len(b) > len(m.data) {
+		if err := m.grow(max(2*len(m.data), m.n+len(b))); err != nil {
+			return 0, err
+		}
+	}
+	m.n += copy(m.data[m.n:], b)
+	return len(b), nil
+}
+
+// Close unmaps the file, truncates it to the bytes written, and closes it.
+func (m *MappedFile) Close() error {
+	err := syscall.Munmap(m.data)
+	m.data = nil
+	if terr := m.f.Truncate(int64(m.n)); err == nil {
+		err = terr
+	}
+	if cerr := m.f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
@@ -0,0 +1,60 @@
+package benchlp
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMappedFile(t *testing.T) {
+	cons := randomConstraints(100, 10000)
+	var want bytes.Buffer
+	if err := WriteConstraintsTo(&want, cons, nil); err != nil {
+		t.Fatal(err)
+	}
+	// Start small so the file is grown several times.
+	name := filepath.Join(t.TempDir(), "out.lp")
+	m, err := CreateMapped(name, 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteConstraintsTo(m, cons, &Options{BufferSize: 4096}); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Close(); err != nil {
+		t.Fatal(err)
+	}
+	got, err := os.ReadFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want.Bytes()) {
+		t.Errorf("mapped file has %d bytes, want %d, or differs", len(got), want.Len())
+	}
+}
+
+// BenchmarkWriteMapped compares writing through a MappedFile, sized in
+// advance, with the buffered writes of BenchmarkWriteFile.
+func BenchmarkWriteMapped(b *testing.B) {
+	cons := randomSparseConstraints(*benchVars, *benchCons, *benchDensity)
+	var out bytes.Buffer
+	if err := WriteConstraintsTo(&out, cons, nil); err != nil {
+		b.Fatal(err)
+	}
+	size := int64(out.Len())
+	name := filepath.Join(b.TempDir(), "out.lp")
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		m, err := CreateMapped(name, size)
+		if err != nil {
+			b.Fatal(err)
+		}
+		if err := WriteConstraintsTo(m, cons, nil); err != nil {
+			b.Fatal(err)
+		}
+		if err := m.Close(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,409 @@
+/*
+Copyright 2017 Brendan Tracey
+
+Redistribution and use in source and binary forms, with or without modification,
+are permitted provided that the following conditions are met:
+
+1. Redistributions of source code must retain the above copyright notice, this
+list of conditions and the following disclaimer.
+
+2. Redistributions in binary form must reproduce the above copyright notice,
+this list of conditions and the following disclaimer in the documentation and/or
+other materials provided with the distribution.
+
+3. Neither the name of the copyright holder nor the names of its contributors may
+be used to endorse or promote products derived from this software without specific
+prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED.
+IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT,
+INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF
+LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE
+OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED
+OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package benchlp
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"math"
+	"os"
+	"slices"
+	"strconv"
+)
+
+// mpsBlock is the most coefficients of a block of columns that WriteMPS
+// holds in memory when it transposes through a temporary file. It is a
+// variable for testing.
+var mpsBlock = 1 << 20
+
+// mpsEntrySize is the size of a coefficient in the temporary file: its
+// column, row, and value.
+const mpsEntrySize = 24
+
+// WriteMPS writes the constraints to w in free MPS format. Constraint i is
+// the row ci, of type L, and every variable other than One is free. One is
+// not written as a variable: its coefficients are the constants of the
+// constraints, and are written negated, as right hand sides, in the RHS
+// section. The objective row OBJ is empty. A variable whose coefficients all
+// cancel is given an explicit zero in OBJ so that it is still declared.
+// Variable names must not contain spaces; names sanitized with MPSNames, as
+// by setting opts.Names, are also legal in fixed MPS.
+//
+// Variables are written in the order given by opts.Order. Coefficients are
+// formatted according to opts, coefficients smaller than opts.DropTolerance
+// are dropped, and the output is buffered and compressed as for
+// WriteConstraintsTo. A nil opts is equivalent to the zero value.
+//
+// The COLUMNS section lists the coefficients column by column, so they have
+// to be transposed. By default the transpose is held in memory, as large as
+// the condensed constraints. If opts.TempDir is set, the coefficients are
+// instead written to a temporary file in that directory, grouped into
+// blocks of columns, and read back a block at a time, so only the counts of
+// each column, the constants, and a block of about a million coefficients
+// are held in memory. The file is removed before WriteMPS returns.
+func WriteMPS(w io.Writer, cons []Constraint, opts *Options) (err error) {
+	if opts == nil {
+		opts = &Options{}
+	}
+	defer opts.recoverWrite(&err, cons)
+	if err := opts.Limits.check(cons); err != nil {
+		return err
+	}
+	bw, err := opts.output(w)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cerr := bw.Close(); err == nil {
+			err = cerr
+		}
+	}()
+
+	var names []string
+	var nameMap map[string]int
+	var m SparseMatrix
+	if opts.TempDir == "" {
+		names, m = CSR(cons)
+		if opts.DropTolerance > 0 {
+			opts.dropSparse(&m)
+		}
+		if opts.Order != FirstAppearance {
+			sortColumns(names, &m, opts.Order)
+		}
+		m = csrToCSC(m)
+	} else {
+		names, nameMap = IndexVariables(cons)
+		SortVariables(names, nameMap, opts.Order)
+	}
+	mw := &mpsWriter{w: bw, opts: opts, one: slices.Index(names, One)}
+	if names, err = opts.writeNames(bw, "* ", names); err != nil {
+		return err
+	}
+
+	if err := mw.rows(len(cons)); err != nil {
+		return err
+	}
+	if opts.TempDir == "" {
+		for j, name := range names {
+			lo, hi := m.Ptr[j], m.Ptr[j+1]
+			if err := mw.column(j, name, m.Index[lo:hi], m.Value[lo:hi]); err != nil {
+				return err
+			}
+		}
+	} else if err := mw.spilled(cons, names, nameMap); err != nil {
+		return err
+	}
+
+	return mw.bounds(names)
+}
+
+// dropSparse removes the coefficients of the CSR matrix m dropped by the
+// DropTolerance option, recording the number dropped from each row.
+func (o *Options) dropSparse(m *SparseMatrix) {
+	k := 0
+	for i := 0; i < m.Rows; i++ {
+		lo, hi := m.Ptr[i], m.Ptr[i+1]
+		m.Ptr[i] = k
+		for p := lo; p < hi; p++ {
+			if !o.drops(m.Value[p]) {
+				m.Index[k], m.Value[k] = m.Index[p], m.Value[p]
+				k++
+			}
+		}
+		o.recordDropped(i, hi-lo-(k-m.Ptr[i]))
+	}
+	m.Ptr[m.Rows] = k
+	m.Index, m.Value = m.Index[:k], m.Value[:k]
+}
+
+// mpsWriter batches the lines of an MPS file. one is the index of One among
+// the variables, or -1, and its column is kept for the RHS section.
+type mpsWriter struct {
+	w    io.Writer
+	opts *Options
+	b    []byte
+
+	one       int
+	rhsRows   []int
+	rhsValues []float64
+}
+
+// write writes the batched lines once they have grown past a few KiB, or
+// whenever force is true.
+func (mw *mpsWriter) write(force bool) error {
+	if !force && len(mw.b) < 4096 {
+		return nil
+	}
+	_, err := mw.w.Write(mw.b)
+	mw.b = mw.b[:0]
+	return err
+}
+
+// rows writes the header and the ROWS section for n constraints, and starts
+// the COLUMNS section.
+func (mw *mpsWriter) rows(n int) error {
+	mw.b = append(mw.b, "NAME\nROWS\n N  OBJ\n"...)
+	for i := 0; i < n; i++ {
+		mw.b = append(mw.b, " L  c"...)
+		mw.b = strconv.AppendInt(mw.b, int64(i), 10)
+		mw.b = append(mw.b, '\n')
+		if err := mw.write(false); err != nil {
+			return err
+		}
+	}
+	mw.b = append(mw.b, "COLUMNS\n"...)
+	return nil
+}
+
+// bounds writes the RHS section from the column of One, if it has any
+// coefficients, and the BOUNDS section, making each other variable free, and
+// ends the file.
+func (mw *mpsWriter) bounds(names []string) error {
+	if len(mw.rhsRows) > 0 {
+		mw.b = append(mw.b, "RHS\n"...)
+		for k, i := range mw.rhsRows {
+			mw.b = append(mw.b, "    RHS  c"...)
+			mw.b = strconv.AppendInt(mw.b, int64(i), 10)
+			mw.b = append(mw.b, "  "...)
+			mw.b = mw.opts.appendFloat(mw.b, -mw.rhsValues[k])
+			mw.b = append(mw.b, '\n')
+			if err := mw.write(false); err != nil {
+				return err
+			}
+		}
+	}
+	mw.b = append(mw.b, "BOUNDS\n"...)
+	for j, name := range names {
+		if j == mw.one {
+			continue
+		}
+		mw.b = append(mw.b, " FR BND "...)
+		mw.b = append(mw.b, name...)
+		mw.b = append(mw.b, '\n')
+		if err := mw.write(false); err != nil {
+			return err
+		}
+	}
+	mw.b = append(mw.b, "ENDATA\n"...)
+	return mw.write(true)
+}
+
+// column writes the coefficients of column j, with rows in increasing order.
+// The coefficients of One are kept for the RHS section instead.
+func (mw *mpsWriter) column(j int, name string, rows []int, values []float64) error {
+	if j == mw.one {
+		mw.rhsRows = append(mw.rhsRows, rows...)
+		mw.rhsValues = append(mw.rhsValues, values...)
+		return nil
+	}
+	if len(rows) == 0 {
+		mw.entry(name, -1, 0)
+	}
+	for k, i := range rows {
+		mw.entry(name, i, values[k])
+		if err := mw.write(false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// entry appends the COLUMNS line for a coefficient in row i, or in the
+// objective row if i is negative.
+func (mw *mpsWriter) entry(name string, i int, v float64) {
+	mw.b = append(mw.b, "    "...)
+	mw.b = append(mw.b, name...)
+	if i < 0 {
+		mw.b = append(mw.b, "  OBJ  "...)
+	} else {
+		mw.b = append(mw.b, "  c"...)
+		mw.b = strconv.AppendInt(mw.b, int64(i), 10)
+		mw.b = append(mw.b, "  "...)
+	}
+	mw.b = mw.opts.appendFloat(mw.b, v)
+	mw.b = append(mw.b, '\n')
+}
+
+// spilled writes the COLUMNS section by transposing the coefficients
+// through a temporary file. The first pass over the constraints counts the
+// coefficients of each column, which fixes where each block of columns
+// starts in the file. The second scatters the coefficients to their blocks,
+// in row order, and each block is then read back and sorted by column.
+func (mw *mpsWriter) spilled(cons []Constraint, names []string, nameMap map[string]int) (err error) {
+	// The coefficients are condensed as for CSR, so the output is the same
+	// as when the transpose is held in memory.
+	wl := make([]float64, len(names))
+	wr := make([]float64, len(names))
+	count := make([]int, len(names))
+	for i, c := range cons {
+		var dropped int
+		sparseCondense(wl, wr, c, nameMap, func(j int, v float64) {
+			if mw.opts.drops(v) {
+				dropped++
+				return
+			}
+			count[j]++
+		})
+		mw.opts.recordDropped(i, dropped)
+	}
+
+	// Block k holds columns start[k] to start[k+1]-1, and its coefficients
+	// are entries off[k] to off[k+1]-1 of the file.
+	blockOf := make([]int, len(names))
+	start := []int{0}
+	off := []int64{0}
+	var n int
+	for j, cnt := range count {
+		if n > 0 && n+cnt > mpsBlock {
+			start = append(start, j)
+			off = append(off, off[len(off)-1]+int64(n))
+			n = 0
+		}
+		blockOf[j] = len(start) - 1
+		n += cnt
+	}
+	start = append(start, len(names))
+	off = append(off, off[len(off)-1]+int64(n))
+	nBlocks := len(start) - 1
+
+	f, err := os.CreateTemp(mw.opts.TempDir, "benchlp-mps-")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		cerr := f.Close()
+		if rerr := os.Remove(f.Name()); cerr == nil {
+			cerr = rerr
+		}
+		if err == nil {
+			err = cerr
+		}
+	}()
+
+	// Each block has a small write buffer, flushed to the block's next
+	// position in the file when it fills.
+	const bufSize = 8 << 10
+	bufs := make([][]byte, nBlocks)
+	pos := make([]int64, nBlocks)
+	for k := range pos {
+		pos[k] = off[k] * mpsEntrySize
+	}
+	flush := func(k int) error {
+		_, err := f.WriteAt(bufs[k], pos[k])
+		pos[k] += int64(len(bufs[k]))
+		bufs[k] = bufs[k][:0]
+		return err
+	}
+	var ferr error
+	for i, c := range cons {
+		sparseCondense(wl, wr, c, nameMap, func(j int, v float64) {
+			if mw.opts.drops(v) {
+				return
+			}
+			k := blockOf[j]
+			bufs[k] = binary.LittleEndian.AppendUint64(bufs[k], uint64(j))
+			bufs[k] = binary.LittleEndian.AppendUint64(bufs[k], uint64(i))
+			bufs[k] = binary.LittleEndian.AppendUint64(bufs[k], math.Float64bits(v))
+			if len(bufs[k]) >= bufSize && ferr == nil {
+				ferr = flush(k)
+			}
+		})
+		if ferr != nil {
+			return ferr
+		}
+	}
+	for k := range bufs {
+		if err := flush(k); err != nil {
+			return err
+		}
+		bufs[k] = nil
+	}
+
+	var rows []int
+	var values []float64
+	var e [mpsEntrySize]byte
+	for k := 0; k < nBlocks; k++ {
+		lo, hi := start[k], start[k+1]
+		nEntries := off[k+1] - off[k]
+		r := bufio.NewReader(io.NewSectionReader(f, off[k]*mpsEntrySize, nEntries*mpsEntrySize))
+		if hi-lo == 1 {
+			// A single column, possibly larger than a block, is already
+			// in row order and is written as it is read.
+			if nEntries == 0 && lo != mw.one {
+				mw.entry(names[lo], -1, 0)
+			}
+			for ; nEntries > 0; nEntries-- {
+				if _, err := io.ReadFull(r, e[:]); err != nil {
+					return err
+				}
+				i, v := int(binary.LittleEndian.Uint64(e[8:])), math.Float64frombits(binary.LittleEndian.Uint64(e[16:]))
+				if lo == mw.one {
+					mw.rhsRows = append(mw.rhsRows, i)
+					mw.rhsValues = append(mw.rhsValues, v)
+					continue
+				}
+				mw.entry(names[lo], i, v)
+				if err := mw.write(false); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		// Sort the block by column, keeping the row order within each.
+		next := make([]int, hi-lo+1)
+		for j := lo; j < hi; j++ {
+			next[j-lo+1] = next[j-lo] + count[j]
+		}
+		if int64(cap(rows)) < nEntries {
+			rows = make([]int, nEntries)
+			values = make([]float64, nEntries)
+		}
+		rows, values = rows[:nEntries], values[:nEntries]
+		for ; nEntries > 0; nEntries-- {
+			if _, err := io.ReadFull(r, e[:]); err != nil {
+				return err
+			}
+			l := int(binary.LittleEndian.Uint64(e[:])) - lo
+			rows[next[l]] = int(binary.LittleEndian.Uint64(e[8:]))
+			values[next[l]] = math.Float64frombits(binary.LittleEndian.Uint64(e[16:]))
+			next[l]++
+		}
+		var p int
+		for j := lo; j < hi; j++ {
+			if err := mw.column(j, names[j], rows[p:p+count[j]], values[p:p+count[j]]); err != nil {
+				return err
+			}
+			p += count[j]
+		}
+	}
+	return nil
+}
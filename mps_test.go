@@ -0,0 +1,152 @@
+package benchlp
+
+import (
+	"bytes"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestWriteMPS(t *testing.T) {
+	cons := []Constraint{
+		{Left: []Term{{"b", 2}, {"a", 1}}, Right: []Term{{"c", 1}}},
+		{Left: []Term{{"a", -1}, {"c", 1}, {"c", -1}}},
+	}
+	want := "NAME\nROWS\n N  OBJ\n L  c0\n L  c1\n" +
+		"COLUMNS\n" +
+		"    a  c0  1\n" +
+		"    a  c1  -1\n" +
+		"    b  c0  2\n" +
+		"    c  c0  -1\n" +
+		"BOUNDS\n FR BND a\n FR BND b\n FR BND c\nENDATA\n"
+	for _, dir := range []string{"", t.TempDir()} {
+		var buf bytes.Buffer
+		if err := WriteMPS(&buf, cons, &Options{Order: Lexicographic, TempDir: dir}); err != nil {
+			t.Fatal(err)
+		}
+		if buf.String() != want {
+			t.Errorf("TempDir %q: got\n%s\nwant\n%s", dir, buf.String(), want)
+		}
+	}
+
+	// A column whose coefficients cancel is declared with a zero objective.
+	var buf bytes.Buffer
+	cons = []Constraint{{Left: []Term{{"a", 1}, {"b", 1}}, Right: []Term{{"b", 1}}}}
+	if err := WriteMPS(&buf, cons, nil); err != nil {
+		t.Fatal(err)
+	}
+	if want := "    b  OBJ  0\n"; !bytes.Contains(buf.Bytes(), []byte(want)) {
+		t.Errorf("missing %q in\n%s", want, buf.String())
+	}
+}
+
+func TestWriteMPSTempFile(t *testing.T) {
+	defer func(n int) { mpsBlock = n }(mpsBlock)
+	mpsBlock = 50
+
+	cons := randomSparseConstraints(40, 200, 0.1)
+	// Make one column larger than a block.
+	for i := range cons {
+		cons[i].Left = append(cons[i].Left, Term{"big", float64(i + 1)})
+	}
+	var want bytes.Buffer
+	if err := WriteMPS(&want, cons, nil); err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	var got bytes.Buffer
+	if err := WriteMPS(&got, cons, &Options{TempDir: dir}); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got.Bytes(), want.Bytes()) {
+		t.Errorf("output through a temporary file differs")
+	}
+	if files, err := os.ReadDir(dir); err != nil || len(files) != 0 {
+		t.Errorf("temporary files left: %v %v", files, err)
+	}
+
+	// Terms that cancel exactly on being summed by side must cancel in
+	// both paths.
+	x, y := 0.1, 0.2
+	cons = []Constraint{{Left: []Term{{"a", x + y}, {"b", 1}}, Right: []Term{{"a", x}, {"a", y}}}}
+	want.Reset()
+	if err := WriteMPS(&want, cons, nil); err != nil {
+		t.Fatal(err)
+	}
+	got.Reset()
+	if err := WriteMPS(&got, cons, &Options{TempDir: dir}); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got.Bytes(), want.Bytes()) {
+		t.Errorf("cancelling terms: output through a temporary file differs:\n%s\nin memory:\n%s", got.Bytes(), want.Bytes())
+	}
+	if line := "    a  OBJ  0\n"; !bytes.Contains(want.Bytes(), []byte(line)) {
+		t.Errorf("cancelling terms: missing %q in\n%s", line, want.Bytes())
+	}
+}
+
+func TestWriteMPSConstants(t *testing.T) {
+	defer func(n int) { mpsBlock = n }(mpsBlock)
+	cons := []Constraint{
+		{Left: []Term{{"x", 1}}, Right: []Term{{One, 3}}},           // x <= 3
+		{Left: []Term{{One, 2}, {"y", 1}}, Right: []Term{{"x", 1}}}, // y - x <= -2
+		{Left: []Term{{"y", 1}, {One, 1}}, Right: []Term{{One, 1}}}, // y <= 0
+	}
+	want := "NAME\nROWS\n N  OBJ\n L  c0\n L  c1\n L  c2\n" +
+		"COLUMNS\n" +
+		"    x  c0  1\n" +
+		"    x  c1  -1\n" +
+		"    y  c1  1\n" +
+		"    y  c2  1\n" +
+		"RHS\n" +
+		"    RHS  c0  3\n" +
+		"    RHS  c1  -2\n" +
+		"BOUNDS\n FR BND x\n FR BND y\nENDATA\n"
+	for _, block := range []int{1, 1 << 20} {
+		mpsBlock = block
+		for _, dir := range []string{"", t.TempDir()} {
+			var buf bytes.Buffer
+			if err := WriteMPS(&buf, cons, &Options{TempDir: dir}); err != nil {
+				t.Fatal(err)
+			}
+			if buf.String() != want {
+				t.Errorf("block %d, TempDir %q: got\n%s\nwant\n%s", block, dir, buf.String(), want)
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := EncodeConstraints(cons, FirstAppearance, NewMPSEncoder(&buf, nil)); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != want {
+		t.Errorf("encoder: got\n%s\nwant\n%s", buf.String(), want)
+	}
+}
+
+func TestWriteMPSDropTolerance(t *testing.T) {
+	cons := []Constraint{
+		{Left: []Term{{"x", 1}, {"y", 1e-12}}, Right: []Term{{One, 1}}},
+		{Left: []Term{{"y", 2}}, Right: []Term{{"x", 1e-12}}},
+	}
+	want := "NAME\nROWS\n N  OBJ\n L  c0\n L  c1\n" +
+		"COLUMNS\n" +
+		"    x  c0  1\n" +
+		"    y  c1  2\n" +
+		"RHS\n" +
+		"    RHS  c0  1\n" +
+		"BOUNDS\n FR BND x\n FR BND y\nENDATA\n"
+	for _, dir := range []string{"", t.TempDir()} {
+		rep := &Report{}
+		var buf bytes.Buffer
+		if err := WriteMPS(&buf, cons, &Options{TempDir: dir, DropTolerance: 1e-9, Report: rep}); err != nil {
+			t.Fatal(err)
+		}
+		if buf.String() != want {
+			t.Errorf("TempDir %q: got\n%s\nwant\n%s", dir, buf.String(), want)
+		}
+		if !reflect.DeepEqual(rep.Dropped, map[int]int{0: 1, 1: 1}) {
+			t.Errorf("TempDir %q: dropped %v, want one from each row", dir, rep.Dropped)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+/*
+Copyright 2017 Brendan Tracey
+
+Redistribution and use in source and binary forms, with or without modification,
+are permitted provided that the following conditions are met:
+
+1. Redistributions of source code must retain the above copyright notice, this
+list of conditions and the following disclaimer.
+
+2. Redistributions in binary form must reproduce the above copyright notice,
+this list of conditions and the following disclaimer in the documentation and/or
+other materials provided with the distribution.
+
+3. Neither the name of the copyright holder nor the names of its contributors may
+be used to endorse or promote products derived from this software without specific
+prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED.
+IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT,
+INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF
+LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE
+OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED
+OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package benchlp
+
+import "io"
+
+// WriteMatrixMarket writes the condensed coefficient matrix of the
+// constraints to w in the Matrix Market coordinate format. Row i is
+// constraint i and column j is variable j, ordered according to opts.Order,
+// both one-based as the format requires. Coefficients are formatted according
+// to opts, and the output is buffered and compressed as for
+// WriteConstraintsTo. A nil opts is equivalent to the zero value.
+//
+// The right hand side of every constraint is zero and variables are
+// unbounded, so no separate right hand side or bound vectors are written.
+func WriteMatrixMarket(w io.Writer, cons []Constraint, opts *Options) (err error) {
+	if opts == nil {
+		opts = &Options{}
+	}
+	defer opts.recoverWrite(&err, cons)
+	if err := opts.Limits.check(cons); err != nil {
+		return err
+	}
+	return EncodeConstraints(cons, opts.Order, NewMatrixMarketEncoder(w, opts))
+}
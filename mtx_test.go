@@ -0,0 +1,42 @@
+package benchlp
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestWriteMatrixMarket(t *testing.T) {
+	cons := []Constraint{
+		{Left: []Term{{"b", 1}, {"a", 2}}, Right: []Term{{"b", 1}}},
+		{},
+		{Left: []Term{{"c", 0.5}}, Right: []Term{{"a", 3}}},
+	}
+	var buf bytes.Buffer
+	if err := WriteMatrixMarket(&buf, cons, &Options{Order: Lexicographic}); err != nil {
+		t.Fatal(err)
+	}
+	want := `%%MatrixMarket matrix coordinate real general
+3 3 3
+1 1 2
+3 1 -3
+3 3 0.5
+`
+	if buf.String() != want {
+		t.Errorf("got\n%s\nwant\n%s", buf.String(), want)
+	}
+}
+
+func TestWriteMatrixMarketCancelling(t *testing.T) {
+	// The size line counts the entries that are written, after the terms of
+	// a cancel.
+	x, y := 0.1, 0.2
+	cons := []Constraint{{Left: []Term{{"a", x + y}, {"b", 1}}, Right: []Term{{"a", x}, {"a", y}}}}
+	var buf bytes.Buffer
+	if err := WriteMatrixMarket(&buf, cons, nil); err != nil {
+		t.Fatal(err)
+	}
+	want := "%%MatrixMarket matrix coordinate real general\n1 2 1\n1 2 1\n"
+	if buf.String() != want {
+		t.Errorf("got\n%s\nwant\n%s", buf.String(), want)
+	}
+}
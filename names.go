@@ -0,0 +1,169 @@
+/*
+Copyright 2017 Brendan Tracey
+
+Redistribution and use in source and binary forms, with or without modification,
+are permitted provided that the following conditions are met:
+
+1. Redistributions of source code must retain the above copyright notice, this
+list of conditions and the following disclaimer.
+
+2. Redistributions in binary form must reproduce the above copyright notice,
+this list of conditions and the following disclaimer in the documentation and/or
+other materials provided with the distribution.
+
+3. Neither the name of the copyright holder nor the names of its contributors may
+be used to endorse or promote products derived from this software without specific
+prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED.
+IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT,
+INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF
+LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE
+OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED
+OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package benchlp
+
+import (
+	"io"
+	"strconv"
+)
+
+// NameRules describes the variable names allowed by an output format.
+type NameRules struct {
+	// MaxLen is the maximum length of a name in bytes. Zero means there is
+	// no limit.
+	MaxLen int
+	// Legal returns whether the byte c may appear at position i of a name.
+	Legal func(c byte, i int) bool
+}
+
+var (
+	// LPNames are the naming rules of the CPLEX LP format.
+	LPNames = NameRules{MaxLen: 255, Legal: legalLP}
+	// MPSNames are the naming rules of the fixed MPS format.
+	MPSNames = NameRules{MaxLen: 8, Legal: legalMPS}
+)
+
+// legalLP returns whether c can appear at position i of an LP name. Names
+// are letters, digits, and a set of punctuation, and may not start with a
+// digit or a period.
+func legalLP(c byte, i int) bool {
+	switch {
+	case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z':
+		return true
+	case '0' <= c && c <= '9', c == '.':
+		return i > 0
+	}
+	switch c {
+	case '!', '"', '#', '$', '%', '&', '(', ')', '/', ',', ';', '?', '@', '_', '`', '\'', '{', '}', '|', '~':
+		return true
+	}
+	return false
+}
+
+// legalMPS returns whether c can appear in a fixed MPS name, which may contain
+// any printable character except a space.
+func legalMPS(c byte, i int) bool {
+	return '!' <= c && c <= '~'
+}
+
+// SanitizeNames returns a legal and unique name for each of the names
+// according to the rules. Names that are already legal are unchanged. Other
+// names have illegal characters replaced with underscores, are prefixed with
+// an underscore if they cannot start with their first character, and are
+// truncated and given a numeric suffix where needed to keep them unique.
+//
+// The names must be unique, as returned by IndexVariables.
+func SanitizeNames(names []string, rules NameRules) []string {
+	sanitized := make([]string, len(names))
+	used := make(map[string]bool, len(names))
+	for i, name := range names {
+		if rules.isLegal(name) {
+			sanitized[i] = name
+			used[name] = true
+		}
+	}
+	for i, name := range names {
+		if sanitized[i] != "" {
+			continue
+		}
+		base := rules.mangle(name)
+		s := base
+		for k := 1; used[s] || (rules.MaxLen > 0 && len(s) > rules.MaxLen); k++ {
+			suffix := "_" + strconv.Itoa(k)
+			if rules.MaxLen > 0 && len(base)+len(suffix) > rules.MaxLen {
+				base = base[:rules.MaxLen-len(suffix)]
+			}
+			s = base + suffix
+		}
+		sanitized[i] = s
+		used[s] = true
+	}
+	return sanitized
+}
+
+// isLegal returns whether the name satisfies the rules.
+func (r NameRules) isLegal(name string) bool {
+	if name == "" || (r.MaxLen > 0 && len(name) > r.MaxLen) {
+		return false
+	}
+	for i := 0; i < len(name); i++ {
+		if !r.Legal(name[i], i) {
+			return false
+		}
+	}
+	return true
+}
+
+// mangle replaces the illegal characters of name, truncating the result to
+// the maximum length.
+func (r NameRules) mangle(name string) string {
+	b := make([]byte, 0, len(name)+1)
+	if name == "" || !r.Legal(name[0], 0) && r.Legal(name[0], 1) {
+		b = append(b, '_')
+	}
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		if !r.Legal(c, len(b)) {
+			c = '_'
+		}
+		b = append(b, c)
+	}
+	if r.MaxLen > 0 && len(b) > r.MaxLen {
+		b = b[:r.MaxLen]
+	}
+	return string(b)
+}
+
+// WriteNameMap writes the mapping from sanitized names back to the original
+// names, one pair per line with the original name quoted. Each line begins
+// with prefix, so the map can be written as comments in the output file
+// (with a prefix of `\ ` for LP or `* ` for MPS) or as a separate file
+// (with an empty prefix). Only the names that were changed are written.
+func WriteNameMap(w io.Writer, prefix string, sanitized, names []string) error {
+	if len(sanitized) != len(names) {
+		panic("lp: slice length mismatch")
+	}
+	var b []byte
+	for i, name := range names {
+		if sanitized[i] == name {
+			continue
+		}
+		b = b[:0]
+		b = append(b, prefix...)
+		b = append(b, sanitized[i]...)
+		b = append(b, ' ')
+		b = strconv.AppendQuote(b, name)
+		b = append(b, '\n')
+		if _, err := w.Write(b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
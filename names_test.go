@@ -0,0 +1,103 @@
+package benchlp
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestSanitizeNames(t *testing.T) {
+	for _, test := range []struct {
+		rules NameRules
+		names []string
+		want  []string
+	}{
+		{
+			rules: LPNames,
+			names: []string{"x1", "1x", "a+b", "a b", "_1x", "", ".5"},
+			want:  []string{"x1", "_1x_1", "a_b", "a_b_1", "_1x", "_", "_.5"},
+		},
+		{
+			rules: MPSNames,
+			names: []string{"short", "verylongname", "verylongnombre", "with space"},
+			want:  []string{"short", "verylong", "verylo_1", "with_spa"},
+		},
+	} {
+		got := SanitizeNames(test.names, test.rules)
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("names %q: got %q, want %q", test.names, got, test.want)
+		}
+	}
+}
+
+func TestWriteNameMap(t *testing.T) {
+	names := []string{"x1", "a b"}
+	sanitized := SanitizeNames(names, LPNames)
+	var buf bytes.Buffer
+	if err := WriteNameMap(&buf, `\ `, sanitized, names); err != nil {
+		t.Fatal(err)
+	}
+	want := "\\ a_b \"a b\"\n"
+	if buf.String() != want {
+		t.Errorf("got %q, want %q", buf.String(), want)
+	}
+}
+
+func TestOptionsNames(t *testing.T) {
+	cons := []Constraint{{
+		Left:  []Term{{"1x", 2}, {"y", 1}},
+		Right: []Term{{"verylongname", 3}},
+	}}
+	for _, test := range []struct {
+		name  string
+		write func(*bytes.Buffer, *Options) error
+		rules NameRules
+		want  string
+	}{
+		{
+			name: "LP",
+			write: func(buf *bytes.Buffer, opts *Options) error {
+				return WriteConstraintsTo(buf, cons, opts)
+			},
+			rules: LPNames,
+			want:  "\\ _1x \"1x\"\n2 _1x + 1 y + -3 verylongname <= 0\n",
+		},
+		{
+			name: "MPS",
+			write: func(buf *bytes.Buffer, opts *Options) error {
+				return WriteMPS(buf, cons, opts)
+			},
+			rules: MPSNames,
+			want: "* verylong \"verylongname\"\nNAME\nROWS\n N  OBJ\n L  c0\nCOLUMNS\n" +
+				"    1x  c0  2\n    y  c0  1\n    verylong  c0  -3\n" +
+				"BOUNDS\n FR BND 1x\n FR BND y\n FR BND verylong\nENDATA\n",
+		},
+		{
+			name: "AMPL",
+			write: func(buf *bytes.Buffer, opts *Options) error {
+				return WriteAMPL(buf, cons, opts)
+			},
+			rules: LPNames,
+			want:  "# _1x \"1x\"\nvar _1x;\nvar y;\nvar verylongname;\n\nsubject to c0: 2*_1x + 1*y - 3*verylongname <= 0;\n",
+		},
+	} {
+		for _, tempDir := range []string{"", t.TempDir()} {
+			var buf bytes.Buffer
+			if err := test.write(&buf, &Options{Names: test.rules, TempDir: tempDir}); err != nil {
+				t.Fatal(err)
+			}
+			if buf.String() != test.want {
+				t.Errorf("%s: got\n%s\nwant\n%s", test.name, buf.String(), test.want)
+			}
+		}
+	}
+
+	// Without Names, the names are written verbatim.
+	var buf bytes.Buffer
+	if err := WriteConstraintsTo(&buf, cons, nil); err != nil {
+		t.Fatal(err)
+	}
+	if want := "2 1x + 1 y + -3 verylongname <= 0\n"; buf.String() != want {
+		t.Errorf("no rules: got %q, want %q", buf.String(), want)
+	}
+}
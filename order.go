@@ -0,0 +1,132 @@
+/*
+Copyright 2017 Brendan Tracey
+
+Redistribution and use in source and binary forms, with or without modification,
+are permitted provided that the following conditions are met:
+
+1. Redistributions of source code must retain the above copyright notice, this
+list of conditions and the following disclaimer.
+
+2. Redistributions in binary form must reproduce the above copyright notice,
+this list of conditions and the following disclaimer in the documentation and/or
+other materials provided with the distribution.
+
+3. Neither the name of the copyright holder nor the names of its contributors may
+be used to endorse or promote products derived from this software without specific
+prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED.
+IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT,
+INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF
+LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE
+OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED
+OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package benchlp
+
+import "sort"
+
+// VarOrder specifies the order of the variables in the output.
+type VarOrder int
+
+const (
+	// FirstAppearance orders the variables by their first appearance in
+	// the constraints, as returned by IndexVariables.
+	FirstAppearance VarOrder = iota
+	// Lexicographic orders the variables by byte-wise comparison of their
+	// names.
+	Lexicographic
+	// Natural orders the variables by comparing runs of digits in their
+	// names numerically, so that "v2" comes before "v10".
+	Natural
+)
+
+// TermOrder specifies the order of the terms within each written constraint.
+type TermOrder int
+
+const (
+	// IndexOrder writes the terms in the order of the variable indices, as
+	// given by VarOrder.
+	IndexOrder TermOrder = iota
+	// NameOrder writes the terms sorted by byte-wise comparison of the
+	// variable names.
+	NameOrder
+	// MagnitudeOrder writes the terms by decreasing magnitude of their
+	// coefficients, with ties in index order.
+	MagnitudeOrder
+)
+
+// SortVariables reorders names according to the order and updates nameMap to
+// match. The names and map must be as returned by IndexVariables.
+func SortVariables(names []string, nameMap map[string]int, order VarOrder) {
+	switch order {
+	case FirstAppearance:
+		return
+	case Lexicographic:
+		sort.Strings(names)
+	case Natural:
+		sort.Slice(names, func(i, j int) bool {
+			return naturalLess(names[i], names[j])
+		})
+	default:
+		panic("lp: unknown variable order")
+	}
+	for i, name := range names {
+		nameMap[name] = i
+	}
+}
+
+// naturalLess returns whether a sorts before b in natural order. Strings are
+// compared in chunks, where a chunk is a maximal run of digits or a single
+// non-digit byte. Digit runs are compared by numeric value and other bytes
+// are compared directly. Strings that are equal in natural order (such as
+// "v1" and "v01") are compared byte-wise.
+func naturalLess(a, b string) bool {
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		if !isDigit(a[i]) || !isDigit(b[j]) {
+			if a[i] != b[j] {
+				return a[i] < b[j]
+			}
+			i++
+			j++
+			continue
+		}
+		si, sj := i, j
+		for i < len(a) && isDigit(a[i]) {
+			i++
+		}
+		for j < len(b) && isDigit(b[j]) {
+			j++
+		}
+		da := trimZeros(a[si:i])
+		db := trimZeros(b[sj:j])
+		if len(da) != len(db) {
+			return len(da) < len(db)
+		}
+		if da != db {
+			return da < db
+		}
+	}
+	if len(a)-i != len(b)-j {
+		return len(a)-i < len(b)-j
+	}
+	return a < b
+}
+
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
+
+// trimZeros removes the leading zeros from a run of digits.
+func trimZeros(s string) string {
+	for len(s) > 0 && s[0] == '0' {
+		s = s[1:]
+	}
+	return s
+}
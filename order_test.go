@@ -0,0 +1,90 @@
+package benchlp
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestSortVariables(t *testing.T) {
+	for _, test := range []struct {
+		order VarOrder
+		want  []string
+	}{
+		{FirstAppearance, []string{"v10", "v2", "x", "v01", "v1", "v1a"}},
+		{Lexicographic, []string{"v01", "v1", "v10", "v1a", "v2", "x"}},
+		{Natural, []string{"v01", "v1", "v1a", "v2", "v10", "x"}},
+	} {
+		names := []string{"v10", "v2", "x", "v01", "v1", "v1a"}
+		nameMap := make(map[string]int)
+		for i, name := range names {
+			nameMap[name] = i
+		}
+		SortVariables(names, nameMap, test.order)
+		if !reflect.DeepEqual(names, test.want) {
+			t.Errorf("order %d: got %v, want %v", test.order, names, test.want)
+		}
+		for i, name := range names {
+			if nameMap[name] != i {
+				t.Errorf("order %d: map index of %s is %d, want %d", test.order, name, nameMap[name], i)
+			}
+		}
+	}
+}
+
+func TestWriteConstraintsToOrderIndependent(t *testing.T) {
+	cons := randomConstraints(50, 100)
+	reversed := make([]Constraint, len(cons))
+	for i, c := range cons {
+		reversed[len(cons)-1-i] = c
+	}
+	write := func(cons []Constraint) []string {
+		var buf bytes.Buffer
+		err := WriteConstraintsTo(&buf, cons, &Options{Order: Natural})
+		if err != nil {
+			t.Fatal(err)
+		}
+		lines := bytes.SplitAfter(buf.Bytes(), []byte("\n"))
+		s := make([]string, len(lines))
+		for i, l := range lines {
+			s[i] = string(l)
+		}
+		return s
+	}
+	a := write(cons)
+	b := write(reversed)
+	for i := range cons {
+		if a[i] != b[len(cons)-1-i] {
+			t.Fatalf("constraint %d differs with reordering:\n%s\n%s", i, a[i], b[len(cons)-1-i])
+		}
+	}
+}
+
+func TestTermOrder(t *testing.T) {
+	cons := []Constraint{
+		{Left: []Term{{"b", 1}, {"c", -3}, {"a", 2}}, Right: []Term{{"d", 3}}},
+	}
+	for _, test := range []struct {
+		order TermOrder
+		want  string
+	}{
+		{IndexOrder, "1 b + -3 c + 2 a + -3 d <= 0\n"},
+		{NameOrder, "2 a + 1 b + -3 c + -3 d <= 0\n"},
+		{MagnitudeOrder, "-3 c + -3 d + 2 a + 1 b <= 0\n"},
+	} {
+		var buf bytes.Buffer
+		if err := WriteConstraintsTo(&buf, cons, &Options{Terms: test.order}); err != nil {
+			t.Fatal(err)
+		}
+		if buf.String() != test.want {
+			t.Errorf("order %d: got %q, want %q", test.order, buf.String(), test.want)
+		}
+	}
+
+	// Sorting the terms does not allocate.
+	names, nameMap := IndexVariables(cons)
+	r := NewRenderer(names, nameMap, nil, nil, make([]byte, 0, 1024), &Options{Terms: MagnitudeOrder})
+	if allocs := testing.AllocsPerRun(10, func() { r.Render(cons[0]) }); allocs != 0 {
+		t.Errorf("%v allocations rendering with sorted terms", allocs)
+	}
+}
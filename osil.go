@@ -0,0 +1,170 @@
+/*
+Copyright 2017 Brendan Tracey
+
+Redistribution and use in source and binary forms, with or without modification,
+are permitted provided that the following conditions are met:
+
+1. Redistributions of source code must retain the above copyright notice, this
+list of conditions and the following disclaimer.
+
+2. Redistributions in binary form must reproduce the above copyright notice,
+this list of conditions and the following disclaimer in the documentation and/or
+other materials provided with the distribution.
+
+3. Neither the name of the copyright holder nor the names of its contributors may
+be used to endorse or promote products derived from this software without specific
+prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED.
+IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT,
+INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF
+LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE
+OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED
+OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package benchlp
+
+import (
+	"encoding/xml"
+	"io"
+	"sort"
+	"strconv"
+)
+
+// WriteOSiL writes the constraints to w as an instance in the Optimization
+// Services instance Language (OSiL). Every variable is free, every
+// constraint has an upper bound of zero, and there is no objective. The
+// coefficients are written row-wise in the linearConstraintCoefficients
+// element, from the CSR form of the constraints.
+//
+// Variables are listed in the order given by opts.Order. Coefficients are
+// formatted according to opts, and the output is buffered and compressed as
+// for WriteConstraintsTo. A nil opts is equivalent to the zero value.
+func WriteOSiL(w io.Writer, cons []Constraint, opts *Options) (err error) {
+	if opts == nil {
+		opts = &Options{}
+	}
+	defer opts.recoverWrite(&err, cons)
+	if err := opts.Limits.check(cons); err != nil {
+		return err
+	}
+	names, m := CSR(cons)
+	if opts.Order != FirstAppearance {
+		sortColumns(names, &m, opts.Order)
+	}
+
+	bw, err := opts.output(w)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cerr := bw.Close(); err == nil {
+			err = cerr
+		}
+	}()
+	// b is written to bw whenever it has grown past a few KiB, so the
+	// output is never held in memory.
+	var b []byte
+	write := func(force bool) error {
+		if !force && len(b) < 4096 {
+			return nil
+		}
+		_, err := bw.Write(b)
+		b = b[:0]
+		return err
+	}
+	appendCount := func(tag string, n int) {
+		b = append(b, '<')
+		b = append(b, tag...)
+		b = append(b, "=\""...)
+		b = strconv.AppendInt(b, int64(n), 10)
+		b = append(b, "\">\n"...)
+	}
+	writeElems := func(tag string, n int, elem func(i int)) error {
+		b = append(b, '<')
+		b = append(b, tag...)
+		b = append(b, ">\n"...)
+		for i := 0; i < n; i++ {
+			b = append(b, "<el>"...)
+			elem(i)
+			b = append(b, "</el>\n"...)
+			if err := write(false); err != nil {
+				return err
+			}
+		}
+		b = append(b, "</"...)
+		b = append(b, tag...)
+		b = append(b, ">\n"...)
+		return nil
+	}
+
+	b = append(b, xml.Header...)
+	b = append(b, "<osil xmlns=\"os.optimizationservices.org\">\n<instanceHeader/>\n<instanceData>\n"...)
+	appendCount("variables numberOfVariables", len(names))
+	for _, name := range names {
+		b = append(b, "<var name=\""...)
+		if err := write(true); err != nil {
+			return err
+		}
+		if err := xml.EscapeText(bw, []byte(name)); err != nil {
+			return err
+		}
+		b = append(b, "\" lb=\"-INF\"/>\n"...)
+	}
+	b = append(b, "</variables>\n"...)
+	appendCount("constraints numberOfConstraints", len(cons))
+	for range cons {
+		b = append(b, "<con ub=\"0\"/>\n"...)
+		if err := write(false); err != nil {
+			return err
+		}
+	}
+	b = append(b, "</constraints>\n"...)
+	if len(m.Value) > 0 {
+		appendCount("linearConstraintCoefficients numberOfValues", len(m.Value))
+		err := writeElems("start", len(m.Ptr), func(i int) {
+			b = strconv.AppendInt(b, int64(m.Ptr[i]), 10)
+		})
+		if err != nil {
+			return err
+		}
+		err = writeElems("colIdx", len(m.Index), func(i int) {
+			b = strconv.AppendInt(b, int64(m.Index[i]), 10)
+		})
+		if err != nil {
+			return err
+		}
+		err = writeElems("value", len(m.Value), func(i int) {
+			b = opts.appendFloat(b, m.Value[i])
+		})
+		if err != nil {
+			return err
+		}
+		b = append(b, "</linearConstraintCoefficients>\n"...)
+	}
+	b = append(b, "</instanceData>\n</osil>\n"...)
+	return write(true)
+}
+
+// sortColumns reorders the variables of the CSR matrix m, whose columns are
+// names, according to order, keeping the indices within each row sorted.
+func sortColumns(names []string, m *SparseMatrix, order VarOrder) {
+	old := append([]string(nil), names...)
+	nameMap := make(map[string]int, len(names))
+	for i, name := range names {
+		nameMap[name] = i
+	}
+	SortVariables(names, nameMap, order)
+	for k, j := range m.Index {
+		m.Index[k] = nameMap[old[j]]
+	}
+	for i := 0; i < m.Rows; i++ {
+		lo, hi := m.Ptr[i], m.Ptr[i+1]
+		sort.Sort(sparseEntries{m.Index[lo:hi], m.Value[lo:hi]})
+	}
+}
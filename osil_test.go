@@ -0,0 +1,73 @@
+package benchlp
+
+import (
+	"bytes"
+	"encoding/xml"
+	"reflect"
+	"testing"
+)
+
+func TestWriteOSiL(t *testing.T) {
+	cons := []Constraint{
+		{Left: []Term{{"b<1>", 2}, {"a", 1}}, Right: []Term{{"c", 0.5}}},
+		{},
+		{Left: []Term{{"c", -1}}},
+	}
+	var buf bytes.Buffer
+	if err := WriteOSiL(&buf, cons, &Options{Precision: -1, Order: Lexicographic}); err != nil {
+		t.Fatal(err)
+	}
+	var doc struct {
+		XMLName   xml.Name `xml:"os.optimizationservices.org osil"`
+		Variables struct {
+			N    int `xml:"numberOfVariables,attr"`
+			Vars []struct {
+				Name string `xml:"name,attr"`
+				LB   string `xml:"lb,attr"`
+			} `xml:"var"`
+		} `xml:"instanceData>variables"`
+		Constraints struct {
+			N   int `xml:"numberOfConstraints,attr"`
+			Con []struct {
+				UB float64 `xml:"ub,attr"`
+			} `xml:"con"`
+		} `xml:"instanceData>constraints"`
+		Coeffs struct {
+			N      int       `xml:"numberOfValues,attr"`
+			Start  []int     `xml:"start>el"`
+			ColIdx []int     `xml:"colIdx>el"`
+			Value  []float64 `xml:"value>el"`
+		} `xml:"instanceData>linearConstraintCoefficients"`
+	}
+	if err := xml.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("%v\n%s", err, buf.String())
+	}
+	if doc.Variables.N != 3 || len(doc.Variables.Vars) != 3 {
+		t.Fatalf("wrong number of variables:\n%s", buf.String())
+	}
+	var names []string
+	for _, v := range doc.Variables.Vars {
+		names = append(names, v.Name)
+		if v.LB != "-INF" {
+			t.Errorf("variable %s has lower bound %s", v.Name, v.LB)
+		}
+	}
+	if want := []string{"a", "b<1>", "c"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("names: got %v, want %v", names, want)
+	}
+	if doc.Constraints.N != 3 || len(doc.Constraints.Con) != 3 {
+		t.Errorf("wrong number of constraints:\n%s", buf.String())
+	}
+	if doc.Coeffs.N != 4 {
+		t.Errorf("got %d values, want 4", doc.Coeffs.N)
+	}
+	if want := []int{0, 3, 3, 4}; !reflect.DeepEqual(doc.Coeffs.Start, want) {
+		t.Errorf("start: got %v, want %v", doc.Coeffs.Start, want)
+	}
+	if want := []int{0, 1, 2, 2}; !reflect.DeepEqual(doc.Coeffs.ColIdx, want) {
+		t.Errorf("colIdx: got %v, want %v", doc.Coeffs.ColIdx, want)
+	}
+	if want := []float64{1, 2, -0.5, -1}; !reflect.DeepEqual(doc.Coeffs.Value, want) {
+		t.Errorf("value: got %v, want %v", doc.Coeffs.Value, want)
+	}
+}
@@ -0,0 +1,141 @@
+/*
+Copyright 2017 Brendan Tracey
+
+Redistribution and use in source and binary forms, with or without modification,
+are permitted provided that the following conditions are met:
+
+1. Redistributions of source code must retain the above copyright notice, this
+list of conditions and the following disclaimer.
+
+2. Redistributions in binary form must reproduce the above copyright notice,
+this list of conditions and the following disclaimer in the documentation and/or
+other materials provided with the distribution.
+
+3. Neither the name of the copyright holder nor the names of its contributors may
+be used to endorse or promote products derived from this software without specific
+prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED.
+IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT,
+INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF
+LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE
+OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED
+OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package benchlp
+
+import (
+	"io"
+	"strconv"
+	"time"
+)
+
+// SolverParams are common solver settings, written as parameter files for
+// particular solvers so that a run can be reproduced from the files this
+// package writes. The zero value of each field leaves the solver default.
+type SolverParams struct {
+	// TimeLimit is the wall clock limit of the solve.
+	TimeLimit time.Duration
+	// Threads is the number of threads the solver may use.
+	Threads int
+	// Seed is the random seed.
+	Seed int
+	// MIPGap is the relative optimality gap at which a MIP solve stops.
+	MIPGap float64
+	// NoPresolve turns presolving off.
+	NoPresolve bool
+}
+
+// The parameter names of each field of SolverParams, for each solver.
+const (
+	gurobiParams = iota
+	cplexParams
+	scipParams
+)
+
+// The fields of SolverParams, as indices of paramNames.
+const (
+	timeLimitParam = iota
+	threadsParam
+	seedParam
+	mipGapParam
+	presolveParam
+)
+
+var paramNames = [...][3]string{
+	timeLimitParam: {"TimeLimit", "CPX_PARAM_TILIM", "limits/time"},
+	threadsParam:   {"Threads", "CPX_PARAM_THREADS", "lp/threads"},
+	seedParam:      {"Seed", "CPX_PARAM_RANDOMSEED", "randomization/randomseedshift"},
+	mipGapParam:    {"MIPGap", "CPX_PARAM_EPGAP", "limits/gap"},
+	presolveParam:  {"Presolve", "CPX_PARAM_PREIND", "presolving/maxrounds"},
+}
+
+// WriteGurobiParams writes the parameters to w as a Gurobi parameter (.prm)
+// file, with one "Name value" line per parameter.
+func WriteGurobiParams(w io.Writer, p *SolverParams) error {
+	return writeParams(w, p, gurobiParams, "", " ")
+}
+
+// WriteCPLEXParams writes the parameters to w as a CPLEX parameter (.prm)
+// file, as read by the CPLEX interactive optimizer with "read file.prm".
+func WriteCPLEXParams(w io.Writer, p *SolverParams) error {
+	return writeParams(w, p, cplexParams, "CPLEX Parameter File Version 12.10.0.0\n", " ")
+}
+
+// WriteSCIPSettings writes the parameters to w as a SCIP settings (.set)
+// file, with one "name = value" line per parameter.
+func WriteSCIPSettings(w io.Writer, p *SolverParams) error {
+	return writeParams(w, p, scipParams, "", " = ")
+}
+
+// writeParams writes the header and a line for each set parameter, with the
+// names of the given solver and the name and value separated by sep.
+func writeParams(w io.Writer, p *SolverParams, solver int, header, sep string) error {
+	b := []byte(header)
+	for _, pv := range p.values() {
+		b = append(b, paramNames[pv.k][solver]...)
+		b = append(b, sep...)
+		b = append(b, pv.value...)
+		b = append(b, '\n')
+	}
+	_, err := w.Write(b)
+	return err
+}
+
+// paramValue is the formatted value of a parameter, with k its index in
+// paramNames.
+type paramValue struct {
+	k     int
+	value string
+}
+
+// values returns the parameters that are set, in the order of paramNames.
+// A nil p sets none.
+func (p *SolverParams) values() []paramValue {
+	if p == nil {
+		return nil
+	}
+	var vs []paramValue
+	if p.TimeLimit > 0 {
+		vs = append(vs, paramValue{timeLimitParam, strconv.FormatFloat(p.TimeLimit.Seconds(), 'g', -1, 64)})
+	}
+	if p.Threads > 0 {
+		vs = append(vs, paramValue{threadsParam, strconv.Itoa(p.Threads)})
+	}
+	if p.Seed != 0 {
+		vs = append(vs, paramValue{seedParam, strconv.Itoa(p.Seed)})
+	}
+	if p.MIPGap > 0 {
+		vs = append(vs, paramValue{mipGapParam, strconv.FormatFloat(p.MIPGap, 'g', -1, 64)})
+	}
+	if p.NoPresolve {
+		// Zero turns presolve off in each solver.
+		vs = append(vs, paramValue{presolveParam, "0"})
+	}
+	return vs
+}
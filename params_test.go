@@ -0,0 +1,56 @@
+package benchlp
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestWriteParams(t *testing.T) {
+	p := &SolverParams{
+		TimeLimit:  90 * time.Second,
+		Threads:    4,
+		Seed:       7,
+		MIPGap:     1e-4,
+		NoPresolve: true,
+	}
+	for _, test := range []struct {
+		name  string
+		write func(io.Writer, *SolverParams) error
+		want  string
+	}{
+		{
+			name:  "gurobi",
+			write: WriteGurobiParams,
+			want:  "TimeLimit 90\nThreads 4\nSeed 7\nMIPGap 0.0001\nPresolve 0\n",
+		},
+		{
+			name:  "cplex",
+			write: WriteCPLEXParams,
+			want: "CPLEX Parameter File Version 12.10.0.0\n" +
+				"CPX_PARAM_TILIM 90\nCPX_PARAM_THREADS 4\nCPX_PARAM_RANDOMSEED 7\nCPX_PARAM_EPGAP 0.0001\nCPX_PARAM_PREIND 0\n",
+		},
+		{
+			name:  "scip",
+			write: WriteSCIPSettings,
+			want: "limits/time = 90\nlp/threads = 4\nrandomization/randomseedshift = 7\n" +
+				"limits/gap = 0.0001\npresolving/maxrounds = 0\n",
+		},
+	} {
+		var buf bytes.Buffer
+		if err := test.write(&buf, p); err != nil {
+			t.Fatal(err)
+		}
+		if buf.String() != test.want {
+			t.Errorf("%s: got\n%s\nwant\n%s", test.name, buf.String(), test.want)
+		}
+		buf.Reset()
+		if err := test.write(&buf, &SolverParams{Threads: 1}); err != nil {
+			t.Fatal(err)
+		}
+		if n := bytes.Count(buf.Bytes(), []byte("\n")); test.name == "cplex" && n != 2 || test.name != "cplex" && n != 1 {
+			t.Errorf("%s: unset parameters written:\n%s", test.name, buf.String())
+		}
+	}
+}
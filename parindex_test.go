@@ -0,0 +1,51 @@
+package benchlp
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestIndexVariablesParallel(t *testing.T) {
+	for _, nCons := range []int{0, 10, 5000, 20000} {
+		cons := randomConstraints(1000, nCons)
+		wantNames, wantMap := IndexVariables(cons)
+		for _, workers := range []int{0, 1, 3, 8} {
+			names, nameMap := IndexVariablesParallel(cons, workers)
+			if !reflect.DeepEqual(names, wantNames) {
+				t.Errorf("ncons %d, workers %d: names differ", nCons, workers)
+			}
+			if !reflect.DeepEqual(nameMap, wantMap) {
+				t.Errorf("ncons %d, workers %d: name maps differ", nCons, workers)
+			}
+		}
+	}
+}
+
+func BenchmarkIndexVariables(b *testing.B) {
+	cons := randomSparseConstraints(*benchVars, *benchCons, *benchDensity)
+	b.Run("Serial", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			IndexVariables(cons)
+		}
+	})
+	b.Run("Parallel", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			IndexVariablesParallel(cons, 0)
+		}
+	})
+}
+
+func TestWriteConstraintsIndexWorkers(t *testing.T) {
+	cons := randomConstraints(100, 5000)
+	var want, got bytes.Buffer
+	if err := WriteConstraintsTo(&want, cons, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteConstraintsTo(&got, cons, &Options{IndexWorkers: 4}); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got.Bytes(), want.Bytes()) {
+		t.Errorf("output with IndexWorkers differs")
+	}
+}
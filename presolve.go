@@ -0,0 +1,73 @@
+/*
+Copyright 2017 Brendan Tracey
+
+Redistribution and use in source and binary forms, with or without modification,
+are permitted provided that the following conditions are met:
+
+1. Redistributions of source code must retain the above copyright notice, this
+list of conditions and the following disclaimer.
+
+2. Redistributions in binary form must reproduce the above copyright notice,
+this list of conditions and the following disclaimer in the documentation and/or
+other materials provided with the distribution.
+
+3. Neither the name of the copyright holder nor the names of its contributors may
+be used to endorse or promote products derived from this software without specific
+prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED.
+IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT,
+INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF
+LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE
+OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED
+OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package benchlp
+
+// PresolveReport records the changes made by Presolve. Indices refer to the
+// positions of constraints in the slice passed to Presolve.
+type PresolveReport struct {
+	// Empty lists the constraints that were removed because every variable
+	// cancelled when the terms were shifted to the left hand side, leaving
+	// the trivially true 0 <= 0.
+	Empty []int
+}
+
+// Presolve returns the constraints that remain after removing empty rows,
+// along with a report of what was removed. The input slice is not modified.
+//
+// Constraints in this package have no variable bounds and a zero constant, so
+// rows dominated by bounds and fixed variables cannot be detected; only rows
+// that condense to no terms are dropped.
+func Presolve(cons []Constraint) ([]Constraint, PresolveReport) {
+	names, nameMap := IndexVariables(cons)
+	c1 := make([]float64, len(names))
+	c2 := make([]float64, len(names))
+
+	var report PresolveReport
+	kept := make([]Constraint, 0, len(cons))
+	for i, c := range cons {
+		w := CondenseConstraint(c1, c2, c, nameMap)
+		if isZero(w) {
+			report.Empty = append(report.Empty, i)
+			continue
+		}
+		kept = append(kept, c)
+	}
+	return kept, report
+}
+
+// isZero returns whether all of the elements of w are zero.
+func isZero(w []float64) bool {
+	for _, v := range w {
+		if v != 0 {
+			return false
+		}
+	}
+	return true
+}
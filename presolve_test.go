@@ -0,0 +1,24 @@
+package benchlp
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPresolve(t *testing.T) {
+	cons := []Constraint{
+		{Left: []Term{{"a", 1}}, Right: []Term{{"b", 2}}},
+		{Left: []Term{{"a", 1}, {"b", 1}}, Right: []Term{{"a", 1}, {"b", 1}}},
+		{},
+		{Left: []Term{{"c", 2}, {"c", -2}}},
+		{Left: []Term{{"c", 1}}},
+	}
+	got, report := Presolve(cons)
+	want := []Constraint{cons[0], cons[4]}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("constraint mismatch: got %v, want %v", got, want)
+	}
+	if !reflect.DeepEqual(report.Empty, []int{1, 2, 3}) {
+		t.Errorf("empty mismatch: got %v, want %v", report.Empty, []int{1, 2, 3})
+	}
+}
@@ -0,0 +1,117 @@
+/*
+Copyright 2017 Brendan Tracey
+
+Redistribution and use in source and binary forms, with or without modification,
+are permitted provided that the following conditions are met:
+
+1. Redistributions of source code must retain the above copyright notice, this
+list of conditions and the following disclaimer.
+
+2. Redistributions in binary form must reproduce the above copyright notice,
+this list of conditions and the following disclaimer in the documentation and/or
+other materials provided with the distribution.
+
+3. Neither the name of the copyright holder nor the names of its contributors may
+be used to endorse or promote products derived from this software without specific
+prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED.
+IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT,
+INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF
+LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE
+OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED
+OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package benchlp
+
+import (
+	"io"
+	"strconv"
+	"text/tabwriter"
+)
+
+// PrintConstraints writes the constraints to w in a readable algebraic form,
+// for debugging small problems. Unlike the LP writers, each side of a
+// constraint is kept as written, with repeated variables combined on each
+// side, and the constraints are aligned in columns. For example
+//
+//	c0:  2 a - b  <=  3 c
+//	c1:  c        <=  0
+func PrintConstraints(w io.Writer, cons []Constraint) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	var b []byte
+	for i, c := range cons {
+		b = append(b[:0], 'c')
+		b = strconv.AppendInt(b, int64(i), 10)
+		b = append(b, ":\t"...)
+		b = appendSide(b, c.Left)
+		b = append(b, "\t<=\t"...)
+		b = appendSide(b, c.Right)
+		b = append(b, '\n')
+		if _, err := tw.Write(b); err != nil {
+			return err
+		}
+	}
+	return tw.Flush()
+}
+
+// String returns the term in algebraic form, for example "2.5 v1".
+func (t Term) String() string {
+	b := strconv.AppendFloat(nil, t.Value, 'g', -1, 64)
+	b = append(b, ' ')
+	return string(append(b, t.Var...))
+}
+
+// String returns the constraint in the algebraic form of PrintConstraints,
+// for example "2.5 v1 + 3 v7 <= 4 v2".
+func (c Constraint) String() string {
+	b := appendSide(nil, c.Left)
+	b = append(b, " <= "...)
+	return string(appendSide(b, c.Right))
+}
+
+// appendSide appends one side of a constraint in algebraic form, combining
+// terms with the same variable in order of first appearance.
+func appendSide(b []byte, terms []Term) []byte {
+	var vars []string
+	coeffs := make(map[string]float64)
+	for _, term := range terms {
+		if _, ok := coeffs[term.Var]; !ok {
+			vars = append(vars, term.Var)
+		}
+		coeffs[term.Var] += term.Value
+	}
+	first := true
+	for _, v := range vars {
+		coeff := coeffs[v]
+		if coeff == 0 {
+			continue
+		}
+		switch {
+		case first && coeff < 0:
+			b = append(b, '-')
+		case !first && coeff < 0:
+			b = append(b, " - "...)
+		case !first:
+			b = append(b, " + "...)
+		}
+		first = false
+		if coeff < 0 {
+			coeff = -coeff
+		}
+		if coeff != 1 {
+			b = strconv.AppendFloat(b, coeff, 'g', -1, 64)
+			b = append(b, ' ')
+		}
+		b = append(b, v...)
+	}
+	if first {
+		b = append(b, '0')
+	}
+	return b
+}
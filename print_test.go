@@ -0,0 +1,38 @@
+package benchlp
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+)
+
+func TestPrintConstraints(t *testing.T) {
+	cons := []Constraint{
+		{Left: []Term{{"a", 1}, {"b", -1}, {"a", 1}}, Right: []Term{{"c", 3}}},
+		{Left: []Term{{"c", 1}}},
+		{Left: []Term{{"x", -0.5}}, Right: []Term{{"y", 1}, {"y", -1}, {"a", 1}}},
+	}
+	var buf bytes.Buffer
+	if err := PrintConstraints(&buf, cons); err != nil {
+		t.Fatal(err)
+	}
+	want := "c0:  2 a - b  <=  3 c\n" +
+		"c1:  c        <=  0\n" +
+		"c2:  -0.5 x   <=  a\n"
+	if buf.String() != want {
+		t.Errorf("got\n%s\nwant\n%s", buf.String(), want)
+	}
+}
+
+func TestString(t *testing.T) {
+	if got := (Term{"v1", 2.5}).String(); got != "2.5 v1" {
+		t.Errorf("term: got %q", got)
+	}
+	c := Constraint{Left: []Term{{"v1", 2.5}, {"v7", 3}, {"v1", -3.5}}, Right: []Term{{"v2", 4}}}
+	if got, want := c.String(), "-v1 + 3 v7 <= 4 v2"; got != want {
+		t.Errorf("constraint: got %q, want %q", got, want)
+	}
+	if got, want := fmt.Sprint(Constraint{}), "0 <= 0"; got != want {
+		t.Errorf("empty constraint: got %q, want %q", got, want)
+	}
+}
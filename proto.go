@@ -0,0 +1,215 @@
+/*
+Copyright 2017 Brendan Tracey
+
+Redistribution and use in source and binary forms, with or without modification,
+are permitted provided that the following conditions are met:
+
+1. Redistributions of source code must retain the above copyright notice, this
+list of conditions and the following disclaimer.
+
+2. Redistributions in binary form must reproduce the above copyright notice,
+this list of conditions and the following disclaimer in the documentation and/or
+other materials provided with the distribution.
+
+3. Neither the name of the copyright holder nor the names of its contributors may
+be used to endorse or promote products derived from this software without specific
+prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED.
+IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT,
+INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF
+LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE
+OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED
+OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package benchlp
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+)
+
+// Protocol Buffers wire types.
+const (
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+	wireFixed32 = 5
+)
+
+// MarshalProto encodes the constraints in the Protocol Buffers wire format as
+// a Model message defined in benchlp.proto, so they can be exchanged with
+// code generated from the schema in other languages.
+func MarshalProto(cons []Constraint) []byte {
+	var n int
+	for _, c := range cons {
+		n += bytesFieldSize(constraintSize(c))
+	}
+	b := make([]byte, 0, n)
+	for _, c := range cons {
+		b = appendTag(b, 1, wireBytes)
+		b = binary.AppendUvarint(b, uint64(constraintSize(c)))
+		b = appendTerms(b, 1, c.Left)
+		b = appendTerms(b, 2, c.Right)
+	}
+	return b
+}
+
+// appendTerms appends each term as an embedded message with the field number.
+func appendTerms(b []byte, field int, terms []Term) []byte {
+	for _, term := range terms {
+		b = appendTag(b, field, wireBytes)
+		b = binary.AppendUvarint(b, uint64(termSize(term)))
+		if term.Var != "" {
+			b = appendTag(b, 1, wireBytes)
+			b = binary.AppendUvarint(b, uint64(len(term.Var)))
+			b = append(b, term.Var...)
+		}
+		if bits := math.Float64bits(term.Value); bits != 0 {
+			b = appendTag(b, 2, wireFixed64)
+			b = binary.LittleEndian.AppendUint64(b, bits)
+		}
+	}
+	return b
+}
+
+func appendTag(b []byte, field, wire int) []byte {
+	return binary.AppendUvarint(b, uint64(field<<3|wire))
+}
+
+// constraintSize returns the encoded size of a Constraint message.
+func constraintSize(c Constraint) int {
+	var n int
+	for _, term := range c.Left {
+		n += bytesFieldSize(termSize(term))
+	}
+	for _, term := range c.Right {
+		n += bytesFieldSize(termSize(term))
+	}
+	return n
+}
+
+// termSize returns the encoded size of a Term message.
+func termSize(term Term) int {
+	var n int
+	if term.Var != "" {
+		n += bytesFieldSize(len(term.Var))
+	}
+	if math.Float64bits(term.Value) != 0 {
+		n += 1 + 8
+	}
+	return n
+}
+
+// bytesFieldSize returns the encoded size of a length-delimited field with a
+// single byte tag and n bytes of content.
+func bytesFieldSize(n int) int {
+	return 1 + uvarintSize(uint64(n)) + n
+}
+
+func uvarintSize(v uint64) int {
+	n := 1
+	for v >= 0x80 {
+		v >>= 7
+		n++
+	}
+	return n
+}
+
+var errProtoFormat = errors.New("lp: malformed protocol buffer")
+
+// UnmarshalProto decodes a Model message in the Protocol Buffers wire format
+// into constraints. Unknown fields are skipped.
+func UnmarshalProto(b []byte) ([]Constraint, error) {
+	var cons []Constraint
+	err := walkFields(b, func(field, wire int, data []byte) error {
+		if field != 1 || wire != wireBytes {
+			return nil
+		}
+		var c Constraint
+		err := walkFields(data, func(field, wire int, data []byte) error {
+			if (field != 1 && field != 2) || wire != wireBytes {
+				return nil
+			}
+			term, err := unmarshalTerm(data)
+			if err != nil {
+				return err
+			}
+			if field == 1 {
+				c.Left = append(c.Left, term)
+			} else {
+				c.Right = append(c.Right, term)
+			}
+			return nil
+		})
+		cons = append(cons, c)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return cons, nil
+}
+
+// unmarshalTerm decodes a Term message.
+func unmarshalTerm(b []byte) (Term, error) {
+	var term Term
+	err := walkFields(b, func(field, wire int, data []byte) error {
+		switch {
+		case field == 1 && wire == wireBytes:
+			term.Var = string(data)
+		case field == 2 && wire == wireFixed64:
+			term.Value = math.Float64frombits(binary.LittleEndian.Uint64(data))
+		}
+		return nil
+	})
+	return term, err
+}
+
+// walkFields calls fn with the field number, wire type, and content of each
+// field in the message. The content of a varint field is its encoded bytes.
+func walkFields(b []byte, fn func(field, wire int, data []byte) error) error {
+	for len(b) > 0 {
+		tag, n := binary.Uvarint(b)
+		if n <= 0 || tag>>3 == 0 || tag>>3 > math.MaxInt32 {
+			return errProtoFormat
+		}
+		b = b[n:]
+		field, wire := int(tag>>3), int(tag&7)
+		var size int
+		switch wire {
+		case wireVarint:
+			if _, n = binary.Uvarint(b); n <= 0 {
+				return errProtoFormat
+			}
+			size = n
+		case wireFixed64:
+			size = 8
+		case wireFixed32:
+			size = 4
+		case wireBytes:
+			l, n := binary.Uvarint(b)
+			if n <= 0 || l > uint64(len(b)-n) {
+				return errProtoFormat
+			}
+			b = b[n:]
+			size = int(l)
+		default:
+			return errProtoFormat
+		}
+		if size > len(b) {
+			return errProtoFormat
+		}
+		if err := fn(field, wire, b[:size]); err != nil {
+			return err
+		}
+		b = b[size:]
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package benchlp
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestProtoRoundTrip(t *testing.T) {
+	cons := randomConstraints(100, 200)
+	cons = append(cons,
+		Constraint{},
+		Constraint{Left: []Term{{"", 0}, {"a", 0}, {"", 2}}},
+		Constraint{Right: []Term{{string(bytes.Repeat([]byte("x"), 300)), -1}}},
+	)
+	b := MarshalProto(cons)
+	got, err := UnmarshalProto(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, cons) {
+		t.Errorf("round trip mismatch")
+	}
+	if _, err := UnmarshalProto(b[:len(b)-1]); err == nil {
+		t.Errorf("no error decoding truncated message")
+	}
+}
+
+func TestMarshalProto(t *testing.T) {
+	// Wire encoding of the Model message with the single constraint
+	// {left: [{var: "x", value: 1}]}.
+	want := []byte{
+		0x0a, 0x0e, // constraints, 14 bytes
+		0x0a, 0x0c, // left, 12 bytes
+		0x0a, 0x01, 'x', // var
+		0x11, 0, 0, 0, 0, 0, 0, 0xf0, 0x3f, // value
+	}
+	got := MarshalProto([]Constraint{{Left: []Term{{"x", 1}}}})
+	if !bytes.Equal(got, want) {
+		t.Errorf("got % x, want % x", got, want)
+	}
+
+	// Unknown fields are skipped.
+	extra := append(append([]byte{}, want...), 0x10, 0x05, 0x1d, 1, 2, 3, 4)
+	cons, err := UnmarshalProto(extra)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(cons, []Constraint{{Left: []Term{{"x", 1}}}}) {
+		t.Errorf("unexpected constraints %v", cons)
+	}
+}
@@ -0,0 +1,41 @@
+package benchlp
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestComments(t *testing.T) {
+	cons := []Constraint{{Left: []Term{{"a", 1}}}}
+	for _, test := range []struct {
+		dialect Dialect
+		want    string
+	}{
+		{CPLEXDialect, "\\ one\n\\ two\n\\ three\n1 a <= 0\n"},
+		{LPSolveDialect, "// one\n// two\n// three\n1 a <= 0;\n"},
+	} {
+		var buf bytes.Buffer
+		opts := &Options{Dialect: test.dialect, Comments: []string{"one", "two\nthree"}}
+		if err := WriteConstraintsTo(&buf, cons, opts); err != nil {
+			t.Fatal(err)
+		}
+		if buf.String() != test.want {
+			t.Errorf("dialect %d: got %q, want %q", test.dialect, buf.String(), test.want)
+		}
+		got, err := ReadConstraints(&buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != 1 || !Equal(got[0], cons[0], 0) {
+			t.Errorf("dialect %d: read %v", test.dialect, got)
+		}
+	}
+}
+
+func TestProvenance(t *testing.T) {
+	lines := Provenance()
+	if len(lines) < 2 || !strings.HasPrefix(lines[0], "generated ") || !strings.HasPrefix(lines[1], "command ") {
+		t.Errorf("got %q", lines)
+	}
+}
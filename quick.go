@@ -0,0 +1,84 @@
+/*
+Copyright 2017 Brendan Tracey
+
+Redistribution and use in source and binary forms, with or without modification,
+are permitted provided that the following conditions are met:
+
+1. Redistributions of source code must retain the above copyright notice, this
+list of conditions and the following disclaimer.
+
+2. Redistributions in binary form must reproduce the above copyright notice,
+this list of conditions and the following disclaimer in the documentation and/or
+other materials provided with the distribution.
+
+3. Neither the name of the copyright holder nor the names of its contributors may
+be used to endorse or promote products derived from this software without specific
+prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED.
+IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT,
+INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF
+LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE
+OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED
+OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package benchlp
+
+import (
+	"math/rand"
+	"reflect"
+	"testing/quick"
+)
+
+// Generate implements quick.Generator. The term has one of size+1 variables
+// and a normally distributed coefficient.
+func (Term) Generate(rnd *rand.Rand, size int) reflect.Value {
+	g := &Generator{Vars: size + 1, Coeffs: NormalCoeffs}
+	prefix, scale := g.params()
+	return reflect.ValueOf(g.term(rnd, prefix, scale))
+}
+
+// Generate implements quick.Generator. The constraint is generated as by a
+// Generator with size+1 variables, normal coefficients, and a Density of
+// size/10, so larger sizes give longer constraints.
+func (Constraint) Generate(rnd *rand.Rand, size int) reflect.Value {
+	g := &Generator{Vars: size + 1, Density: float64(size) / 10, Coeffs: NormalCoeffs}
+	return reflect.ValueOf(g.generate(rnd, 1)[0])
+}
+
+// Values returns a function for the Values field of quick.Config that
+// generates the arguments of the function f with the generator's parameters.
+// Arguments of type Term, Constraint, and []Constraint are generated as by g,
+// with n constraints in each slice, and arguments of other types as by
+// quick.Value. The Seed of g is not used, since quick supplies the random
+// source. Values panics if f is not a function.
+func (g *Generator) Values(f any, n int) func(args []reflect.Value, rnd *rand.Rand) {
+	prefix, scale := g.params()
+	ft := reflect.TypeOf(f)
+	if ft == nil || ft.Kind() != reflect.Func {
+		panic("lp: not a function")
+	}
+	return func(args []reflect.Value, rnd *rand.Rand) {
+		for i := range args {
+			switch t := ft.In(i); t {
+			case reflect.TypeOf(Term{}):
+				args[i] = reflect.ValueOf(g.term(rnd, prefix, scale))
+			case reflect.TypeOf(Constraint{}):
+				args[i] = reflect.ValueOf(g.generate(rnd, 1)[0])
+			case reflect.TypeOf([]Constraint(nil)):
+				args[i] = reflect.ValueOf(g.generate(rnd, n))
+			default:
+				v, ok := quick.Value(t, rnd)
+				if !ok {
+					panic("lp: cannot generate value of type " + t.String())
+				}
+				args[i] = v
+			}
+		}
+	}
+}
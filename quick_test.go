@@ -0,0 +1,53 @@
+package benchlp
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+	"testing/quick"
+)
+
+func TestQuickGenerate(t *testing.T) {
+	// Every constraint equals its canonical form.
+	f := func(c Constraint) bool {
+		return Equal(c, Canonicalize(c), 1e-12)
+	}
+	if err := quick.Check(f, nil); err != nil {
+		t.Error(err)
+	}
+
+	// Terms use the default prefix.
+	g := func(term Term) bool {
+		return len(term.Var) > 1 && term.Var[0] == 'v'
+	}
+	if err := quick.Check(g, nil); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestGeneratorValues(t *testing.T) {
+	gen := &Generator{Vars: 5, Prefix: "x", Terms: FixedTerms, Density: 2, Coeffs: IntegerCoeffs, Scale: 3}
+	f := func(cons []Constraint, c Constraint, term Term, n int) bool {
+		if len(cons) != 4 {
+			return false
+		}
+		for _, c := range append(cons, c) {
+			if len(c.Left) != 3 || len(c.Right) != 3 {
+				return false
+			}
+		}
+		v, ok := (NumericIndexer{"x", 5}).Index(term.Var)
+		return ok && v >= 0 && term.Value == float64(int(term.Value))
+	}
+	if err := quick.Check(f, &quick.Config{Values: gen.Values(f, 4)}); err != nil {
+		t.Error(err)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("no panic for a function argument")
+		}
+	}()
+	h := func(func()) bool { return true }
+	gen.Values(h, 1)(make([]reflect.Value, 1), rand.New(rand.NewSource(1)))
+}
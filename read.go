@@ -0,0 +1,199 @@
+/*
+Copyright 2017 Brendan Tracey
+
+Redistribution and use in source and binary forms, with or without modification,
+are permitted provided that the following conditions are met:
+
+1. Redistributions of source code must retain the above copyright notice, this
+list of conditions and the following disclaimer.
+
+2. Redistributions in binary form must reproduce the above copyright notice,
+this list of conditions and the following disclaimer in the documentation and/or
+other materials provided with the distribution.
+
+3. Neither the name of the copyright holder nor the names of its contributors may
+be used to endorse or promote products derived from this software without specific
+prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED.
+IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT,
+INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF
+LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE
+OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED
+OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package benchlp
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"strconv"
+)
+
+// ReadConstraints reads constraints in the form written by
+// WriteConstraintsTo, one per line, returning each with its terms on the
+// side they were written on, which is the left hand side unless the
+// TwoSided option was used. Any of the formatting options and dialects may
+// have been used, and comment lines and comments at the end of a line are
+// skipped. A number that is not the coefficient of a variable is a
+// constant, and is read as a term of One, so x <= 3 is read as x <= 3 one.
+// A side that is only 0 has no terms.
+//
+// Terms are separated by spaces, so variable names must not contain spaces,
+// and names that begin with '-' are ambiguous when unit coefficients are
+// omitted. Names produced by SanitizeNames with LPNames are always read
+// correctly.
+func ReadConstraints(r io.Reader) ([]Constraint, error) {
+	var cons []Constraint
+	sc := bufio.NewScanner(r)
+	sc.Buffer(nil, 1<<30)
+	var line int
+	for sc.Scan() {
+		line++
+		if isComment(sc.Bytes()) {
+			continue
+		}
+		c, err := parseConstraint(sc.Bytes())
+		if err != nil {
+			return nil, &ParseError{Line: line, Err: err}
+		}
+		cons = append(cons, c)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return cons, nil
+}
+
+// ParseError records the line of the input at which reading failed.
+type ParseError struct {
+	Line int
+	Err  error
+}
+
+func (e *ParseError) Error() string {
+	return "lp: line " + strconv.Itoa(e.Line) + ": " + e.Err.Error()
+}
+
+var errSyntax = errors.New("syntax error")
+
+// isComment returns whether the line is a comment, in either the CPLEX or
+// lp_solve syntax.
+func isComment(line []byte) bool {
+	line = bytes.TrimSpace(line)
+	return bytes.HasPrefix(line, []byte(`\`)) || bytes.HasPrefix(line, []byte("//"))
+}
+
+// trailingComments are the starts of the comments that can end a line.
+var trailingComments = [...][]byte{[]byte(` \`), []byte(" //")}
+
+// trimComment returns the line without a trailing comment, which starts
+// with a space followed by either comment prefix.
+func trimComment(line []byte) []byte {
+	for _, prefix := range trailingComments {
+		if i := bytes.Index(line, prefix); i >= 0 {
+			line = line[:i]
+		}
+	}
+	return line
+}
+
+// parseConstraint parses a single line of output.
+func parseConstraint(line []byte) (Constraint, error) {
+	line = trimComment(line)
+	fields := bytes.Fields(bytes.TrimSuffix(bytes.TrimSpace(line), []byte(";")))
+	k := -1
+	for i, f := range fields {
+		if string(f) == "<=" {
+			k = i
+			break
+		}
+	}
+	if k < 0 || k == len(fields)-1 {
+		return Constraint{}, errSyntax
+	}
+	left, err := parseSide(fields[:k])
+	if err != nil {
+		return Constraint{}, err
+	}
+	right, err := parseSide(fields[k+1:])
+	if err != nil {
+		return Constraint{}, err
+	}
+	return Constraint{Left: left, Right: right}, nil
+}
+
+// parseSide parses the terms of one side of a constraint. A constant is a
+// term of One, except that a side that is the single constant zero has no
+// terms.
+func parseSide(fields [][]byte) ([]Term, error) {
+	if len(fields) == 1 {
+		if con, ok := parseConstant(fields[0]); ok && con == 0 {
+			return nil, nil
+		}
+	}
+	var terms []Term
+	for len(fields) > 0 {
+		sign := 1.0
+		if len(terms) > 0 {
+			switch string(fields[0]) {
+			case "+":
+			case "-":
+				sign = -1
+			default:
+				return nil, errSyntax
+			}
+			if len(fields) == 1 {
+				return nil, errSyntax
+			}
+			fields = fields[1:]
+		}
+		var term Term
+		if v, err := strconv.ParseFloat(string(fields[0]), 64); err == nil && len(fields) > 1 && !isOperator(fields[1]) {
+			term = Term{string(fields[1]), v}
+			fields = fields[2:]
+		} else if con, ok := parseConstant(fields[0]); ok {
+			term = Term{One, con}
+			fields = fields[1:]
+		} else {
+			name := fields[0]
+			term.Value = 1
+			if len(name) > 1 && name[0] == '-' {
+				name = name[1:]
+				term.Value = -1
+			}
+			term.Var = string(name)
+			fields = fields[1:]
+		}
+		if isOperator([]byte(term.Var)) || term.Var == "<=" {
+			return nil, errSyntax
+		}
+		term.Value *= sign
+		terms = append(terms, term)
+	}
+	return terms, nil
+}
+
+// parseConstant parses a field that is a number rather than a name. Unlike
+// strconv.ParseFloat, it does not accept inf or nan, which are read as
+// names.
+func parseConstant(field []byte) (float64, bool) {
+	digits := bytes.TrimLeft(field, "+-")
+	if len(digits) == 0 || (digits[0] < '0' || digits[0] > '9') && digits[0] != '.' {
+		return 0, false
+	}
+	v, err := strconv.ParseFloat(string(field), 64)
+	return v, err == nil
+}
+
+// isOperator returns whether the field is an operator between terms.
+func isOperator(field []byte) bool {
+	return string(field) == "+" || string(field) == "-"
+}
@@ -0,0 +1,169 @@
+/*
+Copyright 2017 Brendan Tracey
+
+Redistribution and use in source and binary forms, with or without modification,
+are permitted provided that the following conditions are met:
+
+1. Redistributions of source code must retain the above copyright notice, this
+list of conditions and the following disclaimer.
+
+2. Redistributions in binary form must reproduce the above copyright notice,
+this list of conditions and the following disclaimer in the documentation and/or
+other materials provided with the distribution.
+
+3. Neither the name of the copyright holder nor the names of its contributors may
+be used to endorse or promote products derived from this software without specific
+prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED.
+IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT,
+INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF
+LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE
+OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED
+OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package benchlp
+
+import "slices"
+
+// Renderer formats constraints one at a time using scratch memory supplied
+// when it is created. Once its byte buffer has grown to hold the longest
+// constraint, rendering does not allocate.
+type Renderer struct {
+	names   []string
+	nameMap map[string]int
+	wl, wr  []float64
+	buf     []byte
+	opts    Options
+	scale   []float64 // factor of each variable from opts.VarScale, or nil
+	dropped int       // coefficients dropped from the last constraint condensed
+	rhs     float64   // right hand side of the last constraint condensed
+	key     [2]uint64 // cache key of the last constraint looked up
+}
+
+// NewRenderer returns a Renderer for constraints over the variables in names
+// and nameMap, as returned by IndexVariables, formatted according to opts.
+// The output form is the same as WriteConstraintsTo. If opts.Indexer is set,
+// it is used in place of nameMap, and names must be in the order of its
+// indices.
+//
+// wl and wr are the scratch weight vectors, and must have one element per
+// variable. buf is the initial output buffer, and should have enough capacity
+// for the longest constraint for rendering to be allocation free. Scratch
+// arguments that are nil are allocated here.
+func NewRenderer(names []string, nameMap map[string]int, wl, wr []float64, buf []byte, opts *Options) *Renderer {
+	if wl == nil {
+		wl = make([]float64, len(names))
+	}
+	if wr == nil {
+		wr = make([]float64, len(names))
+	}
+	if len(wl) != len(names) || len(wr) != len(names) {
+		panic("lp: bad length")
+	}
+	r := &Renderer{
+		names:   names,
+		nameMap: nameMap,
+		wl:      wl,
+		wr:      wr,
+		buf:     buf[:0],
+	}
+	if opts != nil {
+		r.opts = *opts
+	}
+	if r.opts.VarScale != nil {
+		r.scale = r.opts.varScale(nil, names)
+	}
+	return r
+}
+
+// Render returns the formatted line for the constraint. The returned slice
+// is only valid until the next call to Render.
+func (r *Renderer) Render(c Constraint) []byte {
+	if r.opts.useCache() {
+		var ok bool
+		if r.buf, ok = r.cached(r.buf[:0], c); ok {
+			return r.buf
+		}
+		wl, wr := r.condense(c)
+		r.buf = r.format(r.buf[:0], wl, wr)
+		r.cacheLine(r.buf, wl, wr)
+		return r.buf
+	}
+	wl, wr := r.condense(c)
+	r.buf = r.format(r.buf[:0], wl, wr)
+	return r.buf
+}
+
+// Extend updates the variables of r to names and nameMap, which must extend
+// the current variables without changing their indices, as they do after
+// more variables are added to an Index. This supports column generation: the
+// rows that gain a new variable can be rendered again without re-indexing,
+// and lines rendered earlier for the other rows are unchanged. The scratch
+// weight vectors are grown as needed.
+func (r *Renderer) Extend(names []string, nameMap map[string]int) {
+	if len(names) < len(r.names) {
+		panic("lp: fewer variables")
+	}
+	n := len(names)
+	r.wl = slices.Grow(r.wl, n-len(r.wl))[:n]
+	r.wr = slices.Grow(r.wr, n-len(r.wr))[:n]
+	if r.opts.VarScale != nil {
+		r.scale = r.opts.varScale(r.scale, names)
+	}
+	r.names = names
+	r.nameMap = nameMap
+}
+
+// condense returns the condensed weights of the constraint, indexing the
+// variables with the Indexer option if it is set. With the TwoSided option
+// the sides are condensed separately into wl and wr, and otherwise wr is
+// nil.
+func (r *Renderer) condense(c Constraint) (wl, wr []float64) {
+	wl, wr = r.wl, r.wr
+	if r.opts.AllocateScratch {
+		wl = make([]float64, len(r.names))
+		wr = make([]float64, len(r.names))
+	}
+	switch {
+	case r.opts.TwoSided && r.opts.Indexer != nil:
+		wl, wr = condenseIndexedTerms(wl, c.Left, r.opts.Indexer), condenseIndexedTerms(wr, c.Right, r.opts.Indexer)
+	case r.opts.TwoSided:
+		wl, wr = CondenseTerms(wl, c.Left, r.nameMap), CondenseTerms(wr, c.Right, r.nameMap)
+	case r.opts.Indexer != nil:
+		wl, wr = condenseIndexed(wl, wr, c, r.opts.Indexer), nil
+	default:
+		wl, wr = CondenseConstraint(wl, wr, c, r.nameMap), nil
+	}
+	if r.scale != nil {
+		scaleWeights(wl, r.scale)
+		scaleWeights(wr, r.scale)
+	}
+	if r.opts.DropTolerance > 0 {
+		r.dropped = r.opts.drop(wl) + r.opts.drop(wr)
+	}
+	r.rhs = r.opts.rhs(c)
+	return wl, wr
+}
+
+// format appends the line for the weights returned by condense.
+func (r *Renderer) format(b []byte, wl, wr []float64) []byte {
+	if wr != nil {
+		return r.opts.appendTwoSided(b, wl, wr, r.names, r.rhs)
+	}
+	return r.opts.appendConstraint(b, wl, r.names, r.rhs)
+}
+
+// scaleWeights multiplies each weight by its factor.
+func scaleWeights(w, scale []float64) {
+	for j, v := range w {
+		if v != 0 {
+			w[j] = v * scale[j]
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package benchlp
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestRendererAllocs(t *testing.T) {
+	cons := randomConstraints(1000, 1000)
+	names, nameMap := IndexVariables(cons)
+	for _, opts := range []*Options{nil, {Precision: -1, OmitUnit: true, Integers: true}, {Format: 'e', Precision: 8}} {
+		r := NewRenderer(names, nameMap, nil, nil, make([]byte, 0, 64<<10), opts)
+		var i int
+		allocs := testing.AllocsPerRun(len(cons), func() {
+			r.Render(cons[i%len(cons)])
+			i++
+		})
+		if allocs != 0 {
+			t.Errorf("options %+v: got %v allocations per constraint, want 0", opts, allocs)
+		}
+	}
+}
+
+func TestRendererMatchesWriter(t *testing.T) {
+	cons := randomConstraints(100, 100)
+	var want bytes.Buffer
+	if err := WriteConstraintsTo(&want, cons, nil); err != nil {
+		t.Fatal(err)
+	}
+	names, nameMap := IndexVariables(cons)
+	r := NewRenderer(names, nameMap, nil, nil, nil, nil)
+	var got []byte
+	for _, c := range cons {
+		got = append(got, r.Render(c)...)
+	}
+	if !bytes.Equal(got, want.Bytes()) {
+		t.Errorf("rendered output differs from WriteConstraintsTo")
+	}
+}
+
+func TestRendererExtend(t *testing.T) {
+	cons := randomConstraints(20, 10)
+	var x Index
+	for _, c := range cons {
+		x.AddConstraint(c)
+	}
+	opts := &Options{Indexer: &x}
+	names, nameMap := x.Names()
+	r := NewRenderer(names, nameMap, nil, nil, nil, opts)
+	lines := make([]string, len(cons))
+	for i, c := range cons {
+		lines[i] = string(r.Render(c))
+	}
+
+	// Price in a new column appearing in rows 2 and 5.
+	for _, i := range []int{2, 5} {
+		cons[i].Left = append(cons[i].Left, Term{"new", float64(i)})
+		x.AddConstraint(cons[i])
+		r.Extend(x.Names())
+		lines[i] = string(r.Render(cons[i]))
+	}
+
+	var want bytes.Buffer
+	if err := WriteConstraintsTo(&want, cons, opts); err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(lines, ""); got != want.String() {
+		t.Errorf("extended rendering differs from WriteConstraintsTo:\ngot\n%s\nwant\n%s", got, want.String())
+	}
+}
@@ -0,0 +1,120 @@
+/*
+Copyright 2017 Brendan Tracey
+
+Redistribution and use in source and binary forms, with or without modification,
+are permitted provided that the following conditions are met:
+
+1. Redistributions of source code must retain the above copyright notice, this
+list of conditions and the following disclaimer.
+
+2. Redistributions in binary form must reproduce the above copyright notice,
+this list of conditions and the following disclaimer in the documentation and/or
+other materials provided with the distribution.
+
+3. Neither the name of the copyright holder nor the names of its contributors may
+be used to endorse or promote products derived from this software without specific
+prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED.
+IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT,
+INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF
+LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE
+OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED
+OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package benchlp
+
+import (
+	"slices"
+	"sort"
+)
+
+// Reorder returns an ordering of the rows and variables of the constraints
+// that reduces the bandwidth of their coefficient matrix, so that each row
+// uses variables with nearby indices. The ordering is the reverse
+// Cuthill-McKee ordering of the bipartite graph joining each row to its
+// variables, started in each connected component from a node of lowest
+// degree.
+//
+// rows[k] is the index in cons of row k of the reordered problem, and vars
+// indexes the variables in their new order. Writing
+//
+//	SubConstraints(cons, rows, vars.Names)
+//
+// with vars as the Indexer option writes the reordered problem. Variables
+// keep their names, so solution values need no mapping, and rows records the
+// permutation for mapping values associated with rows.
+func Reorder(cons []Constraint) (rows []int, vars MapIndexer) {
+	names, m := CSR(cons)
+	t := csrToCSC(m)
+	nr := m.Rows
+	// Nodes 0 to nr-1 are the rows and nr onwards are the columns.
+	degree := func(v int) int {
+		if v < nr {
+			return m.Ptr[v+1] - m.Ptr[v]
+		}
+		return t.Ptr[v-nr+1] - t.Ptr[v-nr]
+	}
+	neighbors := func(dst []int, v int) []int {
+		if v < nr {
+			for _, j := range m.Index[m.Ptr[v]:m.Ptr[v+1]] {
+				dst = append(dst, nr+j)
+			}
+			return dst
+		}
+		return append(dst, t.Index[t.Ptr[v-nr]:t.Ptr[v-nr+1]]...)
+	}
+
+	nodes := make([]int, nr+m.Cols)
+	for v := range nodes {
+		nodes[v] = v
+	}
+	sort.SliceStable(nodes, func(a, b int) bool {
+		return degree(nodes[a]) < degree(nodes[b])
+	})
+
+	visited := make([]bool, len(nodes))
+	order := make([]int, 0, len(nodes))
+	var adj []int
+	for _, start := range nodes {
+		if visited[start] {
+			continue
+		}
+		visited[start] = true
+		order = append(order, start)
+		for head := len(order) - 1; head < len(order); head++ {
+			adj = neighbors(adj[:0], order[head])
+			sort.SliceStable(adj, func(a, b int) bool {
+				return degree(adj[a]) < degree(adj[b])
+			})
+			for _, v := range adj {
+				if !visited[v] {
+					visited[v] = true
+					order = append(order, v)
+				}
+			}
+		}
+	}
+	slices.Reverse(order)
+
+	rows = make([]int, 0, nr)
+	vars = MapIndexer{
+		Names: make([]string, 0, m.Cols),
+		Map:   make(map[string]int, m.Cols),
+	}
+	for _, v := range order {
+		if v < nr {
+			rows = append(rows, v)
+			continue
+		}
+		name := names[v-nr]
+		vars.Map[name] = len(vars.Names)
+		vars.Names = append(vars.Names, name)
+	}
+	return rows, vars
+}
@@ -0,0 +1,88 @@
+package benchlp
+
+import (
+	"bytes"
+	"math/rand"
+	"strconv"
+	"testing"
+)
+
+// bandwidth returns the largest distance between the index of a row and the
+// index of a variable it uses.
+func bandwidth(cons []Constraint, x VarIndexer) int {
+	var bw int
+	for i, c := range cons {
+		for _, terms := range [][]Term{c.Left, c.Right} {
+			for _, term := range terms {
+				j, _ := x.Index(term.Var)
+				bw = max(bw, i-j, j-i)
+			}
+		}
+	}
+	return bw
+}
+
+func TestReorder(t *testing.T) {
+	// A chain x0 <= x1 <= ... <= xn, with rows and variable order shuffled.
+	const n = 200
+	rnd := rand.New(rand.NewSource(1))
+	perm := rnd.Perm(n + 1)
+	cons := make([]Constraint, n)
+	for i, k := range rnd.Perm(n) {
+		a := "x" + strconv.Itoa(perm[k])
+		b := "x" + strconv.Itoa(perm[k+1])
+		cons[i] = Constraint{Left: []Term{{a, 1}}, Right: []Term{{b, 1}}}
+	}
+	names, nameMap := IndexVariables(cons)
+	if bw := bandwidth(cons, MapIndexer{names, nameMap}); bw < n/4 {
+		t.Fatalf("shuffled bandwidth is only %d", bw)
+	}
+
+	rows, vars := Reorder(cons)
+	if len(rows) != n || len(vars.Names) != n+1 {
+		t.Fatalf("got %d rows and %d variables, want %d and %d", len(rows), len(vars.Names), n, n+1)
+	}
+	seen := make([]bool, n)
+	for _, i := range rows {
+		if seen[i] {
+			t.Fatalf("row %d repeated", i)
+		}
+		seen[i] = true
+	}
+	reordered := SubConstraints(cons, rows, vars.Names)
+	if bw := bandwidth(reordered, vars); bw > 1 {
+		t.Errorf("reordered bandwidth is %d, want at most 1", bw)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteConstraintsTo(&buf, reordered, &Options{Indexer: vars}); err != nil {
+		t.Fatal(err)
+	}
+	got, err := ReadConstraints(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for k, i := range rows {
+		if !Equal(got[k], cons[i], 0) {
+			t.Errorf("row %d: got %v, want %v", k, got[k], cons[i])
+		}
+	}
+}
+
+func TestReorderComponents(t *testing.T) {
+	cons := []Constraint{
+		{Left: []Term{{"a", 1}, {"b", 1}}},
+		{},
+		{Left: []Term{{"c", 1}}, Right: []Term{{"d", 1}}},
+		{Left: []Term{{"b", 2}}},
+	}
+	rows, vars := Reorder(cons)
+	if len(rows) != len(cons) || len(vars.Names) != 4 {
+		t.Fatalf("got rows %v and variables %v", rows, vars.Names)
+	}
+	for name, j := range vars.Map {
+		if vars.Names[j] != name {
+			t.Errorf("variable %s has index %d, which is %s", name, j, vars.Names[j])
+		}
+	}
+}
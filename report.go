@@ -0,0 +1,95 @@
+/*
+Copyright 2017 Brendan Tracey
+
+Redistribution and use in source and binary forms, with or without modification,
+are permitted provided that the following conditions are met:
+
+1. Redistributions of source code must retain the above copyright notice, this
+list of conditions and the following disclaimer.
+
+2. Redistributions in binary form must reproduce the above copyright notice,
+this list of conditions and the following disclaimer in the documentation and/or
+other materials provided with the distribution.
+
+3. Neither the name of the copyright holder nor the names of its contributors may
+be used to endorse or promote products derived from this software without specific
+prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED.
+IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT,
+INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF
+LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE
+OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED
+OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package benchlp
+
+import (
+	"runtime"
+	"time"
+)
+
+// PhaseStats records the resources used by a phase of writing.
+type PhaseStats struct {
+	Duration   time.Duration
+	Allocs     uint64 // number of heap allocations
+	AllocBytes uint64 // bytes allocated on the heap
+	NumGC      uint32 // number of completed garbage collections
+	GCPause    time.Duration
+}
+
+// Report records where the time and memory of a write were spent. It is
+// filled in by the writers when Options.Report is set.
+type Report struct {
+	// Index covers assigning indices to the variables and ordering them.
+	Index PhaseStats
+	// Render covers condensing, formatting, and writing the constraints.
+	Render PhaseStats
+
+	// CondenseTime and FormatTime divide the Render duration between
+	// condensing the constraints and formatting and writing them. Timing
+	// each constraint adds a small overhead to Render.
+	CondenseTime time.Duration
+	FormatTime   time.Duration
+
+	Constraints int
+	Bytes       int64 // bytes written before compression
+
+	// Dropped is the number of coefficients dropped by the DropTolerance
+	// option from each constraint, by index, for the constraints that lost
+	// any.
+	Dropped map[int]int
+}
+
+// phaseTimer measures a PhaseStats. The runtime statistics are read at the
+// start and end of the phase, which stops the world, so phases should be long.
+type phaseTimer struct {
+	start time.Time
+	mem   runtime.MemStats
+}
+
+func startPhase() *phaseTimer {
+	p := &phaseTimer{}
+	runtime.ReadMemStats(&p.mem)
+	p.start = time.Now()
+	return p
+}
+
+// stop returns the statistics of the phase since it started.
+func (p *phaseTimer) stop() PhaseStats {
+	d := time.Since(p.start)
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+	return PhaseStats{
+		Duration:   d,
+		Allocs:     mem.Mallocs - p.mem.Mallocs,
+		AllocBytes: mem.TotalAlloc - p.mem.TotalAlloc,
+		NumGC:      mem.NumGC - p.mem.NumGC,
+		GCPause:    time.Duration(mem.PauseTotalNs - p.mem.PauseTotalNs),
+	}
+}
@@ -0,0 +1,62 @@
+package benchlp
+
+import (
+	"io"
+	"testing"
+)
+
+func TestWriteConstraintsToReport(t *testing.T) {
+	cons := randomConstraints(1000, 5000)
+	var rep Report
+	cw := &countWriter{}
+	if err := WriteConstraintsTo(cw, cons, &Options{Report: &rep}); err != nil {
+		t.Fatal(err)
+	}
+	if rep.Constraints != len(cons) {
+		t.Errorf("got %d constraints, want %d", rep.Constraints, len(cons))
+	}
+	if rep.Bytes != cw.n {
+		t.Errorf("got %d bytes, want %d", rep.Bytes, cw.n)
+	}
+	if rep.Index.Allocs == 0 {
+		t.Errorf("no allocations recorded for indexing")
+	}
+	if rep.CondenseTime <= 0 || rep.FormatTime <= 0 || rep.Render.Duration < rep.CondenseTime+rep.FormatTime {
+		t.Errorf("inconsistent timings: %+v", rep)
+	}
+}
+
+// countWriter counts the bytes written to it.
+type countWriter struct {
+	n int64
+}
+
+func (w *countWriter) Write(b []byte) (int, error) {
+	w.n += int64(len(b))
+	return len(b), nil
+}
+
+func BenchmarkLPReport(b *testing.B) {
+	cons := randomSparseConstraints(*benchVars, *benchCons, *benchDensity)
+	var rep Report
+	var total Report
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		WriteConstraintsTo(io.Discard, cons, &Options{Report: &rep})
+		total.Index.Allocs += rep.Index.Allocs
+		total.Index.GCPause += rep.Index.GCPause
+		total.Render.Allocs += rep.Render.Allocs
+		total.Render.GCPause += rep.Render.GCPause
+		total.CondenseTime += rep.CondenseTime
+		total.FormatTime += rep.FormatTime
+		total.Bytes += rep.Bytes
+	}
+	n := float64(b.N)
+	b.ReportMetric(float64(total.Index.Allocs)/n, "index-allocs/op")
+	b.ReportMetric(float64(total.Index.GCPause.Nanoseconds())/n, "index-gc-ns/op")
+	b.ReportMetric(float64(total.Render.Allocs)/n, "render-allocs/op")
+	b.ReportMetric(float64(total.Render.GCPause.Nanoseconds())/n, "render-gc-ns/op")
+	b.ReportMetric(float64(total.CondenseTime.Nanoseconds())/n, "condense-ns/op")
+	b.ReportMetric(float64(total.FormatTime.Nanoseconds())/n, "format-ns/op")
+	b.ReportMetric(float64(total.Bytes)/n, "output-bytes/op")
+}
@@ -0,0 +1,154 @@
+/*
+Copyright 2017 Brendan Tracey
+
+Redistribution and use in source and binary forms, with or without modification,
+are permitted provided that the following conditions are met:
+
+1. Redistributions of source code must retain the above copyright notice, this
+list of conditions and the following disclaimer.
+
+2. Redistributions in binary form must reproduce the above copyright notice,
+this list of conditions and the following disclaimer in the documentation and/or
+other materials provided with the distribution.
+
+3. Neither the name of the copyright holder nor the names of its contributors may
+be used to endorse or promote products derived from this software without specific
+prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED.
+IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT,
+INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF
+LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE
+OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED
+OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package benchlp
+
+import (
+	"math"
+	"math/bits"
+	"math/rand"
+)
+
+// RNG selects the random number generator of a Generator or of a
+// structured generator such as Knapsack.
+type RNG int
+
+const (
+	// MathRand generates with math/rand, as Generator always has, so
+	// existing seeds reproduce existing instances.
+	MathRand RNG = iota
+	// Xoshiro1 is version 1 of the generator embedded in this package:
+	// xoshiro256** seeded by SplitMix64, with the distributions computed
+	// here rather than by math/rand. Its output for a seed will never
+	// change; a different generator would be a new version. The uniform
+	// and integer coefficients and fixed term counts are computed with
+	// integer arithmetic and exact conversions only. The other
+	// distributions also use math.Log, math.Exp, math.Sqrt, and math.Cos.
+	Xoshiro1
+)
+
+// randSource is the source of the random values of a Generator, implemented
+// by *rand.Rand and *xoshiro.
+type randSource interface {
+	Float64() float64
+	Intn(n int) int
+	ExpFloat64() float64
+	NormFloat64() float64
+}
+
+// newRand returns the random source of the generator with the given seed.
+func (g *Generator) newRand(seed int64) randSource {
+	return g.RNG.source(seed)
+}
+
+// source returns the random source of the generator r with the given seed.
+func (r RNG) source(seed int64) randSource {
+	switch r {
+	case MathRand:
+		return rand.New(rand.NewSource(seed))
+	case Xoshiro1:
+		return newXoshiro(seed)
+	}
+	panic("lp: unknown random number generator")
+}
+
+// xoshiro is the xoshiro256** generator of Blackman and Vigna.
+type xoshiro struct {
+	s [4]uint64
+}
+
+// newXoshiro returns a generator with its state filled by SplitMix64 from
+// the seed, as the authors recommend.
+func newXoshiro(seed int64) *xoshiro {
+	var x xoshiro
+	state := uint64(seed)
+	for i := range x.s {
+		state += splitMixGamma
+		x.s[i] = mix64(state)
+	}
+	return &x
+}
+
+// splitMixGamma is the increment of the SplitMix64 state.
+const splitMixGamma = 0x9e3779b97f4a7c15
+
+// mix64 is the SplitMix64 output function.
+func mix64(z uint64) uint64 {
+	z = (z ^ z>>30) * 0xbf58476d1ce4e5b9
+	z = (z ^ z>>27) * 0x94d049bb133111eb
+	return z ^ z>>31
+}
+
+// Uint64 returns a uniformly distributed 64-bit value.
+func (x *xoshiro) Uint64() uint64 {
+	s := &x.s
+	result := bits.RotateLeft64(s[1]*5, 7) * 9
+	t := s[1] << 17
+	s[2] ^= s[0]
+	s[3] ^= s[1]
+	s[1] ^= s[2]
+	s[0] ^= s[3]
+	s[2] ^= t
+	s[3] = bits.RotateLeft64(s[3], 45)
+	return result
+}
+
+// Float64 returns a uniform value in [0, 1), a multiple of 2^-53.
+func (x *xoshiro) Float64() float64 {
+	return float64(x.Uint64()>>11) * 0x1p-53
+}
+
+// Intn returns a uniform value in [0, n), by Lemire's multiply and reject
+// method. It panics if n is not positive.
+func (x *xoshiro) Intn(n int) int {
+	if n <= 0 {
+		panic("lp: invalid argument to Intn")
+	}
+	un := uint64(n)
+	hi, lo := bits.Mul64(x.Uint64(), un)
+	if lo < un {
+		thresh := -un % un
+		for lo < thresh {
+			hi, lo = bits.Mul64(x.Uint64(), un)
+		}
+	}
+	return int(hi)
+}
+
+// ExpFloat64 returns an exponential value with rate one, by inversion.
+func (x *xoshiro) ExpFloat64() float64 {
+	return -math.Log(1 - x.Float64())
+}
+
+// NormFloat64 returns a standard normal value by the Box-Muller transform.
+func (x *xoshiro) NormFloat64() float64 {
+	u := 1 - x.Float64()
+	v := x.Float64()
+	return math.Sqrt(-2*math.Log(u)) * math.Cos(2*math.Pi*v)
+}
@@ -0,0 +1,87 @@
+package benchlp
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"math"
+	"testing"
+)
+
+func TestXoshiro(t *testing.T) {
+	// Reference values for SplitMix64 from seed 0, and for xoshiro256**
+	// from the state {1, 2, 3, 4}.
+	state := uint64(0)
+	for i, want := range []uint64{0xe220a8397b1dcdaf, 0x6e789e6aa1b965f4, 0x06c45d188009454f} {
+		state += splitMixGamma
+		if got := mix64(state); got != want {
+			t.Errorf("SplitMix64 output %d: got %#x, want %#x", i, got, want)
+		}
+	}
+	x := &xoshiro{s: [4]uint64{1, 2, 3, 4}}
+	for i, want := range []uint64{11520, 0, 1509978240, 1215971899390074240, 1216172134540287360, 607988272756665600} {
+		if got := x.Uint64(); got != want {
+			t.Errorf("xoshiro256** output %d: got %d, want %d", i, got, want)
+		}
+	}
+
+	x = newXoshiro(1)
+	const n = 100000
+	var sum, sumExp, sumNorm, sumSq float64
+	counts := make([]int, 7)
+	for i := 0; i < n; i++ {
+		u := x.Float64()
+		if u < 0 || u >= 1 {
+			t.Fatalf("Float64 returned %v", u)
+		}
+		sum += u
+		counts[x.Intn(len(counts))]++
+		sumExp += x.ExpFloat64()
+		v := x.NormFloat64()
+		sumNorm += v
+		sumSq += v * v
+	}
+	for _, test := range []struct {
+		name      string
+		got, want float64
+	}{
+		{"uniform mean", sum / n, 0.5},
+		{"exponential mean", sumExp / n, 1},
+		{"normal mean", sumNorm / n, 0},
+		{"normal variance", sumSq / n, 1},
+	} {
+		if math.Abs(test.got-test.want) > 0.02 {
+			t.Errorf("%s: got %v, want %v", test.name, test.got, test.want)
+		}
+	}
+	for k, c := range counts {
+		if math.Abs(float64(c)/n-1.0/7) > 0.01 {
+			t.Errorf("Intn(7) returned %d with frequency %v", k, float64(c)/n)
+		}
+	}
+}
+
+// TestXoshiro1Instances pins instances generated with Xoshiro1, which must
+// never change. Only distributions computed without floating point functions
+// are pinned, since those functions can differ in the last bit between
+// architectures.
+func TestXoshiro1Instances(t *testing.T) {
+	for _, test := range []struct {
+		g    Generator
+		want string
+	}{
+		{Generator{Vars: 100, Terms: FixedTerms, Density: 2, Coeffs: IntegerCoeffs, Scale: 10, Seed: 1, RNG: Xoshiro1},
+			"924e94d59876e0962e551812db2428a4145568c815567008fb8067eecae1d90b"},
+		{Generator{Vars: 100, Terms: FixedTerms, Density: 1, Seed: 2, RNG: Xoshiro1},
+			"e649ae570858c4c2cb3fb0cb7076d099e5d7e096b21a92cfa68871c37bad36ab"},
+	} {
+		var buf bytes.Buffer
+		if err := WriteConstraintsTo(&buf, test.g.Generate(1000), &Options{Precision: -1}); err != nil {
+			t.Fatal(err)
+		}
+		sum := sha256.Sum256(buf.Bytes())
+		if got := hex.EncodeToString(sum[:]); got != test.want {
+			t.Errorf("%+v: got SHA-256 %s, want %s", test.g, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+/*
+Copyright 2017 Brendan Tracey
+
+Redistribution and use in source and binary forms, with or without modification,
+are permitted provided that the following conditions are met:
+
+1. Redistributions of source code must retain the above copyright notice, this
+list of conditions and the following disclaimer.
+
+2. Redistributions in binary form must reproduce the above copyright notice,
+this list of conditions and the following disclaimer in the documentation and/or
+other materials provided with the distribution.
+
+3. Neither the name of the copyright holder nor the names of its contributors may
+be used to endorse or promote products derived from this software without specific
+prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED.
+IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT,
+INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF
+LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE
+OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED
+OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package benchlp
+
+import "strconv"
+
+// IntervalTerm is a term whose coefficient is uncertain, taking any value in
+// [Value-Deviation, Value+Deviation].
+type IntervalTerm struct {
+	Var       string
+	Value     float64
+	Deviation float64
+}
+
+// UncertainConstraint is the constraint sum(Left) <= sum(Right) with
+// interval coefficients, of which at most Budget may deviate from their
+// nominal values at once, as in the budgeted uncertainty of Bertsimas and
+// Sim. A fractional Budget lets one more coefficient deviate by that
+// fraction. A Budget of zero gives the nominal constraint, and one at least
+// the number of uncertain variables protects against every deviation at
+// once.
+type UncertainConstraint struct {
+	Left, Right []IntervalTerm
+	Budget      float64
+}
+
+// RobustCounterpart returns the linear robust counterpart of the uncertain
+// constraints. Terms of the same variable are combined, adding their
+// deviations, and for constraint i with nominal coefficients a_j and
+// deviations d_j the counterpart is
+//
+//	sum_j a_j x_j + Budget*z_i + sum_j p_ij <= 0
+//	d_j abs_x_j <= z_i + p_ij  for each uncertain x_j
+//	0 <= z_i,  0 <= p_ij
+//
+// with abs_x_j >= |x_j| from AbsVar, shared between constraints. The
+// auxiliary variables are prefix+"z"+i, prefix+"p"+i+"_"+x_j, and
+// "abs_"+prefix+x_j, and constant terms are written with One as usual. A
+// constraint with no uncertain coefficients is returned in its nominal
+// form.
+func RobustCounterpart(cons []UncertainConstraint, prefix string) []Constraint {
+	var out []Constraint
+	absVars := make(map[string]string)
+	for i, c := range cons {
+		vars, nominal, dev := condenseIntervals(c)
+		row := Constraint{Left: make([]Term, 0, len(vars))}
+		for k, v := range vars {
+			if nominal[k] != 0 {
+				row.Left = append(row.Left, Term{v, nominal[k]})
+			}
+		}
+		z := prefix + "z" + strconv.Itoa(i)
+		var uncertain []Constraint
+		for k, v := range vars {
+			if dev[k] == 0 {
+				continue
+			}
+			abs, ok := absVars[v]
+			if !ok {
+				var absCons []Constraint
+				abs, absCons = AbsVar(prefix+v, []Term{{v, 1}})
+				absVars[v] = abs
+				out = append(out, absCons...)
+			}
+			p := prefix + "p" + strconv.Itoa(i) + "_" + v
+			row.Left = append(row.Left, Term{p, 1})
+			uncertain = append(uncertain,
+				Constraint{Left: []Term{{abs, dev[k]}}, Right: []Term{{z, 1}, {p, 1}}},
+				Constraint{Right: []Term{{p, 1}}},
+			)
+		}
+		if uncertain != nil {
+			row.Left = append(row.Left, Term{z, c.Budget})
+			uncertain = append(uncertain, Constraint{Right: []Term{{z, 1}}})
+		}
+		out = append(out, row)
+		out = append(out, uncertain...)
+	}
+	return out
+}
+
+// condenseIntervals moves the terms of the constraint to the left hand side
+// and combines those of the same variable, returning the variables in order
+// of first appearance with their nominal coefficients and deviations.
+func condenseIntervals(c UncertainConstraint) (vars []string, nominal, dev []float64) {
+	index := make(map[string]int)
+	add := func(terms []IntervalTerm, sign float64) {
+		for _, term := range terms {
+			k, ok := index[term.Var]
+			if !ok {
+				k = len(vars)
+				index[term.Var] = k
+				vars = append(vars, term.Var)
+				nominal = append(nominal, 0)
+				dev = append(dev, 0)
+			}
+			nominal[k] += sign * term.Value
+			dev[k] += term.Deviation
+		}
+	}
+	add(c.Left, 1)
+	add(c.Right, -1)
+	return vars, nominal, dev
+}
@@ -0,0 +1,51 @@
+package benchlp
+
+import (
+	"math"
+	"sort"
+	"testing"
+)
+
+func TestRobustCounterpart(t *testing.T) {
+	// (1±0.5) x + (2±1) y <= 4, with one coefficient deviating at a time.
+	uc := UncertainConstraint{
+		Left:   []IntervalTerm{{"x", 1, 0.5}, {"y", 2, 1}},
+		Right:  []IntervalTerm{{One, 4, 0}},
+		Budget: 1,
+	}
+	certain := UncertainConstraint{Left: []IntervalTerm{{"x", 1, 0}}, Right: []IntervalTerm{{One, 10, 0}}}
+	cons := RobustCounterpart([]UncertainConstraint{uc, certain}, "r_")
+
+	// worst returns the worst case of the uncertain constraint, and the
+	// auxiliary values that minimize the counterpart's left hand side.
+	worst := func(x map[string]float64) (float64, map[string]float64) {
+		devs := []float64{0.5 * math.Abs(x["x"]), 1 * math.Abs(x["y"])}
+		sorted := append([]float64(nil), devs...)
+		sort.Sort(sort.Reverse(sort.Float64Slice(sorted)))
+		z := sorted[1]
+		vals := map[string]float64{
+			"x": x["x"], "y": x["y"], One: 1,
+			"abs_r_x": math.Abs(x["x"]), "abs_r_y": math.Abs(x["y"]),
+			"r_z0": z, "r_p0_x": math.Max(0, devs[0]-z), "r_p0_y": math.Max(0, devs[1]-z),
+		}
+		return x["x"] + 2*x["y"] + sorted[0] - 4, vals
+	}
+	for _, x := range []map[string]float64{
+		{"x": 1, "y": 1},
+		{"x": 0, "y": 1.4},
+		{"x": 0, "y": 1.2},
+		{"x": 2, "y": -1},
+		{"x": 3, "y": 0.5},
+	} {
+		w, vals := worst(x)
+		violated := Violated(cons, vals, 1e-12)
+		if got, want := len(violated) > 0, w > 1e-12; got != want {
+			t.Errorf("x = %v: counterpart violated %v, worst case %v", x, violated, w)
+		}
+	}
+
+	last := cons[len(cons)-1]
+	if want := (Constraint{Left: []Term{{"x", 1}, {One, -10}}}); !Equal(last, want, 0) {
+		t.Errorf("certain constraint not nominal: %v", last)
+	}
+}
@@ -0,0 +1,70 @@
+/*
+Copyright 2017 Brendan Tracey
+
+Redistribution and use in source and binary forms, with or without modification,
+are permitted provided that the following conditions are met:
+
+1. Redistributions of source code must retain the above copyright notice, this
+list of conditions and the following disclaimer.
+
+2. Redistributions in binary form must reproduce the above copyright notice,
+this list of conditions and the following disclaimer in the documentation and/or
+other materials provided with the distribution.
+
+3. Neither the name of the copyright holder nor the names of its contributors may
+be used to endorse or promote products derived from this software without specific
+prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED.
+IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT,
+INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF
+LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE
+OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED
+OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+package benchlp
+
+import "context"
+
+// RowGenerator generates the constraints violated by a candidate solution,
+// for cutting plane and lazy constraint methods built on SolveWithRows.
+type RowGenerator interface {
+	// Generate returns constraints violated by the solution, or none if
+	// the solution satisfies every constraint the generator knows of.
+	Generate(sol *Solution) ([]Constraint, error)
+}
+
+// SolveWithRows solves the constraints with s, then passes the solution to
+// each of the generators, adds the rows they return, and solves again,
+// until a solution is found for which no generator returns a row. It
+// returns the constraints with the generated rows appended, and the
+// statistics and solution of the last run. The constraints passed in are
+// not modified. The loop also ends, without an error, when a run finds no
+// solution, as when the rows made the constraints infeasible.
+func SolveWithRows(ctx context.Context, cons []Constraint, s ConstraintSolver, p *SolverParams, gens ...RowGenerator) ([]Constraint, *RunStats, *Solution, error) {
+	cons = cons[:len(cons):len(cons)]
+	for {
+		if err := ctx.Err(); err != nil {
+			return cons, nil, nil, err
+		}
+		stats, sol, err := s.SolveConstraints(ctx, cons, p)
+		if err != nil || sol == nil {
+			return cons, stats, sol, err
+		}
+		n := len(cons)
+		for _, g := range gens {
+			rows, err := g.Generate(sol)
+			if err != nil {
+				return cons, stats, sol, err
+			}
+			cons = append(cons, rows...)
+		}
+		if len(cons) == n {
+			return cons, stats, sol, nil
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package benchlp
+
+import (
+	"context"
+	"math"
+	"testing"
+)
+
+// boundSolver is a ConstraintSolver that maximizes x subject to the upper
+// bounds x <= c*One among the constraints, with x at most 10.
+type boundSolver struct{ solves int }
+
+func (s *boundSolver) SolveConstraints(ctx context.Context, cons []Constraint, p *SolverParams) (*RunStats, *Solution, error) {
+	s.solves++
+	x := 10.0
+	for _, c := range cons {
+		if len(c.Left) == 1 && c.Left[0].Var == "x" && len(c.Right) == 1 && c.Right[0].Var == One {
+			x = math.Min(x, c.Right[0].Value/c.Left[0].Value)
+		}
+	}
+	if x < 0 {
+		return &RunStats{Status: "infeasible"}, nil, nil
+	}
+	return &RunStats{Status: "optimal"}, &Solution{Values: map[string]float64{"x": x}}, nil
+}
+
+// halver cuts off a solution with x above 1 by bounding x by half of it.
+type halver struct{}
+
+func (halver) Generate(sol *Solution) ([]Constraint, error) {
+	if x := sol.Values["x"]; x > 1 {
+		return []Constraint{{Left: []Term{{"x", 1}}, Right: []Term{{One, x / 2}}}}, nil
+	}
+	return nil, nil
+}
+
+func TestSolveWithRows(t *testing.T) {
+	cons := []Constraint{{Left: []Term{{"y", 1}}}}
+	s := &boundSolver{}
+	got, stats, sol, err := SolveWithRows(context.Background(), cons, s, nil, halver{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// x is 10, 5, 2.5, 1.25, then 0.625.
+	if len(got) != 5 || len(cons) != 1 || s.solves != 5 {
+		t.Errorf("%d constraints after %d solves", len(got), s.solves)
+	}
+	if stats.Status != "optimal" || sol.Values["x"] != 0.625 {
+		t.Errorf("got status %q and x = %v", stats.Status, sol.Values["x"])
+	}
+
+	// A generated row that makes the constraints infeasible ends the loop.
+	infeasible := generatorFunc(func(*Solution) ([]Constraint, error) {
+		return []Constraint{{Left: []Term{{"x", 1}}, Right: []Term{{One, -1}}}}, nil
+	})
+	got, stats, sol, err = SolveWithRows(context.Background(), cons, &boundSolver{}, nil, infeasible)
+	if err != nil || sol != nil || stats.Status != "infeasible" || len(got) != 2 {
+		t.Errorf("infeasible: got %d constraints, status %q, solution %v, error %v", len(got), stats.Status, sol, err)
+	}
+}
+
+type generatorFunc func(*Solution) ([]Constraint, error)
+
+func (f generatorFunc) Generate(sol *Solution) ([]Constraint, error) { return f(sol) }
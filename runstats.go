@@ -0,0 +1,220 @@
+/*
+Copyright 2017 Brendan Tracey
+
+Redistribution and use in source and binary forms, with or without modification,
+are permitted provided that the following conditions are met:
+
+1. Redistributions of source code must retain the above copyright notice, this
+list of conditions and the following disclaimer.
+
+2. Redistributions in binary form must reproduce the above copyright notice,
+this list of conditions and the following disclaimer in the documentation and/or
+other materials provided with the distribution.
+
+3. Neither the name of the copyright holder nor the names of its contributors may
+be used to endorse or promote products derived from this software without specific
+prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED.
+IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT,
+INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF
+LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE
+OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED
+OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package benchlp
+
+import (
+	"bufio"
+	"io"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RunStats are the statistics of a solver run, read from its log so that
+// runs of different solvers can be compared. Objective, Bound, and Gap are
+// NaN if the log does not report them, and the counts are zero.
+type RunStats struct {
+	// Status is "optimal", "infeasible", "unbounded", "infeasible or
+	// unbounded", or "time limit", or the solver's own wording in lower
+	// case for any other outcome, or empty if the log has none.
+	Status     string
+	Iterations int64 // simplex, barrier, and crossover iterations
+	Nodes      int64 // branch-and-bound nodes explored
+	Objective  float64
+	Bound      float64 // best bound of a MIP
+	Gap        float64 // relative MIP gap, 0.01 for 1%
+	Time       time.Duration
+}
+
+// logRule updates the statistics from a line of a log matching re.
+type logRule struct {
+	re     *regexp.Regexp
+	update func(s *RunStats, m []string)
+}
+
+var gurobiLog = []logRule{
+	{regexp.MustCompile(`^Solved in (\d+) iterations and ([\d.]+) seconds`), func(s *RunStats, m []string) {
+		s.Iterations = parseLogInt(m[1])
+		s.Time = parseLogSeconds(m[2])
+	}},
+	{regexp.MustCompile(`^Explored (\d+) nodes \((\d+) simplex iterations\) in ([\d.]+) seconds`), func(s *RunStats, m []string) {
+		s.Nodes = parseLogInt(m[1])
+		s.Iterations = parseLogInt(m[2])
+		s.Time = parseLogSeconds(m[3])
+	}},
+	{regexp.MustCompile(`^Optimal objective\s+(\S+)`), func(s *RunStats, m []string) {
+		s.Status = "optimal"
+		s.Objective = parseLogFloat(m[1])
+	}},
+	{regexp.MustCompile(`^Best objective (\S+), best bound (\S+), gap (\S+?)%?$`), func(s *RunStats, m []string) {
+		s.Objective = parseLogFloat(m[1])
+		s.Bound = parseLogFloat(m[2])
+		s.Gap = parseLogFloat(m[3]) / 100
+	}},
+	{regexp.MustCompile(`^(Optimal solution found|Infeasible model|Unbounded model|Infeasible or unbounded model|Time limit reached)`), func(s *RunStats, m []string) {
+		s.Status = logStatus(m[1])
+	}},
+}
+
+var cplexLog = []logRule{
+	{regexp.MustCompile(`^(?:Primal simplex|Dual simplex|Barrier|Network|Sifting|MIP) - ([^:]+?)\.?(?::\s+Objective =\s+(\S+))?$`), func(s *RunStats, m []string) {
+		s.Status = logStatus(m[1])
+		if m[2] != "" {
+			s.Objective = parseLogFloat(m[2])
+		}
+	}},
+	{regexp.MustCompile(`^Solution time =\s+([\d.]+) sec\.\s+Iterations = (\d+)(?: \(\d+\))?(?:\s+Nodes = (\d+))?`), func(s *RunStats, m []string) {
+		s.Time = parseLogSeconds(m[1])
+		s.Iterations = parseLogInt(m[2])
+		if m[3] != "" {
+			s.Nodes = parseLogInt(m[3])
+		}
+	}},
+	{regexp.MustCompile(`^Current MIP best bound =\s+(\S+) \(gap = \S+, ([\d.]+)%\)`), func(s *RunStats, m []string) {
+		s.Bound = parseLogFloat(m[1])
+		s.Gap = parseLogFloat(m[2]) / 100
+	}},
+}
+
+var highsLog = []logRule{
+	{regexp.MustCompile(`^Model\s+status\s*:\s*(.+)$`), func(s *RunStats, m []string) {
+		s.Status = logStatus(m[1])
+	}},
+	{regexp.MustCompile(`^(?:Simplex|IPM|Crossover)\s+iterations:\s*(\d+)`), func(s *RunStats, m []string) {
+		s.Iterations += parseLogInt(m[1])
+	}},
+	{regexp.MustCompile(`^Objective value\s*:\s*(\S+)`), func(s *RunStats, m []string) {
+		s.Objective = parseLogFloat(m[1])
+	}},
+	{regexp.MustCompile(`^HiGHS run time\s*:\s*([\d.]+)`), func(s *RunStats, m []string) {
+		s.Time = parseLogSeconds(m[1])
+	}},
+	// The MIP solving report.
+	{regexp.MustCompile(`^Status\s+(.+)$`), func(s *RunStats, m []string) {
+		s.Status = logStatus(m[1])
+	}},
+	{regexp.MustCompile(`^Primal bound\s+(\S+)`), func(s *RunStats, m []string) {
+		s.Objective = parseLogFloat(m[1])
+	}},
+	{regexp.MustCompile(`^Dual bound\s+(\S+)`), func(s *RunStats, m []string) {
+		s.Bound = parseLogFloat(m[1])
+	}},
+	{regexp.MustCompile(`^Gap\s+(\S+?)%`), func(s *RunStats, m []string) {
+		s.Gap = parseLogFloat(m[1]) / 100
+	}},
+	{regexp.MustCompile(`^Timing\s+([\d.]+) \(total\)`), func(s *RunStats, m []string) {
+		s.Time = parseLogSeconds(m[1])
+	}},
+	{regexp.MustCompile(`^Nodes\s+(\d+)`), func(s *RunStats, m []string) {
+		s.Nodes = parseLogInt(m[1])
+	}},
+	{regexp.MustCompile(`^LP iterations\s+(\d+) \(total\)`), func(s *RunStats, m []string) {
+		s.Iterations = parseLogInt(m[1])
+	}},
+}
+
+// ReadGurobiLog reads the statistics of a run from a Gurobi log, from the
+// summary lines printed at the end of an LP or MIP solve.
+func ReadGurobiLog(r io.Reader) (*RunStats, error) {
+	return readLog(r, gurobiLog)
+}
+
+// ReadCPLEXLog reads the statistics of a run from a CPLEX interactive
+// optimizer log, from the status, "Solution time", and "Current MIP best
+// bound" lines.
+func ReadCPLEXLog(r io.Reader) (*RunStats, error) {
+	return readLog(r, cplexLog)
+}
+
+// ReadHiGHSLog reads the statistics of a run from a HiGHS log, from the
+// summary of an LP solve or the solving report of a MIP.
+func ReadHiGHSLog(r io.Reader) (*RunStats, error) {
+	return readLog(r, highsLog)
+}
+
+// readLog applies the rules to each line of the log, with later lines
+// overriding earlier ones. Lines are matched with surrounding space
+// removed.
+func readLog(r io.Reader, rules []logRule) (*RunStats, error) {
+	s := &RunStats{Objective: math.NaN(), Bound: math.NaN(), Gap: math.NaN()}
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		for _, rule := range rules {
+			if m := rule.re.FindStringSubmatch(line); m != nil {
+				rule.update(s, m)
+				break
+			}
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// logStatus normalizes a status reported by a solver.
+func logStatus(status string) string {
+	s := strings.ToLower(strings.TrimSpace(status))
+	switch {
+	case strings.Contains(s, "infeasible or unbounded"), strings.Contains(s, "unbounded or infeasible"):
+		return "infeasible or unbounded"
+	case strings.Contains(s, "infeasible"):
+		return "infeasible"
+	case strings.Contains(s, "unbounded"):
+		return "unbounded"
+	case strings.Contains(s, "time limit"):
+		return "time limit"
+	case strings.Contains(s, "optimal"):
+		return "optimal"
+	}
+	return s
+}
+
+// parseLogFloat parses a number in a log, returning NaN for a placeholder
+// such as "-".
+func parseLogFloat(s string) float64 {
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return math.NaN()
+	}
+	return v
+}
+
+func parseLogInt(s string) int64 {
+	v, _ := strconv.ParseInt(s, 10, 64)
+	return v
+}
+
+func parseLogSeconds(s string) time.Duration {
+	return time.Duration(math.Round(parseLogFloat(s) * float64(time.Second)))
+}
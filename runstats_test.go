@@ -0,0 +1,124 @@
+package benchlp
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReadSolverLogs(t *testing.T) {
+	nan := math.NaN()
+	for _, test := range []struct {
+		name string
+		log  string
+		want RunStats
+	}{
+		{
+			name: "gurobi lp",
+			log: `Optimize a model with 3 rows, 4 columns and 8 nonzeros
+Iteration    Objective       Primal Inf.    Dual Inf.      Time
+       0    0.0000000e+00   2.000000e+00   0.000000e+00      0s
+       3    1.2500000e+01   0.000000e+00   0.000000e+00      0s
+
+Solved in 3 iterations and 0.01 seconds (0.00 work units)
+Optimal objective  1.250000000e+01
+`,
+			want: RunStats{Status: "optimal", Iterations: 3, Objective: 12.5, Bound: nan, Gap: nan, Time: 10 * time.Millisecond},
+		},
+		{
+			name: "gurobi mip",
+			log: `Explored 1523 nodes (20415 simplex iterations) in 4.25 seconds (3.10 work units)
+Thread count was 8 (of 8 available processors)
+
+Solution count 4: 120 118 101 95
+
+Time limit reached
+Best objective 1.200000000000e+02, best bound 1.260000000000e+02, gap 5.0000%
+`,
+			want: RunStats{Status: "time limit", Iterations: 20415, Nodes: 1523, Objective: 120, Bound: 126, Gap: 0.05, Time: 4250 * time.Millisecond},
+		},
+		{
+			name: "gurobi infeasible",
+			log: `Solved in 0 iterations and 0.00 seconds (0.00 work units)
+Infeasible model
+`,
+			want: RunStats{Status: "infeasible", Objective: nan, Bound: nan, Gap: nan},
+		},
+		{
+			name: "cplex lp",
+			log: `Tried aggregator 1 time.
+Dual simplex - Optimal:  Objective =  1.2500000000e+01
+Solution time =    0.02 sec.  Iterations = 5 (1)
+Deterministic time = 0.01 ticks  (0.50 ticks/sec)
+`,
+			want: RunStats{Status: "optimal", Iterations: 5, Objective: 12.5, Bound: nan, Gap: nan, Time: 20 * time.Millisecond},
+		},
+		{
+			name: "cplex mip",
+			log: `MIP - Time limit exceeded, integer feasible:  Objective =  1.2000000000e+02
+Current MIP best bound =  1.2600000000e+02 (gap = 6, 5.00%)
+Solution time =    4.25 sec.  Iterations = 20415  Nodes = 1523 (310)
+`,
+			want: RunStats{Status: "time limit", Iterations: 20415, Nodes: 1523, Objective: 120, Bound: 126, Gap: 0.05, Time: 4250 * time.Millisecond},
+		},
+		{
+			name: "cplex infeasible",
+			log:  "Dual simplex - Infeasible.\n",
+			want: RunStats{Status: "infeasible", Objective: nan, Bound: nan, Gap: nan},
+		},
+		{
+			name: "highs lp",
+			log: `Model   status      : Optimal
+Simplex   iterations: 4
+IPM       iterations: 6
+Crossover iterations: 1
+Objective value     :  1.2500000000e+01
+HiGHS run time      :          0.03
+`,
+			want: RunStats{Status: "optimal", Iterations: 11, Objective: 12.5, Bound: nan, Gap: nan, Time: 30 * time.Millisecond},
+		},
+		{
+			name: "highs mip",
+			log: `Solving report
+  Status            Time limit reached
+  Primal bound      120
+  Dual bound        126
+  Gap               5% (tolerance: 0.01%)
+  Solution status   feasible
+                    120 (objective)
+  Timing            4.25 (total)
+  Nodes             1523
+  LP iterations     20415 (total)
+`,
+			want: RunStats{Status: "time limit", Iterations: 20415, Nodes: 1523, Objective: 120, Bound: 126, Gap: 0.05, Time: 4250 * time.Millisecond},
+		},
+	} {
+		var got *RunStats
+		var err error
+		switch {
+		case strings.HasPrefix(test.name, "gurobi"):
+			got, err = ReadGurobiLog(strings.NewReader(test.log))
+		case strings.HasPrefix(test.name, "cplex"):
+			got, err = ReadCPLEXLog(strings.NewReader(test.log))
+		default:
+			got, err = ReadHiGHSLog(strings.NewReader(test.log))
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !sameRunStats(*got, test.want) {
+			t.Errorf("%s: got %+v, want %+v", test.name, *got, test.want)
+		}
+	}
+}
+
+// sameRunStats compares run statistics, with NaNs equal.
+func sameRunStats(a, b RunStats) bool {
+	same := func(x, y float64) bool {
+		return x == y || math.IsNaN(x) && math.IsNaN(y) || math.Abs(x-y) < 1e-12
+	}
+	return a.Status == b.Status && a.Iterations == b.Iterations && a.Nodes == b.Nodes &&
+		same(a.Objective, b.Objective) && same(a.Bound, b.Bound) && same(a.Gap, b.Gap) &&
+		a.Time == b.Time
+}
@@ -0,0 +1,179 @@
+/*
+Copyright 2017 Brendan Tracey
+
+Redistribution and use in source and binary forms, with or without modification,
+are permitted provided that the following conditions are met:
+
+1. Redistributions of source code must retain the above copyright notice, this
+list of conditions and the following disclaimer.
+
+2. Redistributions in binary form must reproduce the above copyright notice,
+this list of conditions and the following disclaimer in the documentation and/or
+other materials provided with the distribution.
+
+3. Neither the name of the copyright holder nor the names of its contributors may
+be used to endorse or promote products derived from this software without specific
+prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED.
+IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT,
+INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF
+LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE
+OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED
+OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package benchlp
+
+import "math"
+
+// Scaling records the scale factors applied by Scale. The coefficient of
+// variable j in constraint i is multiplied by Row[i]*Col[j], and Names[j] is
+// the name of variable j.
+//
+// The scaled variables are related to the original variables by
+// x_j = Col[j] * x'_j. Row scaling multiplies both sides of a constraint by
+// a positive factor, so it does not affect the solution. The coefficients
+// of One are the constant terms, so One is scaled only with the rows and its
+// Col is 1.
+type Scaling struct {
+	Names []string
+	Row   []float64
+	Col   []float64
+}
+
+// Scale applies geometric-mean row and column scaling to the constraints. Each
+// pass scales every row and then every column by the inverse of the geometric
+// mean of its largest and smallest coefficient magnitudes. The factors are
+// rounded to powers of two so that scaling introduces no rounding error.
+// The constant terms, the coefficients of One, are not counted in the
+// magnitudes of the rows, and One is not column scaled. Scale returns the scaled constraints and the factors used. The input slice
+// is not modified.
+func Scale(cons []Constraint, passes int) ([]Constraint, Scaling) {
+	names, nameMap := IndexVariables(cons)
+	rows := sparseRows(cons, names, nameMap)
+	one, hasOne := nameMap[One]
+	if !hasOne {
+		one = -1
+	}
+
+	s := Scaling{
+		Names: names,
+		Row:   make([]float64, len(cons)),
+		Col:   make([]float64, len(names)),
+	}
+	for i := range s.Row {
+		s.Row[i] = 1
+	}
+	for j := range s.Col {
+		s.Col[j] = 1
+	}
+
+	colMin := make([]float64, len(names))
+	colMax := make([]float64, len(names))
+	for p := 0; p < passes; p++ {
+		for i, row := range rows {
+			min, max := math.Inf(1), 0.0
+			for k, j := range row.Index {
+				if j == one {
+					continue
+				}
+				v := math.Abs(row.Value[k] * s.Col[j])
+				min = math.Min(min, v)
+				max = math.Max(max, v)
+			}
+			if max != 0 {
+				s.Row[i] = powerOfTwo(1 / math.Sqrt(min*max))
+			}
+		}
+		for j := range colMin {
+			colMin[j] = math.Inf(1)
+			colMax[j] = 0
+		}
+		for i, row := range rows {
+			for k, j := range row.Index {
+				v := math.Abs(row.Value[k] * s.Row[i])
+				colMin[j] = math.Min(colMin[j], v)
+				colMax[j] = math.Max(colMax[j], v)
+			}
+		}
+		for j := range s.Col {
+			if colMax[j] != 0 && j != one {
+				s.Col[j] = powerOfTwo(1 / math.Sqrt(colMin[j]*colMax[j]))
+			}
+		}
+	}
+
+	scaled := make([]Constraint, len(cons))
+	for i, c := range cons {
+		scaled[i] = Constraint{
+			Left:  scaleTerms(c.Left, s.Row[i], s.Col, nameMap),
+			Right: scaleTerms(c.Right, s.Row[i], s.Col, nameMap),
+		}
+	}
+	return scaled, s
+}
+
+// Unscale converts the values of the scaled variables in x to values of the
+// original variables. Variables not present in the scaling are unchanged.
+func (s Scaling) Unscale(x map[string]float64) {
+	for j, name := range s.Names {
+		if v, ok := x[name]; ok {
+			x[name] = v * s.Col[j]
+		}
+	}
+}
+
+// VarScaling returns the Scaling of the variables by the VarScale option
+// scale, for unscaling a solution of a problem written with it. The rows are
+// not scaled, and Row is nil.
+func VarScaling(scale map[string]float64) Scaling {
+	s := Scaling{
+		Names: sortedKeys(scale),
+		Col:   make([]float64, len(scale)),
+	}
+	for j, name := range s.Names {
+		s.Col[j] = scale[name]
+	}
+	return s
+}
+
+// sparseRows returns the condensed constraints in sparse form, with the
+// non-zero indices in increasing order.
+func sparseRows(cons []Constraint, names []string, nameMap map[string]int) []CanonicalRow {
+	c1 := make([]float64, len(names))
+	c2 := make([]float64, len(names))
+	rows := make([]CanonicalRow, len(cons))
+	for i, c := range cons {
+		w := CondenseConstraint(c1, c2, c, nameMap)
+		for j, v := range w {
+			if v != 0 {
+				rows[i].Index = append(rows[i].Index, j)
+				rows[i].Value = append(rows[i].Value, v)
+			}
+		}
+	}
+	return rows
+}
+
+// scaleTerms returns a copy of the terms with each value multiplied by the row
+// scale and the scale of its variable.
+func scaleTerms(terms []Term, row float64, col []float64, nameMap map[string]int) []Term {
+	if terms == nil {
+		return nil
+	}
+	scaled := make([]Term, len(terms))
+	for i, term := range terms {
+		scaled[i] = Term{term.Var, term.Value * row * col[nameMap[term.Var]]}
+	}
+	return scaled
+}
+
+// powerOfTwo returns the power of two nearest to v in a logarithmic sense.
+func powerOfTwo(v float64) float64 {
+	return math.Exp2(math.Round(math.Log2(v)))
+}
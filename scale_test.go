@@ -0,0 +1,110 @@
+package benchlp
+
+import (
+	"bytes"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestScale(t *testing.T) {
+	cons := []Constraint{
+		{Left: []Term{{"a", 1e-8}, {"b", 1e4}}, Right: []Term{{"c", 1e8}}},
+		{Left: []Term{{"a", 1e-6}}, Right: []Term{{"b", 1e3}, {"b", 1e3}}},
+		{Left: []Term{{"c", 1e6}}, Right: []Term{{"a", 1e-7}}},
+	}
+	scaled, s := Scale(cons, 4)
+
+	rangeOf := func(cons []Constraint) float64 {
+		min, max := math.Inf(1), 0.0
+		for _, c := range cons {
+			for _, term := range append(append([]Term{}, c.Left...), c.Right...) {
+				v := math.Abs(term.Value)
+				min = math.Min(min, v)
+				max = math.Max(max, v)
+			}
+		}
+		return max / min
+	}
+	if rangeOf(scaled) >= rangeOf(cons) {
+		t.Errorf("scaling did not reduce the coefficient range: before %g, after %g", rangeOf(cons), rangeOf(scaled))
+	}
+
+	// Each term must be scaled by its row and column factors.
+	_, nameMap := IndexVariables(cons)
+	for i, c := range cons {
+		for _, term := range c.Left {
+			want := term.Value * s.Row[i] * s.Col[nameMap[term.Var]]
+			var got float64
+			for _, st := range scaled[i].Left {
+				if st.Var == term.Var {
+					got = st.Value
+					break
+				}
+			}
+			if got != want {
+				t.Errorf("constraint %d, var %s: got %g, want %g", i, term.Var, got, want)
+			}
+		}
+	}
+
+	x := map[string]float64{"a": 1, "b": 2, "c": 3}
+	s.Unscale(x)
+	for j, name := range s.Names {
+		want := float64(j+1) * s.Col[j]
+		if x[name] != want {
+			t.Errorf("unscaled %s: got %g, want %g", name, x[name], want)
+		}
+	}
+}
+
+func TestScaleConstants(t *testing.T) {
+	// x <= 1e6 and 1e-3 x + 1e-3 y <= 1. Scaling the rows leaves the
+	// constants in proportion to their rows, and One is not scaled.
+	cons := []Constraint{
+		{Left: []Term{{"x", 1}}, Right: []Term{{One, 1e6}}},
+		{Left: []Term{{"x", 1e-3}, {"y", 1e-3}}, Right: []Term{{One, 1}}},
+	}
+	scaled, s := Scale(cons, 2)
+	for j, name := range s.Names {
+		if name == One && s.Col[j] != 1 {
+			t.Errorf("One scaled by %g", s.Col[j])
+		}
+	}
+	// The point x = 1e3, y = 0 is feasible, with the second row tight,
+	// before and after scaling.
+	x := map[string]float64{"x": 1e3, "y": 0}
+	for j, name := range s.Names {
+		if v, ok := x[name]; ok {
+			x[name] = v / s.Col[j]
+		}
+	}
+	for i, e := range EvaluateConstraints(scaled, x) {
+		if i == 1 && e.Slack != 0 || e.Slack < 0 {
+			t.Errorf("constraint %d: slack %g", i, e.Slack)
+		}
+	}
+}
+
+func TestVarScale(t *testing.T) {
+	cons := []Constraint{
+		{Left: []Term{{"cost", 1}}, Right: []Term{{"budget", 1}}},
+		{Left: []Term{{"units", 2}, {"cost", -3}}},
+	}
+	scale := map[string]float64{"cost": 1e6, "budget": 1e6}
+	var buf bytes.Buffer
+	if err := WriteConstraintsTo(&buf, cons, &Options{VarScale: scale}); err != nil {
+		t.Fatal(err)
+	}
+	want := "1000000 cost + -1000000 budget <= 0\n" +
+		"-3000000 cost + 2 units <= 0\n"
+	if buf.String() != want {
+		t.Errorf("got\n%s\nwant\n%s", buf.String(), want)
+	}
+
+	x := map[string]float64{"cost": 1.5, "budget": 2, "units": 7}
+	VarScaling(scale).Unscale(x)
+	if want := map[string]float64{"cost": 1.5e6, "budget": 2e6, "units": 7}; !reflect.DeepEqual(x, want) {
+		t.Errorf("unscaled %v, want %v", x, want)
+	}
+}
@@ -0,0 +1,157 @@
+/*
+Copyright 2017 Brendan Tracey
+
+Redistribution and use in source and binary forms, with or without modification,
+are permitted provided that the following conditions are met:
+
+1. Redistributions of source code must retain the above copyright notice, this
+list of conditions and the following disclaimer.
+
+2. Redistributions in binary form must reproduce the above copyright notice,
+this list of conditions and the following disclaimer in the documentation and/or
+other materials provided with the distribution.
+
+3. Neither the name of the copyright holder nor the names of its contributors may
+be used to endorse or promote products derived from this software without specific
+prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED.
+IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT,
+INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF
+LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE
+OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED
+OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+package benchlp
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// InfeasibleError describes a constraint found by ScreenBounds that cannot
+// hold.
+type InfeasibleError struct {
+	// Row is the index of the constraint that cannot hold.
+	Row int
+	// Bounds are the indices of the single-variable constraints whose bounds
+	// make Row infeasible, in increasing order. It is empty if Row is
+	// infeasible by itself, as 3*One <= One is.
+	Bounds []int
+	// Activity is the least value of the left hand side of Row, with all
+	// of its terms moved there, within the bounds. It is greater than zero.
+	Activity float64
+}
+
+func (e *InfeasibleError) Error() string {
+	s := "lp: constraint " + strconv.Itoa(e.Row) + " is infeasible"
+	if len(e.Bounds) > 0 {
+		b := make([]string, len(e.Bounds))
+		for k, i := range e.Bounds {
+			b[k] = strconv.Itoa(i)
+		}
+		s += " with the bounds of constraints " + strings.Join(b, ", ")
+	}
+	return s + ": least activity " + strconv.FormatFloat(e.Activity, 'g', -1, 64)
+}
+
+// ScreenBounds is a cheap check for constraints that cannot hold, such as
+// the pair x <= 1*One and 3*One <= x, to run before writing a large problem.
+// One is fixed at one and every other variable is free, so a constraint
+// with a single variable bounds that variable. The least activity of each
+// constraint within the bounds of the others is computed, and a constraint
+// is reported if it exceeds tol. Bounds are not propagated through
+// constraints with more than one variable, so not every infeasible problem
+// is found.
+//
+// A single-variable constraint is checked against the bounds of the earlier
+// constraints, so a pair with crossed bounds is reported once, at the later
+// of the two. The errors are in order of Row. Constraint i is named ci in
+// MPS and AMPL output.
+func ScreenBounds(cons []Constraint, tol float64) []*InfeasibleError {
+	names, nameMap := IndexVariables(cons)
+	one, hasOne := nameMap[One]
+	n := len(names)
+	lo, hi := make([]float64, n), make([]float64, n)
+	loRow, hiRow := make([]int, n), make([]int, n)
+	for j := range lo {
+		lo[j], hi[j] = math.Inf(-1), math.Inf(1)
+		loRow[j], hiRow[j] = -1, -1
+	}
+
+	// row condenses c into its constant, the coefficient of One, and the
+	// indices and coefficients of its other variables.
+	wl, wr := make([]float64, n), make([]float64, n)
+	var idx []int
+	var val []float64
+	row := func(c Constraint) (b float64) {
+		idx, val = idx[:0], val[:0]
+		sparseCondense(wl, wr, c, nameMap, func(j int, v float64) {
+			if hasOne && j == one {
+				b = v
+				return
+			}
+			idx = append(idx, j)
+			val = append(val, v)
+		})
+		return b
+	}
+
+	// a*x + b <= 0 bounds x by -b/a, from above if a is positive. Each
+	// bound is checked against the opposite bound of the earlier
+	// constraints before it is applied.
+	var errs []*InfeasibleError
+	for i, c := range cons {
+		b := row(c)
+		if len(idx) != 1 {
+			continue
+		}
+		j, a := idx[0], val[0]
+		bound := -b / a
+		if a > 0 {
+			if activity := a*lo[j] + b; activity > tol {
+				errs = append(errs, &InfeasibleError{Row: i, Bounds: []int{loRow[j]}, Activity: activity})
+			}
+			if bound < hi[j] {
+				hi[j], hiRow[j] = bound, i
+			}
+		} else {
+			if activity := a*hi[j] + b; activity > tol {
+				errs = append(errs, &InfeasibleError{Row: i, Bounds: []int{hiRow[j]}, Activity: activity})
+			}
+			if bound > lo[j] {
+				lo[j], loRow[j] = bound, i
+			}
+		}
+	}
+
+	for i, c := range cons {
+		activity := row(c)
+		if len(idx) == 1 {
+			continue
+		}
+		var bounds []int
+		for k, j := range idx {
+			v, r := val[k]*lo[j], loRow[j]
+			if val[k] < 0 {
+				v, r = val[k]*hi[j], hiRow[j]
+			}
+			activity += v
+			if r >= 0 {
+				bounds = append(bounds, r)
+			}
+		}
+		if activity > tol {
+			sort.Ints(bounds)
+			errs = append(errs, &InfeasibleError{Row: i, Bounds: bounds, Activity: activity})
+		}
+	}
+	sort.Slice(errs, func(a, b int) bool { return errs[a].Row < errs[b].Row })
+	return errs
+}
@@ -0,0 +1,48 @@
+package benchlp
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestScreenBounds(t *testing.T) {
+	cons := []Constraint{
+		{Left: []Term{{"x", 1}}, Right: []Term{{One, 1}}},                // x <= 1
+		{Left: []Term{{"y", 1}, {"z", 1}}, Right: []Term{{"x", 1}}},      // y + z <= x, least 2 - 0.95
+		{Left: []Term{{One, 3}}, Right: []Term{{"x", 1}}},                // 3 <= x, crosses row 0
+		{Left: []Term{{"y", -2}}},                                        // y >= 0
+		{Left: []Term{{"z", -1}}, Right: []Term{{One, -2}}},              // z >= 2
+		{Left: []Term{{"y", 1}, {"z", 1}, {One, -1}}},                    // y + z <= 1, least 2 - 1
+		{Left: []Term{{One, 2}}, Right: []Term{{One, 1}}},                // 2 <= 1
+		{Left: []Term{{"w", 1}, {One, 1}}, Right: []Term{{"w", 1}}},      // 1 <= 0 once w cancels
+		{Left: []Term{{"y", 1}, {"v", 1}, {One, 5}}},                     // v is free
+		{Left: []Term{{"z", 1}}, Right: []Term{{One, 2 + 1e-12}}},        // z <= 2 within tol
+		{Left: []Term{{"x", 1}, {One, 0.1}}, Right: []Term{{One, 1.05}}}, // tighter x <= 0.95
+	}
+	got := ScreenBounds(cons, 1e-9)
+	want := []*InfeasibleError{
+		{Row: 1, Bounds: []int{3, 4, 10}, Activity: 2 - 0.95},
+		{Row: 2, Bounds: []int{0}, Activity: 3 - 1},
+		{Row: 5, Bounds: []int{3, 4}, Activity: 1},
+		{Row: 6, Activity: 1},
+		{Row: 7, Activity: 1},
+		{Row: 10, Bounds: []int{2}, Activity: 3 + 0.1 - 1.05},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d errors, want %d: %v", len(got), len(want), got)
+	}
+	for k := range want {
+		g, w := got[k], want[k]
+		if g.Row != w.Row || !reflect.DeepEqual(g.Bounds, w.Bounds) || math.Abs(g.Activity-w.Activity) > 1e-12 {
+			t.Errorf("error %d: got %v, want %v", k, g, w)
+		}
+	}
+	if s, want := got[1].Error(), "lp: constraint 2 is infeasible with the bounds of constraints 0: least activity 2"; s != want {
+		t.Errorf("got message %q, want %q", s, want)
+	}
+
+	if errs := ScreenBounds(randomConstraints(20, 50), 0); errs != nil {
+		t.Errorf("homogeneous constraints: got %v, want none", errs)
+	}
+}
@@ -0,0 +1,187 @@
+/*
+Copyright 2017 Brendan Tracey
+
+Redistribution and use in source and binary forms, with or without modification,
+are permitted provided that the following conditions are met:
+
+1. Redistributions of source code must retain the above copyright notice, this
+list of conditions and the following disclaimer.
+
+2. Redistributions in binary form must reproduce the above copyright notice,
+this list of conditions and the following disclaimer in the documentation and/or
+other materials provided with the distribution.
+
+3. Neither the name of the copyright holder nor the names of its contributors may
+be used to endorse or promote products derived from this software without specific
+prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED.
+IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT,
+INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF
+LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE
+OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED
+OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package benchlp
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+)
+
+// Partition is a way of dividing constraints among shards.
+type Partition int
+
+const (
+	// RangePartition gives each shard a contiguous range of constraints of
+	// nearly equal size.
+	RangePartition Partition = iota
+	// HashPartition assigns each constraint to a shard by the hash of its
+	// canonical form, so identical constraints always share a shard.
+	HashPartition
+)
+
+func (p Partition) String() string {
+	switch p {
+	case RangePartition:
+		return "range"
+	case HashPartition:
+		return "hash"
+	}
+	return "unknown"
+}
+
+// Manifest describes the output of WriteShards.
+type Manifest struct {
+	Partition   string      `json:"partition"`
+	Constraints int         `json:"constraints"`
+	Shards      []ShardInfo `json:"shards"`
+}
+
+// ShardInfo describes one shard of the output.
+type ShardInfo struct {
+	// Start is the index of the first constraint in the shard when the
+	// constraints are partitioned by range, and zero otherwise.
+	Start       int   `json:"start"`
+	Constraints int   `json:"constraints"`
+	Bytes       int64 `json:"bytes"` // before compression
+}
+
+// WriteJSON writes the manifest to w as JSON.
+func (m *Manifest) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(m)
+}
+
+// WriteShards writes the constraints across the writers, one shard per
+// writer, in the same form as WriteConstraintsTo. Variables are indexed and
+// ordered over all of the constraints, so the shards are consistent with each
+// other, and within each shard constraints keep their original order. Each
+// shard starts with the comments, the variable attributes, and the map of
+// the names sanitized by the Names option. The Cache, Indexer,
+// IndexWorkers, Report, ProfileLabels, Progress, Checkpoint, and Resume
+// options are ignored.
+func WriteShards(ws []io.Writer, cons []Constraint, part Partition, opts *Options) (_ *Manifest, err error) {
+	if len(ws) == 0 {
+		panic("lp: no shards")
+	}
+	if opts == nil {
+		opts = &Options{}
+	}
+	defer opts.recoverWrite(&err, cons)
+	if err := opts.Limits.check(cons); err != nil {
+		return nil, err
+	}
+	m := &Manifest{
+		Partition:   part.String(),
+		Constraints: len(cons),
+		Shards:      make([]ShardInfo, len(ws)),
+	}
+	outs := make([]io.WriteCloser, len(ws))
+	for i, w := range ws {
+		out, err := opts.output(w)
+		if err != nil {
+			return nil, err
+		}
+		outs[i] = out
+	}
+
+	names, nameMap := IndexVariables(cons)
+	SortVariables(names, nameMap, opts.Order)
+	// The renderer condenses with nameMap and never uses the cache, which
+	// is only swept by WriteConstraintsTo.
+	ropts := *opts
+	ropts.Indexer, ropts.Cache = nil, nil
+	r := NewRenderer(names, nameMap, nil, nil, nil, &ropts)
+
+	// Each shard starts with the comments and the map of sanitized names.
+	var header bytes.Buffer
+	if err = opts.writeComments(&header); err == nil {
+		r.names, err = opts.writeNames(&header, opts.commentPrefix(), names)
+	}
+	for k := range outs {
+		if err != nil {
+			break
+		}
+		_, err = outs[k].Write(header.Bytes())
+		m.Shards[k].Bytes = int64(header.Len())
+	}
+
+	var wt []float64
+	for i, c := range cons {
+		if err != nil {
+			break
+		}
+		wl, wr := r.condense(c)
+		var k int
+		switch part {
+		case RangePartition:
+			k = int(int64(i) * int64(len(ws)) / int64(len(cons)))
+			if m.Shards[k].Constraints == 0 {
+				m.Shards[k].Start = i
+			}
+		case HashPartition:
+			if wr == nil {
+				wt = wl
+			} else {
+				wt = append(wt[:0], wl...)
+				for j, v := range wr {
+					wt[j] -= v
+				}
+			}
+			k = int(CanonicalizeRow(wt).Hash() % uint64(len(ws)))
+		default:
+			panic("lp: unknown partition")
+		}
+		b := r.buf[:0]
+		if opts.Attributes != nil && !opts.TrailingAttributes {
+			b = opts.appendRowAttributes(b, i)
+		}
+		b = r.format(b, wl, wr)
+		if opts.Attributes != nil && opts.TrailingAttributes {
+			b = opts.appendTrailingAttributes(b, i)
+		}
+		r.buf = b
+		if _, err = outs[k].Write(b); err != nil {
+			break
+		}
+		m.Shards[k].Constraints++
+		m.Shards[k].Bytes += int64(len(b))
+	}
+	for _, out := range outs {
+		if cerr := out.Close(); err == nil {
+			err = cerr
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return m, nil
+}
@@ -0,0 +1,110 @@
+package benchlp
+
+import (
+	"bytes"
+	"io"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestWriteShards(t *testing.T) {
+	cons := randomConstraints(50, 1000)
+	var all bytes.Buffer
+	if err := WriteConstraintsTo(&all, cons, nil); err != nil {
+		t.Fatal(err)
+	}
+	wantLines := strings.SplitAfter(all.String(), "\n")
+	wantLines = wantLines[:len(wantLines)-1]
+
+	for _, part := range []Partition{RangePartition, HashPartition} {
+		bufs := make([]bytes.Buffer, 3)
+		ws := make([]io.Writer, len(bufs))
+		for i := range bufs {
+			ws[i] = &bufs[i]
+		}
+		m, err := WriteShards(ws, cons, part, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if m.Constraints != len(cons) || m.Partition != part.String() {
+			t.Errorf("%v: bad manifest %+v", part, m)
+		}
+		var gotLines []string
+		var total int
+		for i, buf := range bufs {
+			info := m.Shards[i]
+			if int64(buf.Len()) != info.Bytes {
+				t.Errorf("%v: shard %d has %d bytes, manifest says %d", part, i, buf.Len(), info.Bytes)
+			}
+			lines := strings.SplitAfter(buf.String(), "\n")
+			lines = lines[:len(lines)-1]
+			if len(lines) != info.Constraints {
+				t.Errorf("%v: shard %d has %d constraints, manifest says %d", part, i, len(lines), info.Constraints)
+			}
+			if part == RangePartition {
+				for j, l := range lines {
+					if l != wantLines[info.Start+j] {
+						t.Errorf("%v: shard %d line %d differs", part, i, j)
+						break
+					}
+				}
+			}
+			total += info.Constraints
+			gotLines = append(gotLines, lines...)
+		}
+		if total != len(cons) {
+			t.Errorf("%v: shards hold %d constraints, want %d", part, total, len(cons))
+		}
+		sort.Strings(gotLines)
+		want := append([]string(nil), wantLines...)
+		sort.Strings(want)
+		if strings.Join(gotLines, "") != strings.Join(want, "") {
+			t.Errorf("%v: shards do not contain the same constraints", part)
+		}
+	}
+}
+
+func TestWriteShardsOptions(t *testing.T) {
+	cons := []Constraint{
+		{Left: []Term{{"a b", 1}, {"c", 2}}, Right: []Term{{"c", 1}}},
+		{Left: []Term{{"c", 1}}, Right: []Term{{"a b", 3}}},
+	}
+	var attrs Attributes
+	attrs.SetVar("c", "kind", "integer")
+	attrs.SetRow(1, "source", "test")
+	for _, trailing := range []bool{false, true} {
+		rc := NewRenderCache()
+		opts := &Options{
+			TwoSided:           true,
+			Comments:           []string{"sharded"},
+			Names:              LPNames,
+			Attributes:         &attrs,
+			TrailingAttributes: trailing,
+			Cache:              rc,
+		}
+		var got, want bytes.Buffer
+		m, err := WriteShards([]io.Writer{&got}, cons, RangePartition, opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := WriteConstraintsTo(&want, cons, &Options{
+			TwoSided:           true,
+			Comments:           []string{"sharded"},
+			Names:              LPNames,
+			Attributes:         &attrs,
+			TrailingAttributes: trailing,
+		}); err != nil {
+			t.Fatal(err)
+		}
+		if got.String() != want.String() {
+			t.Errorf("trailing %v: got\n%s\nwant\n%s", trailing, got.String(), want.String())
+		}
+		if m.Shards[0].Bytes != int64(got.Len()) {
+			t.Errorf("trailing %v: manifest has %d bytes, shard %d", trailing, m.Shards[0].Bytes, got.Len())
+		}
+		if rc.Len() != 0 {
+			t.Errorf("trailing %v: cache used", trailing)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+/*
+Copyright 2017 Brendan Tracey
+
+Redistribution and use in source and binary forms, with or without modification,
+are permitted provided that the following conditions are met:
+
+1. Redistributions of source code must retain the above copyright notice, this
+list of conditions and the following disclaimer.
+
+2. Redistributions in binary form must reproduce the above copyright notice,
+this list of conditions and the following disclaimer in the documentation and/or
+other materials provided with the distribution.
+
+3. Neither the name of the copyright holder nor the names of its contributors may
+be used to endorse or promote products derived from this software without specific
+prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED.
+IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT,
+INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF
+LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE
+OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED
+OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package benchlp
+
+import "strconv"
+
+// SoftConstraint is a constraint that may be violated at a cost of Penalty
+// per unit of violation.
+type SoftConstraint struct {
+	Constraint
+	Penalty float64
+}
+
+// Soften reformulates the soft constraints with slack variables. Soft
+// constraint i gets the slack variable prefix+i, and becomes the constraints
+//
+//	Left <= Right + slack,  0 <= slack
+//
+// so that the slack is at least the violation. The returned penalty terms,
+// Penalty*slack for each constraint, must be added to a minimized objective,
+// which this package does not represent, to make the slacks equal to the
+// violations at an optimum.
+func Soften(soft []SoftConstraint, prefix string) (cons []Constraint, penalty []Term) {
+	cons = make([]Constraint, 0, 2*len(soft))
+	penalty = make([]Term, len(soft))
+	for i, s := range soft {
+		slack := prefix + strconv.Itoa(i)
+		right := append(copyTerms(s.Right, 1), Term{slack, 1})
+		cons = append(cons,
+			Constraint{Left: copyTerms(s.Left, 1), Right: right},
+			Constraint{Right: []Term{{slack, 1}}},
+		)
+		penalty[i] = Term{slack, s.Penalty}
+	}
+	return cons, penalty
+}
@@ -0,0 +1,43 @@
+package benchlp
+
+import "testing"
+
+func TestSoften(t *testing.T) {
+	soft := []SoftConstraint{
+		{Constraint: Constraint{Left: []Term{{"x", 1}}, Right: []Term{{"y", 1}}}, Penalty: 10},
+		{Constraint: Constraint{Left: []Term{{"y", 2}}}, Penalty: 1},
+	}
+	cons, penalty := Soften(soft, "s")
+	if len(cons) != 4 {
+		t.Fatalf("got %d constraints, want 4", len(cons))
+	}
+	want := []Term{{"s0", 10}, {"s1", 1}}
+	if len(penalty) != len(want) || penalty[0] != want[0] || penalty[1] != want[1] {
+		t.Errorf("got penalty %v, want %v", penalty, want)
+	}
+
+	x := map[string]float64{"x": 3, "y": 1} // violations 2 and 2
+	for _, test := range []struct {
+		s0, s1 float64
+		ok     bool
+	}{
+		{2, 2, true},
+		{5, 3, true},
+		{1.5, 2, false},
+		{2, 1, false},
+		{-1, 2, false},
+	} {
+		x["s0"], x["s1"] = test.s0, test.s1
+		if satisfied(cons, x) != test.ok {
+			t.Errorf("slacks %v, %v: satisfied %t", test.s0, test.s1, !test.ok)
+		}
+	}
+	// With x and y zero, both constraints hold without slack.
+	x = map[string]float64{"x": 0, "y": 0, "s0": 0, "s1": 0}
+	if !satisfied(cons, x) {
+		t.Errorf("zero slacks do not satisfy satisfied constraints")
+	}
+	if len(soft[0].Right) != 1 {
+		t.Errorf("input modified")
+	}
+}
@@ -0,0 +1,195 @@
+/*
+Copyright 2017 Brendan Tracey
+
+Redistribution and use in source and binary forms, with or without modification,
+are permitted provided that the following conditions are met:
+
+1. Redistributions of source code must retain the above copyright notice, this
+list of conditions and the following disclaimer.
+
+2. Redistributions in binary form must reproduce the above copyright notice,
+this list of conditions and the following disclaimer in the documentation and/or
+other materials provided with the distribution.
+
+3. Neither the name of the copyright holder nor the names of its contributors may
+be used to endorse or promote products derived from this software without specific
+prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED.
+IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT,
+INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF
+LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE
+OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED
+OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package benchlp
+
+import (
+	"bufio"
+	"encoding/xml"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Solution is a solution returned by a solver, with variable values keyed by
+// the variable names used when writing the constraints. Duals, if not nil,
+// holds the dual value of each constraint, by index, in the sign convention
+// of VerifySolution.
+type Solution struct {
+	Objective float64
+	Values    map[string]float64
+	Duals     []float64
+}
+
+// ReadSolution reads a solution in the text format written by Gurobi and
+// SCIP. Each line holds a variable name followed by its value, and anything
+// after the value, such as SCIP's objective coefficient, is ignored. The
+// objective is read from a Gurobi "# Objective value = v" comment or a SCIP
+// "objective value: v" line, and other comments and the SCIP status line are
+// skipped. Variables that are not listed have the value zero.
+func ReadSolution(r io.Reader) (*Solution, error) {
+	sol := &Solution{Values: make(map[string]float64)}
+	sc := bufio.NewScanner(r)
+	var line int
+	for sc.Scan() {
+		line++
+		text := strings.TrimSpace(sc.Text())
+		lower := strings.ToLower(text)
+		var obj string
+		switch {
+		case text == "":
+			continue
+		case strings.HasPrefix(text, "#"):
+			i := strings.Index(lower, "objective value")
+			if i < 0 {
+				continue
+			}
+			obj = strings.TrimLeft(text[i+len("objective value"):], " =:")
+		case strings.HasPrefix(lower, "solution status:"):
+			continue
+		case strings.HasPrefix(lower, "objective value:"):
+			obj = text[len("objective value:"):]
+		}
+		if obj != "" {
+			v, err := strconv.ParseFloat(strings.TrimSpace(obj), 64)
+			if err != nil {
+				return nil, &ParseError{Line: line, Err: errSyntax}
+			}
+			sol.Objective = v
+			continue
+		}
+		fields := strings.Fields(text)
+		if len(fields) < 2 {
+			return nil, &ParseError{Line: line, Err: errSyntax}
+		}
+		v, err := strconv.ParseFloat(fields[1], 64)
+		if err != nil {
+			return nil, &ParseError{Line: line, Err: errSyntax}
+		}
+		sol.Values[fields[0]] = v
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return sol, nil
+}
+
+// errNoCPLEXSolution is returned when XML input is not a CPLEX solution.
+var errNoCPLEXSolution = errors.New("lp: not a CPLEX solution file")
+
+// ReadCPLEXSolution reads a solution in the XML format written by CPLEX.
+// Only the objective value and the variable values are read.
+func ReadCPLEXSolution(r io.Reader) (*Solution, error) {
+	var doc struct {
+		XMLName xml.Name `xml:"CPLEXSolution"`
+		Header  struct {
+			Objective float64 `xml:"objectiveValue,attr"`
+		} `xml:"header"`
+		Variables []struct {
+			Name  string  `xml:"name,attr"`
+			Value float64 `xml:"value,attr"`
+		} `xml:"variables>variable"`
+	}
+	if err := xml.NewDecoder(r).Decode(&doc); err != nil {
+		if _, ok := err.(xml.UnmarshalError); ok {
+			return nil, errNoCPLEXSolution
+		}
+		return nil, err
+	}
+	sol := &Solution{
+		Objective: doc.Header.Objective,
+		Values:    make(map[string]float64, len(doc.Variables)),
+	}
+	for _, v := range doc.Variables {
+		sol.Values[v.Name] = v.Value
+	}
+	return sol, nil
+}
+
+// ReadHiGHSSolution reads a solution in the raw format written by HiGHS with
+// --solution_file: the objective and the column values of the primal
+// solution, and the row values of the dual solution as Duals, which for a
+// minimization are in the sign convention of VerifySolution. A solution
+// that HiGHS lists as None has no values, and the basis is not read.
+func ReadHiGHSSolution(r io.Reader) (*Solution, error) {
+	sol := &Solution{Values: make(map[string]float64)}
+	sc := bufio.NewScanner(r)
+	var line int
+	var primal, dual bool
+	for sc.Scan() {
+		line++
+		text := strings.TrimSpace(sc.Text())
+		switch {
+		case text == "# Primal solution values":
+			primal, dual = true, false
+		case text == "# Dual solution values":
+			primal, dual = false, true
+		case text == "# Basis":
+			return sol, nil
+		case primal && strings.HasPrefix(text, "Objective "):
+			v, err := strconv.ParseFloat(strings.TrimSpace(text[len("Objective "):]), 64)
+			if err != nil {
+				return nil, &ParseError{Line: line, Err: errSyntax}
+			}
+			sol.Objective = v
+		case strings.HasPrefix(text, "# Columns "), strings.HasPrefix(text, "# Rows "):
+			fields := strings.Fields(text)
+			n, err := strconv.Atoi(fields[len(fields)-1])
+			if err != nil || n < 0 {
+				return nil, &ParseError{Line: line, Err: errSyntax}
+			}
+			columns := fields[1] == "Columns"
+			for i := 0; i < n; i++ {
+				if !sc.Scan() {
+					return nil, &ParseError{Line: line, Err: io.ErrUnexpectedEOF}
+				}
+				line++
+				fields := strings.Fields(sc.Text())
+				if len(fields) < 2 {
+					return nil, &ParseError{Line: line, Err: errSyntax}
+				}
+				v, err := strconv.ParseFloat(fields[1], 64)
+				if err != nil {
+					return nil, &ParseError{Line: line, Err: errSyntax}
+				}
+				switch {
+				case primal && columns:
+					sol.Values[fields[0]] = v
+				case dual && !columns:
+					sol.Duals = append(sol.Duals, v)
+				}
+			}
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return sol, nil
+}
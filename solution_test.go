@@ -0,0 +1,101 @@
+package benchlp
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReadSolution(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		in   string
+	}{
+		{"gurobi", "# Solution for model benchlp\n# Objective value = 2.5\nx 1\ny -0.5\nz 0\n"},
+		{"scip", "solution status: optimal solution found\n" +
+			"objective value:                    2.5\n" +
+			"x                                   1 \t(obj:2)\n" +
+			"y                                -0.5 \t(obj:-1)\n"},
+	} {
+		sol, err := ReadSolution(strings.NewReader(test.in))
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		if sol.Objective != 2.5 {
+			t.Errorf("%s: objective %v, want 2.5", test.name, sol.Objective)
+		}
+		if sol.Values["x"] != 1 || sol.Values["y"] != -0.5 || sol.Values["z"] != 0 {
+			t.Errorf("%s: values %v", test.name, sol.Values)
+		}
+	}
+
+	for _, in := range []string{"x\n", "x one\n", "# Objective value = many\n"} {
+		if _, err := ReadSolution(strings.NewReader(in)); err == nil {
+			t.Errorf("no error reading %q", in)
+		}
+	}
+}
+
+func TestReadCPLEXSolution(t *testing.T) {
+	in := `<?xml version = "1.0" encoding="UTF-8" standalone="yes"?>
+<CPLEXSolution version="1.2">
+ <header problemName="benchlp.lp" objectiveValue="2.5" solutionStatusString="optimal"/>
+ <quality epRHS="1e-06"/>
+ <linearConstraints>
+  <constraint name="c1" index="0" slack="0"/>
+ </linearConstraints>
+ <variables>
+  <variable name="x" index="0" value="1"/>
+  <variable name="y" index="1" value="-0.5"/>
+ </variables>
+</CPLEXSolution>
+`
+	sol, err := ReadCPLEXSolution(strings.NewReader(in))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &Solution{Objective: 2.5, Values: map[string]float64{"x": 1, "y": -0.5}}
+	if !reflect.DeepEqual(sol, want) {
+		t.Errorf("got %+v, want %+v", sol, want)
+	}
+	if _, err := ReadCPLEXSolution(strings.NewReader("<other/>")); err != errNoCPLEXSolution {
+		t.Errorf("got error %v for other XML", err)
+	}
+}
+
+func TestReadHiGHSSolution(t *testing.T) {
+	in := "Model status\nOptimal\n\n" +
+		"# Primal solution values\nFeasible\nObjective 2.5\n" +
+		"# Columns 2\nx 1\ny -0.5\n# Rows 2\nc0 0.5\nc1 -1\n\n" +
+		"# Dual solution values\nFeasible\n" +
+		"# Columns 2\nx 0\ny 0\n# Rows 2\nc0 -2\nc1 0\n\n" +
+		"# Basis\nHiGHS_basis_file v2\nValid\n# Columns 2\n1 1\n# Rows 2\n0 1\n"
+	sol, err := ReadHiGHSSolution(strings.NewReader(in))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &Solution{Objective: 2.5, Values: map[string]float64{"x": 1, "y": -0.5}, Duals: []float64{-2, 0}}
+	if !reflect.DeepEqual(sol, want) {
+		t.Errorf("got %+v, want %+v", sol, want)
+	}
+
+	in = "Model status\nInfeasible\n\n# Primal solution values\nNone\n# Dual solution values\nNone\n# Basis\nHiGHS v1\nNone\n"
+	sol, err = ReadHiGHSSolution(strings.NewReader(in))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sol.Values) != 0 || sol.Duals != nil {
+		t.Errorf("infeasible: got %+v", sol)
+	}
+
+	for _, in := range []string{
+		"# Primal solution values\n# Columns 2\nx 1\n",
+		"# Primal solution values\n# Columns 1\nx one\n",
+		"# Primal solution values\nObjective -\n",
+	} {
+		if _, err := ReadHiGHSSolution(strings.NewReader(in)); err == nil {
+			t.Errorf("no error reading %q", in)
+		}
+	}
+}
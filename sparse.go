@@ -0,0 +1,310 @@
+/*
+Copyright 2017 Brendan Tracey
+
+Redistribution and use in source and binary forms, with or without modification,
+are permitted provided that the following conditions are met:
+
+1. Redistributions of source code must retain the above copyright notice, this
+list of conditions and the following disclaimer.
+
+2. Redistributions in binary form must reproduce the above copyright notice,
+this list of conditions and the following disclaimer in the documentation and/or
+other materials provided with the distribution.
+
+3. Neither the name of the copyright holder nor the names of its contributors may
+be used to endorse or promote products derived from this software without specific
+prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED.
+IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT,
+INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF
+LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE
+OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED
+OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package benchlp
+
+import "sort"
+
+// SparseMatrix is a matrix in compressed sparse row (CSR) or compressed
+// sparse column (CSC) form, following the conventions of SciPy and gonum.
+// For CSR, the column indices and values of row i are
+//
+//	Index[Ptr[i]:Ptr[i+1]] and Value[Ptr[i]:Ptr[i+1]]
+//
+// and CSC is the same with rows and columns exchanged. Indices within each
+// row (or column) are increasing and there are no explicit zeros.
+type SparseMatrix struct {
+	Rows, Cols int
+	Ptr        []int
+	Index      []int
+	Value      []float64
+}
+
+// CSR returns the condensed coefficient matrix of the constraints in CSR
+// form. Row i is constraint i, and column j is variable names[j], with
+// variables indexed as by IndexVariables.
+func CSR(cons []Constraint) (names []string, m SparseMatrix) {
+	names, nameMap := IndexVariables(cons)
+	m = SparseMatrix{
+		Rows: len(cons),
+		Cols: len(names),
+		Ptr:  make([]int, 1, len(cons)+1),
+	}
+	wl := make([]float64, len(names))
+	wr := make([]float64, len(names))
+	for _, c := range cons {
+		start := len(m.Index)
+		sparseCondense(wl, wr, c, nameMap, func(j int, v float64) {
+			m.Index = append(m.Index, j)
+			m.Value = append(m.Value, v)
+		})
+		sort.Sort(sparseEntries{m.Index[start:], m.Value[start:]})
+		m.Ptr = append(m.Ptr, len(m.Index))
+	}
+	return names, m
+}
+
+// CSC returns the condensed coefficient matrix of the constraints in CSC
+// form, with rows and columns as for CSR.
+func CSC(cons []Constraint) (names []string, m SparseMatrix) {
+	names, csr := CSR(cons)
+	return names, csrToCSC(csr)
+}
+
+// DenseMatrix returns the condensed coefficient matrix of the constraints as
+// a dense row-major slice, with row i constraint i and column j variable
+// names[j], indexed as by CSR. It is meant for models small enough to be
+// stored densely, and
+//
+//	mat.NewDense(len(cons), len(names), data)
+//
+// gives a gonum matrix. The right hand side is zero and is not returned.
+func DenseMatrix(cons []Constraint) (names []string, data []float64) {
+	names, nameMap := IndexVariables(cons)
+	n := len(names)
+	data = make([]float64, len(cons)*n)
+	wr := make([]float64, n)
+	for i, c := range cons {
+		CondenseConstraint(data[i*n:(i+1)*n], wr, c, nameMap)
+	}
+	return names, data
+}
+
+// csrToCSC returns the CSC form of the CSR matrix m.
+func csrToCSC(m SparseMatrix) SparseMatrix {
+	n := m.Cols
+	t := SparseMatrix{
+		Rows:  m.Rows,
+		Cols:  m.Cols,
+		Ptr:   make([]int, n+1),
+		Index: make([]int, len(m.Index)),
+		Value: make([]float64, len(m.Value)),
+	}
+	for _, j := range m.Index {
+		t.Ptr[j+1]++
+	}
+	for j := 0; j < n; j++ {
+		t.Ptr[j+1] += t.Ptr[j]
+	}
+	next := append([]int(nil), t.Ptr[:n]...)
+	for i := 0; i+1 < len(m.Ptr); i++ {
+		for k := m.Ptr[i]; k < m.Ptr[i+1]; k++ {
+			j := m.Index[k]
+			t.Index[next[j]] = i
+			t.Value[next[j]] = m.Value[k]
+			next[j]++
+		}
+	}
+	return t
+}
+
+// sparseEntries sorts indices and their values together.
+type sparseEntries struct {
+	index []int
+	value []float64
+}
+
+func (s sparseEntries) Len() int           { return len(s.index) }
+func (s sparseEntries) Less(i, j int) bool { return s.index[i] < s.index[j] }
+func (s sparseEntries) Swap(i, j int) {
+	s.index[i], s.index[j] = s.index[j], s.index[i]
+	s.value[i], s.value[j] = s.value[j], s.value[i]
+}
+
+// Transpose returns the transpose of the CSR matrix m, in CSR form.
+func (m SparseMatrix) Transpose() SparseMatrix {
+	t := csrToCSC(m)
+	t.Rows, t.Cols = m.Cols, m.Rows
+	return t
+}
+
+// SubMatrix returns the submatrix of the CSR matrix m with the given rows and
+// columns, in CSR form. Row k of the result is row rows[k] of m, and column l
+// is column cols[l]. It panics if an index is out of range or a column is
+// repeated.
+func (m SparseMatrix) SubMatrix(rows, cols []int) SparseMatrix {
+	colMap := make([]int, m.Cols)
+	for j := range colMap {
+		colMap[j] = -1
+	}
+	for l, j := range cols {
+		if colMap[j] >= 0 {
+			panic("lp: repeated column")
+		}
+		colMap[j] = l
+	}
+	sub := SparseMatrix{
+		Rows: len(rows),
+		Cols: len(cols),
+		Ptr:  make([]int, 1, len(rows)+1),
+	}
+	for _, i := range rows {
+		start := len(sub.Index)
+		for k := m.Ptr[i]; k < m.Ptr[i+1]; k++ {
+			if l := colMap[m.Index[k]]; l >= 0 {
+				sub.Index = append(sub.Index, l)
+				sub.Value = append(sub.Value, m.Value[k])
+			}
+		}
+		sort.Sort(sparseEntries{sub.Index[start:], sub.Value[start:]})
+		sub.Ptr = append(sub.Ptr, len(sub.Index))
+	}
+	return sub
+}
+
+// SubConstraints returns the constraints with the given indices, keeping only
+// the terms whose variables are in vars, for example to separate the master
+// and subproblem rows of a decomposition. The constraints are not condensed.
+func SubConstraints(cons []Constraint, rows []int, vars []string) []Constraint {
+	keep := make(map[string]bool, len(vars))
+	for _, v := range vars {
+		keep[v] = true
+	}
+	filter := func(terms []Term) []Term {
+		var out []Term
+		for _, term := range terms {
+			if keep[term.Var] {
+				out = append(out, term)
+			}
+		}
+		return out
+	}
+	sub := make([]Constraint, len(rows))
+	for k, i := range rows {
+		sub[k] = Constraint{Left: filter(cons[i].Left), Right: filter(cons[i].Right)}
+	}
+	return sub
+}
+
+// PartitionRows splits the rows of the constraints for a decomposition in
+// which the variables in masterVars are the master, or complicating,
+// variables, as in Benders decomposition. The master rows use only master
+// variables, and the subproblem rows use at least one other variable.
+// Terms are not condensed, so a row whose subproblem terms cancel is still a
+// subproblem row.
+func PartitionRows(cons []Constraint, masterVars []string) (master, sub []int) {
+	isMaster := make(map[string]bool, len(masterVars))
+	for _, v := range masterVars {
+		isMaster[v] = true
+	}
+	for i, c := range cons {
+		inSub := false
+		for _, terms := range [2][]Term{c.Left, c.Right} {
+			for _, term := range terms {
+				if !isMaster[term.Var] {
+					inSub = true
+				}
+			}
+		}
+		if inSub {
+			sub = append(sub, i)
+		} else {
+			master = append(master, i)
+		}
+	}
+	return master, sub
+}
+
+// FixVariables returns the constraints with the variables in values fixed to
+// their values, each term of a fixed variable replaced by a term of One,
+// such as to form the subproblem of a decomposition for given master values.
+// The constraints are not condensed.
+func FixVariables(cons []Constraint, values map[string]float64) []Constraint {
+	fix := func(terms []Term) []Term {
+		if terms == nil {
+			return nil
+		}
+		out := make([]Term, len(terms))
+		for i, term := range terms {
+			if v, ok := values[term.Var]; ok {
+				term = Term{One, term.Value * v}
+			}
+			out[i] = term
+		}
+		return out
+	}
+	fixed := make([]Constraint, len(cons))
+	for i, c := range cons {
+		fixed[i] = Constraint{Left: fix(c.Left), Right: fix(c.Right)}
+	}
+	return fixed
+}
+
+// FilterConstraints returns the constraints for which keep returns true, and
+// their indices in cons, such as to write only the capacity constraints of a
+// model. The constraints are shared with cons.
+func FilterConstraints(cons []Constraint, keep func(Constraint) bool) (sub []Constraint, rows []int) {
+	for i, c := range cons {
+		if keep(c) {
+			sub = append(sub, c)
+			rows = append(rows, i)
+		}
+	}
+	return sub, rows
+}
+
+// ProjectConstraints returns the constraints that reference at least one of
+// the variables in vars, and their indices in cons, such as to write only the
+// rows touching one facility. The terms of the other variables are replaced
+// by terms of One if the variable has a value in fixed, as by FixVariables,
+// and are dropped otherwise, as by SubConstraints. One is never dropped. The
+// constraints are not condensed.
+func ProjectConstraints(cons []Constraint, vars []string, fixed map[string]float64) (sub []Constraint, rows []int) {
+	keep := make(map[string]bool, len(vars)+1)
+	for _, v := range vars {
+		keep[v] = true
+	}
+	keep[One] = true
+	project := func(terms []Term) []Term {
+		var out []Term
+		for _, term := range terms {
+			if keep[term.Var] {
+				out = append(out, term)
+			} else if v, ok := fixed[term.Var]; ok {
+				out = append(out, Term{One, term.Value * v})
+			}
+		}
+		return out
+	}
+	for i, c := range cons {
+		uses := false
+		for _, terms := range [2][]Term{c.Left, c.Right} {
+			for _, term := range terms {
+				if term.Var != One && keep[term.Var] {
+					uses = true
+				}
+			}
+		}
+		if uses {
+			sub = append(sub, Constraint{Left: project(c.Left), Right: project(c.Right)})
+			rows = append(rows, i)
+		}
+	}
+	return sub, rows
+}
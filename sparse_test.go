@@ -0,0 +1,208 @@
+package benchlp
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestCSR(t *testing.T) {
+	cons := []Constraint{
+		{Left: []Term{{"a", 1}}, Right: []Term{{"c", 3}}},
+		{Left: []Term{{"c", 2}, {"b", 1}, {"a", 1}}, Right: []Term{{"a", 1}}},
+		{},
+		{Left: []Term{{"b", 5}}},
+	}
+	names, csr := CSR(cons)
+	if want := []string{"a", "c", "b"}; !reflect.DeepEqual(names, want) {
+		t.Fatalf("names: got %v, want %v", names, want)
+	}
+	want := SparseMatrix{
+		Rows:  4,
+		Cols:  3,
+		Ptr:   []int{0, 2, 4, 4, 5},
+		Index: []int{0, 1, 1, 2, 2},
+		Value: []float64{1, -3, 2, 1, 5},
+	}
+	if !reflect.DeepEqual(csr, want) {
+		t.Errorf("CSR: got %+v, want %+v", csr, want)
+	}
+
+	_, csc := CSC(cons)
+	want = SparseMatrix{
+		Rows:  4,
+		Cols:  3,
+		Ptr:   []int{0, 1, 3, 5},
+		Index: []int{0, 0, 1, 1, 3},
+		Value: []float64{1, -3, 2, 1, 5},
+	}
+	if !reflect.DeepEqual(csc, want) {
+		t.Errorf("CSC: got %+v, want %+v", csc, want)
+	}
+
+	// The CSC form must agree with the columns.
+	cons = randomConstraints(50, 100)
+	_, csc = CSC(cons)
+	_, cols := ToColumns(cons)
+	for j, col := range cols {
+		lo, hi := csc.Ptr[j], csc.Ptr[j+1]
+		if !reflect.DeepEqual(csc.Index[lo:hi], col.Row) || !reflect.DeepEqual(csc.Value[lo:hi], col.Value) {
+			t.Fatalf("column %d of CSC does not match ToColumns", j)
+		}
+	}
+}
+
+func TestDenseMatrix(t *testing.T) {
+	cons := []Constraint{
+		{Left: []Term{{"a", 1}}, Right: []Term{{"c", 3}}},
+		{Left: []Term{{"c", 2}, {"b", 1}, {"a", 1}}, Right: []Term{{"a", 1}}},
+		{},
+		{Left: []Term{{"b", 5}}},
+	}
+	names, data := DenseMatrix(cons)
+	if want := []string{"a", "c", "b"}; !reflect.DeepEqual(names, want) {
+		t.Fatalf("names: got %v, want %v", names, want)
+	}
+	want := []float64{
+		1, -3, 0,
+		0, 2, 1,
+		0, 0, 0,
+		0, 0, 5,
+	}
+	if !reflect.DeepEqual(data, want) {
+		t.Errorf("got %v, want %v", data, want)
+	}
+}
+
+func TestCSRMatchesDense(t *testing.T) {
+	// The sides sum to the same value, but not when their terms are
+	// combined in one accumulator.
+	x, y := 0.1, 0.2
+	cons := []Constraint{
+		{Left: []Term{{"a", x + y}, {"b", 1}}, Right: []Term{{"a", x}, {"a", y}}},
+	}
+	rnd := rand.New(rand.NewSource(1))
+	vars := []string{"a", "b", "c", "d"}
+	for i := 0; i < 200; i++ {
+		var c Constraint
+		for k := 0; k < 6; k++ {
+			term := Term{vars[rnd.Intn(len(vars))], rnd.Float64()}
+			if rnd.Intn(2) == 0 {
+				c.Left = append(c.Left, term)
+			} else {
+				c.Right = append(c.Right, term)
+			}
+		}
+		cons = append(cons, c)
+	}
+
+	names, dense := DenseMatrix(cons)
+	_, csr := CSR(cons)
+	got := make([]float64, len(dense))
+	for i := 0; i < csr.Rows; i++ {
+		for k := csr.Ptr[i]; k < csr.Ptr[i+1]; k++ {
+			got[i*len(names)+csr.Index[k]] = csr.Value[k]
+		}
+	}
+	if !reflect.DeepEqual(got, dense) {
+		t.Errorf("CSR does not match DenseMatrix")
+	}
+	if csr.Ptr[1] != 1 {
+		t.Errorf("cancelling row: got %d entries, want 1", csr.Ptr[1])
+	}
+}
+
+func TestSubMatrix(t *testing.T) {
+	cons := []Constraint{
+		{Left: []Term{{"a", 1}, {"b", 2}, {"c", 3}}},
+		{Left: []Term{{"b", 4}}},
+		{Left: []Term{{"a", 5}, {"c", 6}}},
+	}
+	_, csr := CSR(cons)
+
+	tr := csr.Transpose()
+	want := SparseMatrix{
+		Rows:  3,
+		Cols:  3,
+		Ptr:   []int{0, 2, 4, 6},
+		Index: []int{0, 2, 0, 1, 0, 2},
+		Value: []float64{1, 5, 2, 4, 3, 6},
+	}
+	if !reflect.DeepEqual(tr, want) {
+		t.Errorf("transpose: got %+v, want %+v", tr, want)
+	}
+	if !reflect.DeepEqual(tr.Transpose(), csr) {
+		t.Errorf("double transpose differs")
+	}
+
+	sub := csr.SubMatrix([]int{2, 0}, []int{2, 0})
+	want = SparseMatrix{
+		Rows:  2,
+		Cols:  2,
+		Ptr:   []int{0, 2, 4},
+		Index: []int{0, 1, 0, 1},
+		Value: []float64{6, 5, 3, 1},
+	}
+	if !reflect.DeepEqual(sub, want) {
+		t.Errorf("submatrix: got %+v, want %+v", sub, want)
+	}
+
+	subCons := SubConstraints(cons, []int{2, 0}, []string{"c", "a"})
+	_, got := CSR(subCons)
+	if !reflect.DeepEqual(got, SparseMatrix{
+		Rows:  2,
+		Cols:  2,
+		Ptr:   []int{0, 2, 4},
+		Index: []int{0, 1, 0, 1},
+		Value: []float64{5, 6, 1, 3},
+	}) {
+		t.Errorf("sub-constraints: got %+v", got)
+	}
+}
+
+func TestPartitionRows(t *testing.T) {
+	cons := []Constraint{
+		{Left: []Term{{"y", 1}}, Right: []Term{{One, 4}}},              // master
+		{Left: []Term{{"x", 2}}, Right: []Term{{"y", 3}}},              // linking
+		{Left: []Term{{"x", 1}, {"z", 1}}},                             // subproblem
+		{Left: []Term{{"y", 1}, {"x", 1}, {"x", -1}}, Right: []Term{}}, // cancelling
+	}
+	master, sub := PartitionRows(cons, []string{"y", One})
+	if !reflect.DeepEqual(master, []int{0}) || !reflect.DeepEqual(sub, []int{1, 2, 3}) {
+		t.Errorf("got master %v and subproblem %v", master, sub)
+	}
+
+	fixed := FixVariables(SubConstraints(cons, []int{1, 2}, []string{"x", "y", "z", One}), map[string]float64{"y": 2})
+	x := map[string]float64{"x": 3, "z": -3}
+	if !satisfied(fixed, x) {
+		t.Errorf("x = 3 does not satisfy 2x <= 3y with y = 2")
+	}
+	x["x"] = 3.5
+	if satisfied(fixed, x) {
+		t.Errorf("x = 3.5 satisfies 2x <= 3y with y = 2")
+	}
+	if cons[1].Right[0].Var != "y" {
+		t.Errorf("input modified")
+	}
+}
+
+func TestFilterProjectConstraints(t *testing.T) {
+	cons := []Constraint{
+		{Left: []Term{{"x", 1}, {"y", 1}}, Right: []Term{{One, 4}}},
+		{Left: []Term{{"y", 2}}, Right: []Term{{"z", 3}}},
+		{Left: []Term{{"z", 1}}, Right: []Term{{One, 1}}},
+	}
+	sub, rows := FilterConstraints(cons, func(c Constraint) bool { return len(c.Left) == 1 })
+	if !reflect.DeepEqual(rows, []int{1, 2}) || !reflect.DeepEqual(sub, cons[1:]) {
+		t.Errorf("filtered: got %+v at rows %v", sub, rows)
+	}
+
+	sub, rows = ProjectConstraints(cons, []string{"y"}, map[string]float64{"z": 2})
+	want := []Constraint{
+		{Left: []Term{{"y", 1}}, Right: []Term{{One, 4}}},
+		{Left: []Term{{"y", 2}}, Right: []Term{{One, 6}}},
+	}
+	if !reflect.DeepEqual(rows, []int{0, 1}) || !reflect.DeepEqual(sub, want) {
+		t.Errorf("projected: got %+v at rows %v, want %+v at rows [0 1]", sub, rows, want)
+	}
+}
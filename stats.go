@@ -0,0 +1,213 @@
+/*
+Copyright 2017 Brendan Tracey
+
+Redistribution and use in source and binary forms, with or without modification,
+are permitted provided that the following conditions are met:
+
+1. Redistributions of source code must retain the above copyright notice, this
+list of conditions and the following disclaimer.
+
+2. Redistributions in binary form must reproduce the above copyright notice,
+this list of conditions and the following disclaimer in the documentation and/or
+other materials provided with the distribution.
+
+3. Neither the name of the copyright holder nor the names of its contributors may
+be used to endorse or promote products derived from this software without specific
+prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED.
+IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT,
+INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF
+LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE
+OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED
+OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package benchlp
+
+import "math"
+
+// Stats summarizes a set of constraints.
+type Stats struct {
+	Vars        int
+	Constraints int
+	// Terms is the number of terms before condensing.
+	Terms int
+	// Nonzeros is the number of non-zero coefficients after condensing.
+	Nonzeros int
+	// Density is the fraction of the condensed matrix that is non-zero.
+	Density float64
+
+	// MinCoeff, MaxCoeff and MeanCoeff are the smallest, largest, and mean
+	// magnitudes of the non-zero condensed coefficients. They are zero if
+	// there are no non-zeros.
+	MinCoeff  float64
+	MaxCoeff  float64
+	MeanCoeff float64
+
+	// RowCounts is a histogram of non-zeros per row, where RowCounts[k]
+	// is the number of constraints with k non-zero coefficients.
+	RowCounts []int
+
+	// Empty lists the constraints with no non-zero coefficients, and
+	// Singleton lists those with exactly one. Both are degenerate, since
+	// an empty row is always satisfied and a singleton row only fixes the
+	// sign of its variable.
+	Empty     []int
+	Singleton []int
+}
+
+// ConstraintStats computes statistics of the constraints.
+func ConstraintStats(cons []Constraint) Stats {
+	names, nameMap := IndexVariables(cons)
+	c1 := make([]float64, len(names))
+	c2 := make([]float64, len(names))
+
+	s := Stats{
+		Vars:        len(names),
+		Constraints: len(cons),
+		MinCoeff:    math.Inf(1),
+	}
+	var sum float64
+	for i, c := range cons {
+		s.Terms += len(c.Left) + len(c.Right)
+		var n int
+		for _, v := range CondenseConstraint(c1, c2, c, nameMap) {
+			if v == 0 {
+				continue
+			}
+			n++
+			v = math.Abs(v)
+			s.MinCoeff = math.Min(s.MinCoeff, v)
+			s.MaxCoeff = math.Max(s.MaxCoeff, v)
+			sum += v
+		}
+		s.Nonzeros += n
+		for len(s.RowCounts) <= n {
+			s.RowCounts = append(s.RowCounts, 0)
+		}
+		s.RowCounts[n]++
+		switch n {
+		case 0:
+			s.Empty = append(s.Empty, i)
+		case 1:
+			s.Singleton = append(s.Singleton, i)
+		}
+	}
+	if s.Nonzeros == 0 {
+		s.MinCoeff = 0
+	} else {
+		s.MeanCoeff = sum / float64(s.Nonzeros)
+		s.Density = float64(s.Nonzeros) / (float64(s.Vars) * float64(s.Constraints))
+	}
+	return s
+}
+
+// StatsCollector computes the statistics of constraints one at a time, as
+// they are generated or read, so that constraints too many to hold in
+// memory can be summarized in the same pass that writes them. It also
+// counts the coefficient magnitudes by decade and the degree of each
+// variable. The memory used grows with the number of variables, and with
+// the number of empty and singleton rows, but not with the number of terms.
+// The zero value is an empty StatsCollector ready to use.
+type StatsCollector struct {
+	s      Stats
+	sum    float64
+	index  Index
+	wl, wr []float64
+	degree []int
+	decade map[int]int
+}
+
+// Add adds the constraint to the statistics.
+func (sc *StatsCollector) Add(c Constraint) {
+	if sc.decade == nil {
+		sc.decade = make(map[int]int)
+		sc.s.MinCoeff = math.Inf(1)
+	}
+	i := sc.s.Constraints
+	sc.s.Constraints++
+	sc.s.Terms += len(c.Left) + len(c.Right)
+	sc.index.AddConstraint(c)
+	names, nameMap := sc.index.Names()
+	for len(sc.wl) < len(names) {
+		sc.wl = append(sc.wl, 0)
+		sc.wr = append(sc.wr, 0)
+		sc.degree = append(sc.degree, 0)
+	}
+	var n int
+	sparseCondense(sc.wl, sc.wr, c, nameMap, func(j int, v float64) {
+		n++
+		v = math.Abs(v)
+		sc.s.MinCoeff = math.Min(sc.s.MinCoeff, v)
+		sc.s.MaxCoeff = math.Max(sc.s.MaxCoeff, v)
+		sc.sum += v
+		sc.degree[j]++
+		sc.decade[decade(v)]++
+	})
+	sc.s.Nonzeros += n
+	for len(sc.s.RowCounts) <= n {
+		sc.s.RowCounts = append(sc.s.RowCounts, 0)
+	}
+	sc.s.RowCounts[n]++
+	switch n {
+	case 0:
+		sc.s.Empty = append(sc.s.Empty, i)
+	case 1:
+		sc.s.Singleton = append(sc.s.Singleton, i)
+	}
+}
+
+// Stats returns the statistics of the constraints added so far, as
+// ConstraintStats does for all of them at once. The slices are shared with
+// sc and must not be modified.
+func (sc *StatsCollector) Stats() Stats {
+	s := sc.s
+	s.Vars = sc.index.Len()
+	if s.Nonzeros == 0 {
+		s.MinCoeff = 0
+	} else {
+		s.MeanCoeff = sc.sum / float64(s.Nonzeros)
+		s.Density = float64(s.Nonzeros) / (float64(s.Vars) * float64(s.Constraints))
+	}
+	return s
+}
+
+// Magnitudes returns a histogram of the magnitudes of the non-zero condensed
+// coefficients by decade, where the count of decade k is the number of
+// coefficients with 10^k <= |v| < 10^(k+1). A wide range of decades is a
+// sign of a badly scaled model.
+func (sc *StatsCollector) Magnitudes() map[int]int {
+	h := make(map[int]int, len(sc.decade))
+	for k, n := range sc.decade {
+		h[k] = n
+	}
+	return h
+}
+
+// Degree returns the number of constraints in which the variable has a
+// non-zero condensed coefficient.
+func (sc *StatsCollector) Degree(name string) int {
+	j, ok := sc.index.Index(name)
+	if !ok {
+		return 0
+	}
+	return sc.degree[j]
+}
+
+// decade returns the k with 10^k <= v < 10^(k+1), for positive finite v,
+// correcting the rounding of math.Log10 at powers of ten.
+func decade(v float64) int {
+	k := int(math.Floor(math.Log10(v)))
+	switch {
+	case math.Pow(10, float64(k+1)) <= v:
+		k++
+	case v < math.Pow(10, float64(k)):
+		k--
+	}
+	return k
+}
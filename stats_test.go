@@ -0,0 +1,74 @@
+package benchlp
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestConstraintStats(t *testing.T) {
+	cons := []Constraint{
+		{Left: []Term{{"a", 1}, {"b", -4}}, Right: []Term{{"c", 2}}},
+		{Left: []Term{{"a", 1}}, Right: []Term{{"a", 1}}},
+		{Left: []Term{{"b", 0.5}, {"b", 0.5}}},
+	}
+	got := ConstraintStats(cons)
+	want := Stats{
+		Vars:        3,
+		Constraints: 3,
+		Terms:       7,
+		Nonzeros:    4,
+		Density:     4.0 / 9,
+		MinCoeff:    1,
+		MaxCoeff:    4,
+		MeanCoeff:   2,
+		RowCounts:   []int{1, 1, 0, 1},
+		Empty:       []int{1},
+		Singleton:   []int{2},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestStatsCollector(t *testing.T) {
+	cons := []Constraint{
+		{Left: []Term{{"a", 1}, {"b", -4}}, Right: []Term{{"c", 2}}},
+		{Left: []Term{{"a", 1}}, Right: []Term{{"a", 1}}},
+		{Left: []Term{{"b", 0.5}, {"b", 0.5}}},
+		{Left: []Term{{"d", 0.02}}, Right: []Term{{"b", 300}}},
+		{Left: []Term{{"d", 1000}}},
+	}
+	var sc StatsCollector
+	if s := sc.Stats(); s.Constraints != 0 || s.MinCoeff != 0 {
+		t.Errorf("empty collector: got %+v", s)
+	}
+	for _, c := range cons {
+		sc.Add(c)
+	}
+	if got, want := sc.Stats(), ConstraintStats(cons); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+	if got, want := sc.Magnitudes(), map[int]int{-2: 1, 0: 4, 2: 1, 3: 1}; !reflect.DeepEqual(got, want) {
+		t.Errorf("magnitudes: got %v, want %v", got, want)
+	}
+	for name, want := range map[string]int{"a": 1, "b": 3, "c": 1, "d": 2, "e": 0} {
+		if got := sc.Degree(name); got != want {
+			t.Errorf("degree of %s: got %d, want %d", name, got, want)
+		}
+	}
+
+	cons = randomSparseConstraints(100, 500, 2)
+	sc = StatsCollector{}
+	for _, c := range cons {
+		sc.Add(c)
+	}
+	got, want := sc.Stats(), ConstraintStats(cons)
+	if math.Abs(got.MeanCoeff-want.MeanCoeff) > 1e-12*want.MeanCoeff {
+		t.Errorf("mean: got %v, want %v", got.MeanCoeff, want.MeanCoeff)
+	}
+	got.MeanCoeff = want.MeanCoeff
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("random constraints: got %+v, want %+v", got, want)
+	}
+}
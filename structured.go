@@ -0,0 +1,220 @@
+/*
+Copyright 2017 Brendan Tracey
+
+Redistribution and use in source and binary forms, with or without modification,
+are permitted provided that the following conditions are met:
+
+1. Redistributions of source code must retain the above copyright notice, this
+list of conditions and the following disclaimer.
+
+2. Redistributions in binary form must reproduce the above copyright notice,
+this list of conditions and the following disclaimer in the documentation and/or
+other materials provided with the distribution.
+
+3. Neither the name of the copyright holder nor the names of its contributors may
+be used to endorse or promote products derived from this software without specific
+prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED.
+IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT,
+INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF
+LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE
+OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED
+OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package benchlp
+
+import "strconv"
+
+// One is the name of the variable that carries the constant terms of the
+// constraints, such as those built by the structured generators. A constant
+// c is written as the term c*One, and the constraints have their usual
+// meaning when One is fixed to 1. WriteMPS writes the coefficients of One as
+// right hand sides, and the other formats write One as a variable.
+const One = "one"
+
+// Knapsack returns the single constraint sum_i w_i x_i <= C of a knapsack
+// problem with n items, where the weights are random integers in [1, 100] and
+// the capacity is half of the total weight. Item i is the variable "x_i".
+// The weights are drawn from rng with the seed, as are the random values of
+// the other structured generators.
+func Knapsack(n int, seed int64, rng RNG) []Constraint {
+	rnd := rng.source(seed)
+	c := Constraint{Left: make([]Term, n)}
+	var total float64
+	for i := range c.Left {
+		w := float64(rnd.Intn(100) + 1)
+		c.Left[i] = Term{indexedName("x", i), w}
+		total += w
+	}
+	c.Right = []Term{{One, total / 2}}
+	return []Constraint{c}
+}
+
+// Assignment returns the constraints of an n×n assignment problem, where
+// variable "x_i_j" assigns agent i to task j. Each agent does at most one
+// task, and each task is done by at least one agent.
+func Assignment(n int) []Constraint {
+	cons := make([]Constraint, 0, 2*n)
+	for i := 0; i < n; i++ {
+		c := Constraint{Right: []Term{{One, 1}}}
+		for j := 0; j < n; j++ {
+			c.Left = append(c.Left, Term{indexedName("x", i, j), 1})
+		}
+		cons = append(cons, c)
+	}
+	for j := 0; j < n; j++ {
+		c := Constraint{Left: []Term{{One, 1}}}
+		for i := 0; i < n; i++ {
+			c.Right = append(c.Right, Term{indexedName("x", i, j), 1})
+		}
+		cons = append(cons, c)
+	}
+	return cons
+}
+
+// Transportation returns the constraints of a transportation problem with m
+// sources and n destinations, where variable "x_i_j" is the amount shipped
+// from source i to destination j. The shipments from each source are at most
+// its supply, and the shipments to each destination are at least its demand.
+// Demands are random integers in [1, 100], and the supplies are random
+// integers scaled so that total supply exceeds total demand.
+func Transportation(m, n int, seed int64, rng RNG) []Constraint {
+	rnd := rng.source(seed)
+	demand := make([]float64, n)
+	var totalDemand float64
+	for j := range demand {
+		demand[j] = float64(rnd.Intn(100) + 1)
+		totalDemand += demand[j]
+	}
+	supply := make([]float64, m)
+	var totalSupply float64
+	for i := range supply {
+		supply[i] = float64(rnd.Intn(100) + 1)
+		totalSupply += supply[i]
+	}
+	for i := range supply {
+		supply[i] = float64(int(supply[i]*1.2*totalDemand/totalSupply) + 1)
+	}
+
+	cons := make([]Constraint, 0, m+n)
+	for i := 0; i < m; i++ {
+		c := Constraint{Right: []Term{{One, supply[i]}}}
+		for j := 0; j < n; j++ {
+			c.Left = append(c.Left, Term{indexedName("x", i, j), 1})
+		}
+		cons = append(cons, c)
+	}
+	for j := 0; j < n; j++ {
+		c := Constraint{Left: []Term{{One, demand[j]}}}
+		for i := 0; i < m; i++ {
+			c.Right = append(c.Right, Term{indexedName("x", i, j), 1})
+		}
+		cons = append(cons, c)
+	}
+	return cons
+}
+
+// SetCover returns the constraints of a set covering problem with the given
+// number of elements and sets, where variable "s_j" selects set j. Each set
+// contains each element with the given probability, and every element is
+// added to at least one set. There is one constraint per element requiring it
+// to be covered at least once.
+func SetCover(elems, sets int, density float64, seed int64, rng RNG) []Constraint {
+	rnd := rng.source(seed)
+	cons := make([]Constraint, elems)
+	for e := range cons {
+		c := Constraint{Left: []Term{{One, 1}}}
+		for j := 0; j < sets; j++ {
+			if rnd.Float64() < density {
+				c.Right = append(c.Right, Term{indexedName("s", j), 1})
+			}
+		}
+		if len(c.Right) == 0 {
+			c.Right = []Term{{indexedName("s", rnd.Intn(sets)), 1}}
+		}
+		cons[e] = c
+	}
+	return cons
+}
+
+// MultiCommodityFlow returns the constraints of a multicommodity flow problem
+// on a random directed graph with the given numbers of nodes and arcs, where
+// variable "f_k_a" is the flow of commodity k on arc a. Each commodity has a
+// random source and sink, and a random integer demand in [1, 10]. Flow is
+// conserved at every node, with conservation written as a pair of opposing
+// inequalities, and the total flow on each arc is at most its capacity, a
+// random integer in [1, 20]. With one commodity this is a max-flow feasibility
+// problem.
+func MultiCommodityFlow(nodes, arcs, commodities int, seed int64, rng RNG) []Constraint {
+	if nodes < 2 {
+		panic("lp: flow network needs at least two nodes")
+	}
+	rnd := rng.source(seed)
+	from := make([]int, arcs)
+	to := make([]int, arcs)
+	for a := range from {
+		from[a] = rnd.Intn(nodes)
+		to[a] = rnd.Intn(nodes - 1)
+		if to[a] >= from[a] {
+			to[a]++
+		}
+	}
+
+	var cons []Constraint
+	for k := 0; k < commodities; k++ {
+		source := rnd.Intn(nodes)
+		sink := rnd.Intn(nodes - 1)
+		if sink >= source {
+			sink++
+		}
+		demand := float64(rnd.Intn(10) + 1)
+
+		// Net outflow at each node is the supply, so out = in + supply.
+		for n := 0; n < nodes; n++ {
+			var out, in []Term
+			for a := range from {
+				if from[a] == n {
+					out = append(out, Term{indexedName("f", k, a), 1})
+				}
+				if to[a] == n {
+					in = append(in, Term{indexedName("f", k, a), 1})
+				}
+			}
+			switch n {
+			case source:
+				in = append(in, Term{One, demand})
+			case sink:
+				out = append(out, Term{One, demand})
+			}
+			cons = append(cons,
+				Constraint{Left: out, Right: in},
+				Constraint{Left: in, Right: out},
+			)
+		}
+	}
+	for a := 0; a < arcs; a++ {
+		c := Constraint{Right: []Term{{One, float64(rnd.Intn(20) + 1)}}}
+		for k := 0; k < commodities; k++ {
+			c.Left = append(c.Left, Term{indexedName("f", k, a), 1})
+		}
+		cons = append(cons, c)
+	}
+	return cons
+}
+
+// indexedName returns the prefix followed by the indices, each preceded by an
+// underscore.
+func indexedName(prefix string, idx ...int) string {
+	b := []byte(prefix)
+	for _, i := range idx {
+		b = append(b, '_')
+		b = strconv.AppendInt(b, int64(i), 10)
+	}
+	return string(b)
+}
@@ -0,0 +1,91 @@
+package benchlp
+
+import "testing"
+
+// satisfied returns whether x satisfies all of the constraints, with One
+// fixed to 1.
+func satisfied(cons []Constraint, x map[string]float64) bool {
+	x[One] = 1
+	for _, c := range cons {
+		var left, right float64
+		for _, term := range c.Left {
+			left += term.Value * x[term.Var]
+		}
+		for _, term := range c.Right {
+			right += term.Value * x[term.Var]
+		}
+		if left > right+1e-10 {
+			return false
+		}
+	}
+	return true
+}
+
+func TestAssignment(t *testing.T) {
+	n := 4
+	cons := Assignment(n)
+	if len(cons) != 2*n {
+		t.Fatalf("got %d constraints, want %d", len(cons), 2*n)
+	}
+	x := make(map[string]float64)
+	for i := 0; i < n; i++ {
+		x[indexedName("x", i, (i+1)%n)] = 1
+	}
+	if !satisfied(cons, x) {
+		t.Errorf("permutation does not satisfy the constraints")
+	}
+	x[indexedName("x", 0, 2)] = 1
+	if satisfied(cons, x) {
+		t.Errorf("agent with two tasks satisfies the constraints")
+	}
+}
+
+func TestTransportation(t *testing.T) {
+	m, n := 3, 5
+	cons := Transportation(m, n, 1, Xoshiro1)
+	if len(cons) != m+n {
+		t.Fatalf("got %d constraints, want %d", len(cons), m+n)
+	}
+	if satisfied(cons, map[string]float64{}) {
+		t.Errorf("zero shipment satisfies the demands")
+	}
+}
+
+func TestSetCover(t *testing.T) {
+	cons := SetCover(20, 10, 0.05, 1, Xoshiro1)
+	if len(cons) != 20 {
+		t.Fatalf("got %d constraints, want 20", len(cons))
+	}
+	x := make(map[string]float64)
+	for j := 0; j < 10; j++ {
+		x[indexedName("s", j)] = 1
+	}
+	if !satisfied(cons, x) {
+		t.Errorf("selecting every set does not cover every element")
+	}
+}
+
+func TestMultiCommodityFlow(t *testing.T) {
+	nodes, arcs, commodities := 6, 15, 3
+	cons := MultiCommodityFlow(nodes, arcs, commodities, 1, Xoshiro1)
+	if want := 2*nodes*commodities + arcs; len(cons) != want {
+		t.Fatalf("got %d constraints, want %d", len(cons), want)
+	}
+	if satisfied(cons, map[string]float64{}) {
+		t.Errorf("zero flow satisfies the demands")
+	}
+}
+
+func TestKnapsack(t *testing.T) {
+	cons := Knapsack(10, 1, MathRand)
+	x := make(map[string]float64)
+	if !satisfied(cons, x) {
+		t.Errorf("empty knapsack infeasible")
+	}
+	for i := 0; i < 10; i++ {
+		x[indexedName("x", i)] = 1
+	}
+	if satisfied(cons, x) {
+		t.Errorf("full knapsack feasible")
+	}
+}
@@ -0,0 +1,108 @@
+/*
+Copyright 2017 Brendan Tracey
+
+Redistribution and use in source and binary forms, with or without modification,
+are permitted provided that the following conditions are met:
+
+1. Redistributions of source code must retain the above copyright notice, this
+list of conditions and the following disclaimer.
+
+2. Redistributions in binary form must reproduce the above copyright notice,
+this list of conditions and the following disclaimer in the documentation and/or
+other materials provided with the distribution.
+
+3. Neither the name of the copyright holder nor the names of its contributors may
+be used to endorse or promote products derived from this software without specific
+prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED.
+IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT,
+INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF
+LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE
+OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED
+OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package benchlp
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"strings"
+)
+
+// SymbolTable is the assignment of indices to variables made by the writers,
+// which can be saved so that the indices of column-indexed output, such as
+// the columns of WriteMatrixMarket, can be mapped back to names by another
+// process. It is a VarIndexer, and can be passed as the Indexer option to
+// write with the same indices.
+type SymbolTable struct {
+	MapIndexer
+}
+
+// NewSymbolTable returns the symbol table of the constraints, with the
+// variables indexed by IndexVariables and ordered by order, as the writers
+// index them.
+func NewSymbolTable(cons []Constraint, order VarOrder) *SymbolTable {
+	names, nameMap := IndexVariables(cons)
+	SortVariables(names, nameMap, order)
+	return &SymbolTable{MapIndexer{Names: names, Map: nameMap}}
+}
+
+var errSymbolName = errors.New("lp: variable name contains a line break")
+
+// WriteTo writes the names to w one per line, in order of index, so that
+// line k holds the variable of index k-1, which is column k of the Matrix
+// Market output. It implements io.WriterTo.
+func (s *SymbolTable) WriteTo(w io.Writer) (int64, error) {
+	var b []byte
+	for _, name := range s.Names {
+		if strings.ContainsAny(name, "\r\n") {
+			return 0, errSymbolName
+		}
+		b = append(b, name...)
+		b = append(b, '\n')
+	}
+	n, err := w.Write(b)
+	return int64(n), err
+}
+
+// ReadFrom replaces the table with the one read from r in the format written
+// by WriteTo. It implements io.ReaderFrom. A repeated name is a ParseError.
+func (s *SymbolTable) ReadFrom(r io.Reader) (int64, error) {
+	cr := &countingReader{r: r}
+	sc := bufio.NewScanner(cr)
+	t := MapIndexer{Map: make(map[string]int)}
+	for sc.Scan() {
+		name := sc.Text()
+		if _, ok := t.Map[name]; ok {
+			return cr.n, &ParseError{Line: len(t.Names) + 1, Err: errRepeatedName}
+		}
+		t.Map[name] = len(t.Names)
+		t.Names = append(t.Names, name)
+	}
+	if err := sc.Err(); err != nil {
+		return cr.n, err
+	}
+	s.MapIndexer = t
+	return cr.n, nil
+}
+
+var errRepeatedName = errors.New("repeated variable name")
+
+// countingReader counts the bytes read from r.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (r *countingReader) Read(b []byte) (int, error) {
+	n, err := r.r.Read(b)
+	r.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,54 @@
+package benchlp
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSymbolTable(t *testing.T) {
+	cons := []Constraint{
+		{Left: []Term{{"v10", 1}, {"v2", 2}}, Right: []Term{{"v1", 1}}},
+	}
+	s := NewSymbolTable(cons, Natural)
+	if want := []string{"v1", "v2", "v10"}; !reflect.DeepEqual(s.Names, want) {
+		t.Fatalf("got names %v, want %v", s.Names, want)
+	}
+
+	var buf bytes.Buffer
+	n, err := s.WriteTo(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "v1\nv2\nv10\n" || n != int64(buf.Len()) {
+		t.Errorf("wrote %d bytes %q", n, buf.String())
+	}
+	var got SymbolTable
+	if m, err := got.ReadFrom(&buf); err != nil || m != n {
+		t.Fatalf("read %d bytes, error %v", m, err)
+	}
+	if !reflect.DeepEqual(got, *s) {
+		t.Errorf("read back %+v, want %+v", got, *s)
+	}
+
+	// Writing with the table as the Indexer matches the table's order.
+	var lp bytes.Buffer
+	if err := WriteConstraintsTo(&lp, cons, &Options{Indexer: &got}); err != nil {
+		t.Fatal(err)
+	}
+	if want := "-1 v1 + 2 v2 + 1 v10 <= 0\n"; lp.String() != want {
+		t.Errorf("got %q, want %q", lp.String(), want)
+	}
+
+	_, err = got.ReadFrom(strings.NewReader("a\nb\na\n"))
+	var perr *ParseError
+	if !errors.As(err, &perr) || perr.Line != 3 {
+		t.Errorf("repeated name: got error %v", err)
+	}
+	bad := &SymbolTable{MapIndexer{Names: []string{"a\nb"}}}
+	if _, err := bad.WriteTo(&buf); err == nil {
+		t.Errorf("no error writing a name with a newline")
+	}
+}
@@ -0,0 +1,88 @@
+/*
+Copyright 2017 Brendan Tracey
+
+Redistribution and use in source and binary forms, with or without modification,
+are permitted provided that the following conditions are met:
+
+1. Redistributions of source code must retain the above copyright notice, this
+list of conditions and the following disclaimer.
+
+2. Redistributions in binary form must reproduce the above copyright notice,
+this list of conditions and the following disclaimer in the documentation and/or
+other materials provided with the distribution.
+
+3. Neither the name of the copyright holder nor the names of its contributors may
+be used to endorse or promote products derived from this software without specific
+prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED.
+IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT,
+INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF
+LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE
+OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED
+OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package benchlp
+
+// ConstraintTemplate is a parameterized constraint that can be expanded over
+// an index set, as in
+//
+//	forall i in 0..n-1: sum_j a[i][j] x_j <= b[i]
+//
+// which is
+//
+//	t := ConstraintTemplate{
+//		Left: func(dst []Term, i int) []Term {
+//			for j, v := range a[i] {
+//				dst = append(dst, Term{x.At(j), v})
+//			}
+//			return dst
+//		},
+//		Right: func(dst []Term, i int) []Term {
+//			return append(dst, Term{One, b[i]})
+//		},
+//	}
+//	cons := t.Expand(n)
+type ConstraintTemplate struct {
+	// Left and Right append the terms of each side of constraint i to dst
+	// and return the result. A nil function gives an empty side.
+	Left, Right func(dst []Term, i int) []Term
+}
+
+// Expand returns the constraints of the template for i in [0, n). The terms
+// of all of the constraints share a single backing array, so expanding takes
+// a number of allocations that grows only logarithmically with the number of
+// terms. Each side is sliced to its own capacity, so appending to one
+// constraint does not overwrite another.
+func (t ConstraintTemplate) Expand(n int) []Constraint {
+	// Record the boundaries of the sides as the terms are appended, and
+	// slice the final backing array once it no longer moves.
+	var terms []Term
+	bounds := make([]int, 2*n+1)
+	for i := 0; i < n; i++ {
+		if t.Left != nil {
+			terms = t.Left(terms, i)
+		}
+		bounds[2*i+1] = len(terms)
+		if t.Right != nil {
+			terms = t.Right(terms, i)
+		}
+		bounds[2*i+2] = len(terms)
+	}
+	cons := make([]Constraint, n)
+	for i := range cons {
+		lo, mid, hi := bounds[2*i], bounds[2*i+1], bounds[2*i+2]
+		if mid > lo {
+			cons[i].Left = terms[lo:mid:mid]
+		}
+		if hi > mid {
+			cons[i].Right = terms[mid:hi:hi]
+		}
+	}
+	return cons
+}
@@ -0,0 +1,65 @@
+package benchlp
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestConstraintTemplate(t *testing.T) {
+	m, n := 3, 4
+	x := NewVarArray("x", m, n)
+	supply := []float64{10, 20, 30}
+	rows := ConstraintTemplate{
+		Left: func(dst []Term, i int) []Term {
+			for j := 0; j < n; j++ {
+				dst = append(dst, Term{x.At(i, j), 1})
+			}
+			return dst
+		},
+		Right: func(dst []Term, i int) []Term {
+			return append(dst, Term{One, supply[i]})
+		},
+	}
+	cons := rows.Expand(m)
+	var want []Constraint
+	for i := 0; i < m; i++ {
+		want = append(want, Constraint{Left: Sum(1, x.Slice(i)), Right: []Term{{One, supply[i]}}})
+	}
+	if !reflect.DeepEqual(cons, want) {
+		t.Errorf("got %v, want %v", cons, want)
+	}
+
+	// Appending to one constraint must not change the next.
+	cons[0].Left = append(cons[0].Left, Term{"y", 1})
+	cons[0].Right = append(cons[0].Right, Term{"y", 1})
+	if !reflect.DeepEqual(cons[1], want[1]) {
+		t.Errorf("appending to a constraint changed the next")
+	}
+
+	// Empty sides are nil, as when built by hand.
+	empty := ConstraintTemplate{Left: func(dst []Term, i int) []Term {
+		if i%2 == 0 {
+			dst = append(dst, Term{"a", float64(i)})
+		}
+		return dst
+	}}
+	cons = empty.Expand(3)
+	want = []Constraint{{Left: []Term{{"a", 0}}}, {}, {Left: []Term{{"a", 2}}}}
+	if !reflect.DeepEqual(cons, want) {
+		t.Errorf("got %v, want %v", cons, want)
+	}
+
+	names := NewVarArray("v", 10).Slice()
+	dense := ConstraintTemplate{Left: func(dst []Term, i int) []Term {
+		for _, name := range names {
+			dst = append(dst, Term{name, float64(i)})
+		}
+		return dst
+	}}
+	allocs := testing.AllocsPerRun(10, func() {
+		dense.Expand(1000)
+	})
+	if allocs > 40 {
+		t.Errorf("%v allocations expanding 1000 constraints", allocs)
+	}
+}
@@ -0,0 +1,82 @@
+/*
+Copyright 2017 Brendan Tracey
+
+Redistribution and use in source and binary forms, with or without modification,
+are permitted provided that the following conditions are met:
+
+1. Redistributions of source code must retain the above copyright notice, this
+list of conditions and the following disclaimer.
+
+2. Redistributions in binary form must reproduce the above copyright notice,
+this list of conditions and the following disclaimer in the documentation and/or
+other materials provided with the distribution.
+
+3. Neither the name of the copyright holder nor the names of its contributors may
+be used to endorse or promote products derived from this software without specific
+prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED.
+IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT,
+INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF
+LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE
+OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED
+OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package benchlp
+
+// VarUsage is an inverted index from each variable to the constraints that
+// reference it, so that the rows of a variable can be found without a scan of
+// every term. Constraints are indexed incrementally by AddConstraint, in
+// order, so the index can be kept up to date as constraints are generated.
+// The zero value is an empty VarUsage ready to use.
+type VarUsage struct {
+	rows map[string][]int
+	n    int
+}
+
+// NewVarUsage returns the usage index of the constraints.
+func NewVarUsage(cons []Constraint) *VarUsage {
+	u := &VarUsage{}
+	for _, c := range cons {
+		u.AddConstraint(c)
+	}
+	return u
+}
+
+// AddConstraint indexes the constraint as the next row, and returns its
+// index. A variable is recorded once per row however many terms reference
+// it, including terms that cancel.
+func (u *VarUsage) AddConstraint(c Constraint) int {
+	if u.rows == nil {
+		u.rows = make(map[string][]int)
+	}
+	i := u.n
+	for _, terms := range [2][]Term{c.Left, c.Right} {
+		for _, term := range terms {
+			rows := u.rows[term.Var]
+			if len(rows) > 0 && rows[len(rows)-1] == i {
+				continue
+			}
+			u.rows[term.Var] = append(rows, i)
+		}
+	}
+	u.n++
+	return i
+}
+
+// Rows returns the indices of the constraints that reference the variable,
+// in increasing order. The slice is shared with u and must not be modified.
+func (u *VarUsage) Rows(name string) []int {
+	return u.rows[name]
+}
+
+// Len returns the number of constraints indexed.
+func (u *VarUsage) Len() int { return u.n }
+
+// Vars returns the number of distinct variables referenced.
+func (u *VarUsage) Vars() int { return len(u.rows) }
@@ -0,0 +1,32 @@
+package benchlp
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestVarUsage(t *testing.T) {
+	cons := []Constraint{
+		{Left: []Term{{"x", 1}, {"x", 2}}, Right: []Term{{"y", 1}}},
+		{Left: []Term{{"z", 1}}},
+		{Left: []Term{{"y", 1}}, Right: []Term{{"x", 1}, {One, 3}}},
+	}
+	u := NewVarUsage(cons[:2])
+	if i := u.AddConstraint(cons[2]); i != 2 {
+		t.Errorf("index of added constraint: got %d, want 2", i)
+	}
+	for name, want := range map[string][]int{
+		"x":   {0, 2},
+		"y":   {0, 2},
+		"z":   {1},
+		One:   {2},
+		"abc": nil,
+	} {
+		if got := u.Rows(name); !reflect.DeepEqual(got, want) {
+			t.Errorf("rows of %q: got %v, want %v", name, got, want)
+		}
+	}
+	if u.Len() != 3 || u.Vars() != 4 {
+		t.Errorf("got %d constraints and %d variables, want 3 and 4", u.Len(), u.Vars())
+	}
+}
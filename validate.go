@@ -0,0 +1,154 @@
+/*
+Copyright 2017 Brendan Tracey
+
+Redistribution and use in source and binary forms, with or without modification,
+are permitted provided that the following conditions are met:
+
+1. Redistributions of source code must retain the above copyright notice, this
+list of conditions and the following disclaimer.
+
+2. Redistributions in binary form must reproduce the above copyright notice,
+this list of conditions and the following disclaimer in the documentation and/or
+other materials provided with the distribution.
+
+3. Neither the name of the copyright holder nor the names of its contributors may
+be used to endorse or promote products derived from this software without specific
+prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED.
+IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT,
+INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF
+LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE
+OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED
+OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package benchlp
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Side identifies a side of a constraint.
+type Side int
+
+const (
+	LeftSide Side = iota
+	RightSide
+)
+
+func (s Side) String() string {
+	if s == LeftSide {
+		return "left"
+	}
+	return "right"
+}
+
+// TermError describes a problem with a term of a constraint. Err, if not
+// nil, is the sentinel error of the problem, such as ErrUnknownVariable.
+type TermError struct {
+	Constraint int
+	Side       Side
+	Term       int
+	Reason     string
+	Err        error
+}
+
+func (e *TermError) Error() string {
+	return "lp: constraint " + strconv.Itoa(e.Constraint) + ", " + e.Side.String() +
+		" term " + strconv.Itoa(e.Term) + ": " + e.Reason
+}
+
+func (e *TermError) Unwrap() error { return e.Err }
+
+// ConstraintError describes a problem with a constraint as a whole.
+type ConstraintError struct {
+	Constraint int
+	Reason     string
+}
+
+func (e *ConstraintError) Error() string {
+	return "lp: constraint " + strconv.Itoa(e.Constraint) + ": " + e.Reason
+}
+
+// ValidationErrors is the list of problems found by Validate. Each element is
+// a *TermError or a *ConstraintError.
+type ValidationErrors []error
+
+func (e ValidationErrors) Error() string {
+	s := make([]string, len(e))
+	for i, err := range e {
+		s[i] = err.Error()
+	}
+	return strings.Join(s, "\n")
+}
+
+// Validate checks the constraints for NaN or infinite coefficients, terms
+// with no variable name, and constraints with no terms. It returns nil if
+// there are no problems, and otherwise a ValidationErrors listing every
+// problem found.
+func Validate(cons []Constraint) error {
+	var errs ValidationErrors
+	for i, c := range cons {
+		if len(c.Left) == 0 && len(c.Right) == 0 {
+			errs = append(errs, &ConstraintError{i, "no terms"})
+			continue
+		}
+		errs = validateTerms(errs, i, LeftSide, c.Left)
+		errs = validateTerms(errs, i, RightSide, c.Right)
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+// validateTerms appends the problems with one side of a constraint to errs.
+func validateTerms(errs ValidationErrors, con int, side Side, terms []Term) ValidationErrors {
+	for j, term := range terms {
+		switch {
+		case term.Var == "":
+			errs = append(errs, &TermError{con, side, j, "empty variable name", nil})
+		case math.IsNaN(term.Value):
+			errs = append(errs, &TermError{con, side, j, "NaN coefficient", nil})
+		case math.IsInf(term.Value, 0):
+			errs = append(errs, &TermError{con, side, j, "infinite coefficient", nil})
+		}
+	}
+	return errs
+}
+
+// ValidateRows checks that every variable of the constraints is known to x,
+// as when writing with the Indexer option, and returns the valid
+// constraints along with a TermError, wrapping ErrUnknownVariable, for the
+// first unknown variable of each of the others. A batch pipeline can so skip
+// the bad rows and continue.
+func ValidateRows(cons []Constraint, x VarIndexer) (valid []Constraint, errs []*TermError) {
+	for i, c := range cons {
+		if err := unknownVariable(i, c, x); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		valid = append(valid, c)
+	}
+	return valid, errs
+}
+
+// unknownVariable returns the error of the first term of constraint i whose
+// variable is not known to x, or nil if there is none.
+func unknownVariable(i int, c Constraint, x VarIndexer) *TermError {
+	for side, terms := range [2][]Term{c.Left, c.Right} {
+		for j, term := range terms {
+			if _, ok := x.Index(term.Var); !ok {
+				return &TermError{i, Side(side), j, "unknown variable " + strconv.Quote(term.Var), ErrUnknownVariable}
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,35 @@
+package benchlp
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	if err := Validate(randomConstraints(10, 10)); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	cons := []Constraint{
+		{Left: []Term{{"a", 1}}},
+		{},
+		{Left: []Term{{"a", math.NaN()}, {"", 1}}, Right: []Term{{"b", 1}, {"c", math.Inf(-1)}}},
+	}
+	err := Validate(cons)
+	want := ValidationErrors{
+		&ConstraintError{1, "no terms"},
+		&TermError{2, LeftSide, 0, "NaN coefficient", nil},
+		&TermError{2, LeftSide, 1, "empty variable name", nil},
+		&TermError{2, RightSide, 1, "infinite coefficient", nil},
+	}
+	if !reflect.DeepEqual(err, want) {
+		t.Errorf("got %v, want %v", err, want)
+	}
+	msg := "lp: constraint 1: no terms\n" +
+		"lp: constraint 2, left term 0: NaN coefficient\n" +
+		"lp: constraint 2, left term 1: empty variable name\n" +
+		"lp: constraint 2, right term 1: infinite coefficient"
+	if err.Error() != msg {
+		t.Errorf("got message\n%s\nwant\n%s", err, msg)
+	}
+}
@@ -0,0 +1,183 @@
+/*
+Copyright 2017 Brendan Tracey
+
+Redistribution and use in source and binary forms, with or without modification,
+are permitted provided that the following conditions are met:
+
+1. Redistributions of source code must retain the above copyright notice, this
+list of conditions and the following disclaimer.
+
+2. Redistributions in binary form must reproduce the above copyright notice,
+this list of conditions and the following disclaimer in the documentation and/or
+other materials provided with the distribution.
+
+3. Neither the name of the copyright holder nor the names of its contributors may
+be used to endorse or promote products derived from this software without specific
+prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED.
+IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT,
+INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF
+LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE
+OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED
+OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package benchlp
+
+import "strconv"
+
+// VarArray is a multi-dimensional array of variables, such as the x_i_j of an
+// assignment problem. The variable at indices (i, j, ...) is named by the
+// prefix followed by "_i_j...", as in the structured generators, and has the
+// row-major ID
+//
+//	(i*dims[1] + j)*dims[2] + ...
+//
+// A VarArray is a VarIndexer over its variables in ID order.
+type VarArray struct {
+	prefix  string
+	dims    []int
+	strides []int
+	n       int
+}
+
+// NewVarArray returns an array of variables with the given prefix and
+// dimensions. It panics if a dimension is negative.
+func NewVarArray(prefix string, dims ...int) *VarArray {
+	a := &VarArray{
+		prefix:  prefix,
+		dims:    append([]int(nil), dims...),
+		strides: make([]int, len(dims)),
+		n:       1,
+	}
+	for k := len(dims) - 1; k >= 0; k-- {
+		if dims[k] < 0 {
+			panic("lp: negative dimension")
+		}
+		a.strides[k] = a.n
+		a.n *= dims[k]
+	}
+	return a
+}
+
+// At returns the name of the variable at the indices. It panics if the
+// number of indices is wrong or an index is out of range.
+func (a *VarArray) At(idx ...int) string {
+	a.ID(idx...)
+	return indexedName(a.prefix, idx...)
+}
+
+// ID returns the ID of the variable at the indices. It panics if the number
+// of indices is wrong or an index is out of range.
+func (a *VarArray) ID(idx ...int) int {
+	if len(idx) != len(a.dims) {
+		panic("lp: wrong number of indices")
+	}
+	var id int
+	for k, i := range idx {
+		if i < 0 || i >= a.dims[k] {
+			panic("lp: index out of range")
+		}
+		id += i * a.strides[k]
+	}
+	return id
+}
+
+// Slice returns the names of the variables whose leading indices are idx, in
+// ID order. For example, for a two-dimensional array X, X.Slice(i) is row i
+// and X.Slice() is every variable.
+func (a *VarArray) Slice(idx ...int) []string {
+	if len(idx) > len(a.dims) {
+		panic("lp: wrong number of indices")
+	}
+	n := 1
+	for _, d := range a.dims[len(idx):] {
+		n *= d
+	}
+	if n == 0 {
+		return nil
+	}
+	full := append(append([]int(nil), idx...), make([]int, len(a.dims)-len(idx))...)
+	start := a.ID(full...)
+	names := make([]string, n)
+	for k := range names {
+		names[k] = a.Name(start + k)
+	}
+	return names
+}
+
+// Len returns the number of variables in the array.
+func (a *VarArray) Len() int { return a.n }
+
+// Name returns the name of the variable with the ID.
+func (a *VarArray) Name(id int) string {
+	if id < 0 || id >= a.n {
+		panic("lp: index out of range")
+	}
+	b := []byte(a.prefix)
+	for _, stride := range a.strides {
+		b = append(b, '_')
+		b = strconv.AppendInt(b, int64(id/stride), 10)
+		id %= stride
+	}
+	return string(b)
+}
+
+// Index returns the ID of the named variable, and whether it is in the array.
+func (a *VarArray) Index(name string) (int, bool) {
+	if len(name) < len(a.prefix) || name[:len(a.prefix)] != a.prefix {
+		return 0, false
+	}
+	s := name[len(a.prefix):]
+	var id int
+	for k, d := range a.dims {
+		if len(s) < 2 || s[0] != '_' {
+			return 0, false
+		}
+		s = s[1:]
+		var j int
+		for j < len(s) && isDigit(s[j]) {
+			j++
+		}
+		if j == 0 || j > 1 && s[0] == '0' {
+			return 0, false
+		}
+		i, err := strconv.Atoi(s[:j])
+		if err != nil || i >= d {
+			return 0, false
+		}
+		id += i * a.strides[k]
+		s = s[j:]
+	}
+	if s != "" {
+		return 0, false
+	}
+	return id, true
+}
+
+// Sum returns the terms coeff*v for each of the variables.
+func Sum(coeff float64, vars []string) []Term {
+	terms := make([]Term, len(vars))
+	for i, v := range vars {
+		terms[i] = Term{v, coeff}
+	}
+	return terms
+}
+
+// WeightedSum returns the terms coeffs[i]*vars[i]. It panics if the slices
+// have different lengths.
+func WeightedSum(coeffs []float64, vars []string) []Term {
+	if len(coeffs) != len(vars) {
+		panic("lp: slice length mismatch")
+	}
+	terms := make([]Term, len(vars))
+	for i, v := range vars {
+		terms[i] = Term{v, coeffs[i]}
+	}
+	return terms
+}
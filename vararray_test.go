@@ -0,0 +1,71 @@
+package benchlp
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestVarArray(t *testing.T) {
+	x := NewVarArray("x", 2, 3)
+	if got := x.At(1, 2); got != "x_1_2" {
+		t.Errorf("At(1, 2) = %q", got)
+	}
+	if got := x.ID(1, 2); got != 5 {
+		t.Errorf("ID(1, 2) = %d", got)
+	}
+	if got, want := x.Slice(1), []string{"x_1_0", "x_1_1", "x_1_2"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Slice(1) = %v, want %v", got, want)
+	}
+	all := x.Slice()
+	if len(all) != x.Len() || x.Len() != 6 {
+		t.Fatalf("got %d variables, want 6", len(all))
+	}
+	for id, name := range all {
+		if name != x.Name(id) {
+			t.Errorf("Name(%d) = %q, want %q", id, x.Name(id), name)
+		}
+		if got, ok := x.Index(name); !ok || got != id {
+			t.Errorf("Index(%q) = %d, %t", name, got, ok)
+		}
+	}
+	for _, name := range []string{"x", "x_1", "x_2_0", "x_0_3", "x_01_1", "x_1_1_1", "y_1_1", "x_1_1a", "x__1"} {
+		if _, ok := x.Index(name); ok {
+			t.Errorf("Index(%q) found", name)
+		}
+	}
+
+	for _, f := range []func(){
+		func() { x.At(2, 0) },
+		func() { x.At(0) },
+		func() { x.Slice(0, 0, 0) },
+		func() { WeightedSum([]float64{1}, nil) },
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("no panic")
+				}
+			}()
+			f()
+		}()
+	}
+
+	// Building the assignment problem with a VarArray gives the same
+	// constraints as Assignment.
+	n := 4
+	x = NewVarArray("x", n, n)
+	var cons []Constraint
+	for i := 0; i < n; i++ {
+		cons = append(cons, Constraint{Left: Sum(1, x.Slice(i)), Right: []Term{{One, 1}}})
+	}
+	for j := 0; j < n; j++ {
+		col := make([]string, n)
+		for i := range col {
+			col[i] = x.At(i, j)
+		}
+		cons = append(cons, Constraint{Left: []Term{{One, 1}}, Right: WeightedSum([]float64{1, 1, 1, 1}, col)})
+	}
+	if !reflect.DeepEqual(cons, Assignment(n)) {
+		t.Errorf("constraints differ from Assignment")
+	}
+}
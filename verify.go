@@ -0,0 +1,150 @@
+/*
+Copyright 2017 Brendan Tracey
+
+Redistribution and use in source and binary forms, with or without modification,
+are permitted provided that the following conditions are met:
+
+1. Redistributions of source code must retain the above copyright notice, this
+list of conditions and the following disclaimer.
+
+2. Redistributions in binary form must reproduce the above copyright notice,
+this list of conditions and the following disclaimer in the documentation and/or
+other materials provided with the distribution.
+
+3. Neither the name of the copyright holder nor the names of its contributors may
+be used to endorse or promote products derived from this software without specific
+prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED.
+IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT,
+INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF
+LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE
+OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED
+OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package benchlp
+
+import "math"
+
+// Certificate is the result of checking a solution with VerifySolution.
+// Fields that cannot be computed without duals are NaN.
+type Certificate struct {
+	// PrimalFeasible is whether no constraint is violated by more than the
+	// tolerance, with MaxViolation the largest violation and Violated the
+	// indices of the violated constraints.
+	PrimalFeasible bool
+	MaxViolation   float64
+	Violated       []int
+
+	// DualFeasible is whether the duals have the right sign and the
+	// largest magnitude of a reduced cost, MaxReducedCost, is within the
+	// tolerance. SignErrors holds the indices of the constraints whose
+	// duals are positive.
+	DualFeasible   bool
+	MaxReducedCost float64
+	SignErrors     []int
+
+	// PrimalObjective and DualObjective are the objective values of the
+	// solution and the duals, and Gap is their difference, which weak
+	// duality makes non-negative when both are feasible. Complementarity
+	// is the sum over the constraints of |dual × slack|.
+	PrimalObjective float64
+	DualObjective   float64
+	Gap             float64
+	Complementarity float64
+
+	// Optimal is whether the solution is primal and dual feasible with a
+	// Gap of at most the tolerance relative to 1 + |PrimalObjective|.
+	Optimal bool
+}
+
+// VerifySolution checks the solution to the problem of minimizing the sum of
+// the objective terms subject to the constraints, so that output from a
+// solver need not be trusted. The variables are free, and the constants of
+// the constraints are the terms of One, which has the value one unless the
+// solution gives it.
+//
+// Primal feasibility is checked as by Violated. If sol.Duals is not nil, it
+// must have an element for each constraint, in the convention of Gurobi's
+// Pi, with dual y_i of the constraint a_i·x <= b_i non-positive, so that the
+// dual problem is to maximize b·y subject to Aᵀy = c. The reduced cost of
+// each variable other than One is then its objective coefficient less the
+// dual weighted sum of its coefficients, and it panics if the length of
+// Duals is wrong.
+func VerifySolution(cons []Constraint, objective []Term, sol *Solution, tol float64) Certificate {
+	nan := math.NaN()
+	cert := Certificate{
+		MaxReducedCost:  nan,
+		DualObjective:   nan,
+		Gap:             nan,
+		Complementarity: nan,
+	}
+	y := sol.Duals
+	if y != nil && len(y) != len(cons) {
+		panic("lp: dual length mismatch")
+	}
+	for _, term := range objective {
+		cert.PrimalObjective += term.Value * evalValue(sol.Values, term.Var)
+	}
+	var reduced map[string]float64
+	if y != nil {
+		reduced = make(map[string]float64)
+		cert.DualObjective, cert.Complementarity = 0, 0
+		for _, term := range objective {
+			if term.Var == One {
+				cert.DualObjective += term.Value // a constant of both objectives
+				continue
+			}
+			reduced[term.Var] += term.Value
+		}
+	}
+	for i, e := range EvaluateConstraints(cons, sol.Values) {
+		if !(e.Activity <= tol) {
+			cert.Violated = append(cert.Violated, i)
+		}
+		if e.Violation > cert.MaxViolation || math.IsNaN(e.Violation) {
+			cert.MaxViolation = e.Violation
+		}
+		if y == nil {
+			continue
+		}
+		if y[i] > 0 {
+			cert.SignErrors = append(cert.SignErrors, i)
+		}
+		cert.Complementarity += math.Abs(y[i] * e.Slack)
+		c := cons[i]
+		for _, side := range [2]struct {
+			terms []Term
+			sign  float64
+		}{{c.Left, 1}, {c.Right, -1}} {
+			for _, term := range side.terms {
+				a := side.sign * term.Value
+				if term.Var == One {
+					cert.DualObjective -= a * y[i] // b_i is -a
+					continue
+				}
+				reduced[term.Var] -= y[i] * a
+			}
+		}
+	}
+	cert.PrimalFeasible = len(cert.Violated) == 0
+	if y == nil {
+		return cert
+	}
+	cert.MaxReducedCost = 0
+	for _, d := range reduced {
+		if d := math.Abs(d); d > cert.MaxReducedCost || math.IsNaN(d) {
+			cert.MaxReducedCost = d
+		}
+	}
+	cert.DualFeasible = len(cert.SignErrors) == 0 && cert.MaxReducedCost <= tol
+	cert.Gap = cert.PrimalObjective - cert.DualObjective
+	cert.Optimal = cert.PrimalFeasible && cert.DualFeasible &&
+		math.Abs(cert.Gap) <= tol*(1+math.Abs(cert.PrimalObjective))
+	return cert
+}
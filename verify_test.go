@@ -0,0 +1,49 @@
+package benchlp
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestVerifySolution(t *testing.T) {
+	// Minimize 1 - x - 2y subject to x <= 2, y <= 3 and x + y <= 4.
+	cons := []Constraint{
+		{Left: []Term{{"x", 1}}, Right: []Term{{One, 2}}},
+		{Left: []Term{{"y", 1}, {One, -3}}},
+		{Left: []Term{{"x", 1}, {"y", 1}}, Right: []Term{{One, 4}}},
+	}
+	obj := []Term{{One, 1}, {"x", -1}, {"y", -2}}
+	sol := &Solution{
+		Values: map[string]float64{"x": 1, "y": 3},
+		Duals:  []float64{0, -1, -1},
+	}
+	cert := VerifySolution(cons, obj, sol, 1e-9)
+	if !cert.PrimalFeasible || !cert.DualFeasible || !cert.Optimal {
+		t.Errorf("optimal solution not verified: %+v", cert)
+	}
+	if cert.PrimalObjective != -6 || cert.DualObjective != -6 || cert.Gap != 0 || cert.Complementarity != 0 {
+		t.Errorf("objectives: got %+v", cert)
+	}
+
+	// Feasible but suboptimal, with duals that are not.
+	sol.Values["y"] = 2
+	sol.Duals = []float64{0.5, -1, -1}
+	cert = VerifySolution(cons, obj, sol, 1e-9)
+	if !cert.PrimalFeasible || cert.DualFeasible || cert.Optimal {
+		t.Errorf("suboptimal solution: got %+v", cert)
+	}
+	if !reflect.DeepEqual(cert.SignErrors, []int{0}) || cert.MaxReducedCost != 0.5 || cert.Complementarity != 2.5 {
+		t.Errorf("dual errors: got %+v", cert)
+	}
+
+	sol.Values["x"], sol.Values["y"] = 2, 3
+	sol.Duals = nil
+	cert = VerifySolution(cons, obj, sol, 1e-9)
+	if cert.PrimalFeasible || cert.MaxViolation != 1 || !reflect.DeepEqual(cert.Violated, []int{2}) {
+		t.Errorf("infeasible solution: got %+v", cert)
+	}
+	if cert.DualFeasible || !math.IsNaN(cert.Gap) || !math.IsNaN(cert.MaxReducedCost) {
+		t.Errorf("without duals: got %+v", cert)
+	}
+}
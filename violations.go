@@ -0,0 +1,108 @@
+/*
+Copyright 2017 Brendan Tracey
+
+Redistribution and use in source and binary forms, with or without modification,
+are permitted provided that the following conditions are met:
+
+1. Redistributions of source code must retain the above copyright notice, this
+list of conditions and the following disclaimer.
+
+2. Redistributions in binary form must reproduce the above copyright notice,
+this list of conditions and the following disclaimer in the documentation and/or
+other materials provided with the distribution.
+
+3. Neither the name of the copyright holder nor the names of its contributors may
+be used to endorse or promote products derived from this software without specific
+prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED.
+IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT,
+INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF
+LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE
+OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED
+OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package benchlp
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"text/tabwriter"
+)
+
+// Violation describes a constraint violated at a candidate solution, as
+// listed by Violations.
+type Violation struct {
+	Row       int         `json:"row"`
+	Violation float64     `json:"violation"`
+	Terms     []TermValue `json:"terms"`
+}
+
+// TermValue is a term of a violated constraint, moved to the left hand side,
+// with the value of its variable.
+type TermValue struct {
+	Var   string  `json:"var"`
+	Coeff float64 `json:"coeff"`
+	Value float64 `json:"value"`
+}
+
+// Violations returns the k constraints most violated at x, as evaluated by
+// EvaluateConstraints, in order of decreasing violation and then of row. Only
+// violations greater than tol are listed, and all of them if k is not
+// positive. The terms of each constraint are listed as written, with the
+// coefficients of the right hand side negated.
+func Violations(cons []Constraint, x map[string]float64, k int, tol float64) []Violation {
+	evals := EvaluateConstraints(cons, x)
+	var rows []int
+	for i, e := range evals {
+		if !(e.Activity <= tol) {
+			rows = append(rows, i)
+		}
+	}
+	// NaN violations sort first, as the most suspicious.
+	sort.SliceStable(rows, func(a, b int) bool {
+		va, vb := evals[rows[a]].Activity, evals[rows[b]].Activity
+		return math.IsNaN(va) && !math.IsNaN(vb) || va > vb
+	})
+	if k > 0 && len(rows) > k {
+		rows = rows[:k]
+	}
+	vs := make([]Violation, len(rows))
+	for j, i := range rows {
+		c := cons[i]
+		terms := make([]TermValue, 0, len(c.Left)+len(c.Right))
+		for _, term := range c.Left {
+			terms = append(terms, TermValue{term.Var, term.Value, evalValue(x, term.Var)})
+		}
+		for _, term := range c.Right {
+			terms = append(terms, TermValue{term.Var, -term.Value, evalValue(x, term.Var)})
+		}
+		vs[j] = Violation{Row: i, Violation: evals[i].Activity, Terms: terms}
+	}
+	return vs
+}
+
+// WriteViolations writes the violations to w as text, with each constraint
+// followed by its terms, their values, and their contributions to the
+// activity, for example
+//
+//	row 7: violated by 1
+//	  2 x  at 3 = 6
+//	  -5 y at 1 = -5
+func WriteViolations(w io.Writer, vs []Violation) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 1, ' ', 0)
+	for _, v := range vs {
+		fmt.Fprintf(tw, "row %d: violated by %g\n", v.Row, v.Violation)
+		for _, t := range v.Terms {
+			fmt.Fprintf(tw, "  %g %s\tat %g\t= %g\n", t.Coeff, t.Var, t.Value, t.Coeff*t.Value)
+		}
+	}
+	return tw.Flush()
+}
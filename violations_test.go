@@ -0,0 +1,53 @@
+package benchlp
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestViolations(t *testing.T) {
+	cons := []Constraint{
+		{Left: []Term{{"x", 1}}, Right: []Term{{One, 1}}},   // x <= 1, violated by 2
+		{Left: []Term{{"x", 2}}, Right: []Term{{"y", 6}}},   // 2x <= 6y, satisfied
+		{Left: []Term{{"y", 1}}},                            // y <= 0, violated by 1
+		{Left: []Term{{"x", 1}}, Right: []Term{{One, 2.5}}}, // x <= 2.5, violated by 0.5
+	}
+	x := map[string]float64{"x": 3, "y": 1}
+	got := Violations(cons, x, 2, 1e-9)
+	want := []Violation{
+		{Row: 0, Violation: 2, Terms: []TermValue{{"x", 1, 3}, {One, -1, 1}}},
+		{Row: 2, Violation: 1, Terms: []TermValue{{"y", 1, 1}}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+	if all := Violations(cons, x, 0, 1e-9); len(all) != 3 || all[2].Row != 3 {
+		t.Errorf("all violations: got %+v", all)
+	}
+	if none := Violations(cons, x, 0, 10); len(none) != 0 {
+		t.Errorf("violations beyond the tolerance: got %+v", none)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteViolations(&buf, got); err != nil {
+		t.Fatal(err)
+	}
+	wantText := "row 0: violated by 2\n" +
+		"  1 x    at 3 = 3\n" +
+		"  -1 one at 1 = -1\n" +
+		"row 2: violated by 1\n" +
+		"  1 y at 1 = 1\n"
+	if buf.String() != wantText {
+		t.Errorf("got text\n%s\nwant\n%s", buf.String(), wantText)
+	}
+
+	b, err := json.Marshal(got[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"row":2,"violation":1,"terms":[{"var":"y","coeff":1,"value":1}]}`; string(b) != want {
+		t.Errorf("got JSON %s, want %s", b, want)
+	}
+}
@@ -0,0 +1,691 @@
+/*
+Copyright 2017 Brendan Tracey
+
+Redistribution and use in source and binary forms, with or without modification,
+are permitted provided that the following conditions are met:
+
+1. Redistributions of source code must retain the above copyright notice, this
+list of conditions and the following disclaimer.
+
+2. Redistributions in binary form must reproduce the above copyright notice,
+this list of conditions and the following disclaimer in the documentation and/or
+other materials provided with the distribution.
+
+3. Neither the name of the copyright holder nor the names of its contributors may
+be used to endorse or promote products derived from this software without specific
+prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED.
+IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT,
+INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF
+LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE
+OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED
+OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package benchlp
+
+import (
+	"bufio"
+	"cmp"
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Options controls the output of the writers. The zero value formats
+// coefficients as WriteConstraints does.
+type Options struct {
+	// Format is the format byte passed to strconv.FormatFloat ('g', 'e',
+	// 'f', ...). The zero value is 'g'.
+	Format byte
+	// Precision is the precision passed to strconv.FormatFloat. The zero
+	// value is 16, and -1 gives the shortest representation that parses
+	// back to the same value.
+	Precision int
+	// OmitUnit writes coefficients of 1 and -1 as the bare variable name
+	// and the negated name, for example "v1 + -v2" rather than
+	// "1 v1 + -1 v2".
+	OmitUnit bool
+	// Integers writes coefficients that are integers without a decimal
+	// point or exponent, regardless of Format.
+	Integers bool
+	// Exact writes every coefficient as its exact decimal value, with as
+	// many digits as that takes, so that nothing is lost between the binary
+	// value and the written one, as exact solvers such as QSopt_ex require.
+	// Format and Precision are ignored. Note that a value such as 0.1 is
+	// written with all 55 digits of its nearest float64.
+	Exact bool
+	// MaxDigits, if positive, rounds coefficients to at most that many
+	// significant digits before they are formatted. It is ignored if Exact
+	// is set.
+	MaxDigits int
+	// Exponent is the character that introduces the exponent of a
+	// coefficient written in exponent notation, 'e' or 'E'. The zero value
+	// is 'e'. ShortExponent writes the exponent without a plus sign or
+	// leading zeros, as "1e6" and "1e-7" rather than "1e+06" and "1e-07".
+	// For output without exponents, use a Format of 'f' or set Exact.
+	Exponent      byte
+	ShortExponent bool
+	// ForcePoint writes a decimal point in every coefficient that is not
+	// written by Integers, as "2.0" rather than "2", for readers that
+	// distinguish integer and real constants.
+	ForcePoint bool
+	// DropTolerance, if positive, drops the coefficients of magnitude less
+	// than it after condensing, such as the residue of terms that nearly
+	// cancel. The number dropped from each constraint is recorded in
+	// Report.Dropped, so set Report to audit what was dropped; WriteMPS
+	// fills in only Report.Dropped. WriteAMPL, WriteMatrixMarket,
+	// WriteOSiL, and WriteIndexedConstraintsTo ignore DropTolerance.
+	DropTolerance float64
+	// VarScale, if not nil, changes the units of the variables as they are
+	// written: variable v in the output stands for v/VarScale[v], so its
+	// coefficients are multiplied by VarScale[v]. For example, a factor of
+	// 1e6 writes an amount in dollars as one in millions. Variables not in
+	// VarScale are written unscaled, and factors must be positive.
+	// VarScaling(VarScale).Unscale converts a solution of the written
+	// problem back to the original units. DropTolerance applies to the
+	// scaled coefficients. WriteIndexedConstraintsTo and the formats other
+	// than LP ignore VarScale.
+	VarScale map[string]float64
+	// Dialect is the LP file dialect the constraint lines are written in.
+	Dialect Dialect
+	// Names, if its Legal function is set, writes the variables under the
+	// names given by SanitizeNames with these rules, such as LPNames or
+	// MPSNames, so that any string can be used as a variable. The map from
+	// the changed names back to the originals is written as comments at
+	// the start of the output, as by WriteNameMap. WriteConstraintsTo,
+	// WriteMPS, and WriteAMPL apply Names; WriteOSiL escapes every name and
+	// the other writers ignore it.
+	Names NameRules
+	// TwoSided writes each constraint with the terms of each side kept on
+	// that side, condensed separately, for example "2 a <= 3 b" rather than
+	// "2 a + -3 b <= 0", so the file reads as the constraints were built.
+	// An empty side is written as 0. Check that the solver reads variables
+	// on the right hand side: lp_solve does, but CPLEX requires a constant
+	// there. WriteAMPL, WriteMatrixMarket, and WriteOSiL ignore TwoSided.
+	TwoSided bool
+	// RHS, if not nil, computes the right hand side constant of each
+	// constraint as it is written, for constants derived from external
+	// data rather than stored in the constraint as terms of One. The
+	// constraint is written as sum(Left) - sum(Right) <= RHS(c). RHS is
+	// called from the writing goroutine, once per constraint. Files with
+	// non-zero constants cannot be read by ReadConstraints.
+	// WriteIndexedConstraintsTo and the formats other than LP ignore RHS.
+	RHS func(Constraint) float64
+	// Comments are written as comment lines, in the syntax of Dialect,
+	// before the constraints, for example to record the provenance of the
+	// file. A comment containing newlines is written as several lines.
+	Comments []string
+	// Attributes, if not nil, are written as comments, the variable
+	// attributes after Comments and the attributes of each constraint
+	// before it, or after it on the same line if TrailingAttributes is set.
+	Attributes         *Attributes
+	TrailingAttributes bool
+	// Cache, if not nil, reuses the lines of constraints that are unchanged
+	// since an earlier write with the same cache. It is not used when RHS or
+	// Names is set. WriteShards and the formats other than LP ignore Cache.
+	Cache *RenderCache
+
+	// Order is the order in which the variables of each constraint are
+	// written.
+	Order VarOrder
+	// Terms, if not IndexOrder, sorts the terms of each constraint
+	// independently of Order, which then only affects the indices.
+	Terms TermOrder
+	// Indexer, if not nil, indexes the variables in place of
+	// IndexVariables, saving the scan over all of the terms and the map
+	// lookups when it is cheaper, as NumericIndexer is. Variables are then
+	// written in the order of their indices, and Order is ignored. Writing
+	// panics if a variable is not known to Indexer.
+	Indexer VarIndexer
+	// AllocateScratch allocates new weight vectors for every constraint
+	// rather than reusing one pair, as WriteConstraints does without
+	// preallocation. It only makes writing slower, and exists to measure
+	// the cost of the garbage.
+	AllocateScratch bool
+	// Strict lets the writers panic on an invalid constraint or option, such
+	// as a variable unknown to the Indexer, as the functions without
+	// options do. Otherwise the panic is recovered and returned as an
+	// error, a TermError for a variable unknown to the Indexer.
+	Strict bool
+	// IndexWorkers, if greater than one, is the number of goroutines used
+	// to index the variables, with IndexVariablesParallel.
+	IndexWorkers int
+
+	// BufferSize is the size of the buffer used to batch writes to the
+	// output. The zero value is 64 KiB.
+	BufferSize int
+	// Compress compresses the output with gzip as it is written, at the
+	// level given by CompressionLevel.
+	Compress bool
+	// CompressionLevel is the gzip compression level. The zero value is
+	// gzip.DefaultCompression.
+	CompressionLevel int
+	// Limits bounds the size of the problem and of each output. A write
+	// that exceeds them fails with a *LimitError, before anything is
+	// written if it is the constraints that exceed them.
+	Limits Limits
+	// TempDir, if not empty, is the directory in which writers that need
+	// scratch space larger than the constraints, such as WriteMPS, create
+	// their temporary files, rather than holding it in memory.
+	TempDir string
+
+	// Report, if not nil, is filled in with the time and memory used by
+	// each phase of the write.
+	Report *Report
+	// ProfileLabels sets the PhaseLabel pprof label on the writing
+	// goroutine during each phase of the write, so CPU profiles attribute
+	// time to indexing, condensing, and formatting. When the write
+	// finishes, the goroutine labels are set to those of the context
+	// passed to WriteConstraintsContext, or cleared.
+	ProfileLabels bool
+
+	// Progress, if not nil, is called after every ProgressInterval
+	// constraints and after the last constraint, from the writing
+	// goroutine. The zero value of ProgressInterval is 10000.
+	Progress         func(Progress)
+	ProgressInterval int
+
+	// Checkpoint, if not nil, is called after every CheckpointInterval
+	// constraints, once the output up to that constraint has been written
+	// to w, so that an interrupted write can be resumed. When compressing,
+	// the gzip stream is ended at each checkpoint and a new one started,
+	// which gzip readers read as one stream. The zero value of
+	// CheckpointInterval is 100000.
+	Checkpoint         func(Checkpoint)
+	CheckpointInterval int
+	// Resume, if not nil, resumes a write from a checkpoint passed to
+	// Checkpoint by an earlier write of the same constraints with the same
+	// options. The constraints before the checkpoint are not written, nor
+	// are the comments, and the output must be appended to that of the
+	// earlier write truncated to Resume.Bytes.
+	Resume *Checkpoint
+}
+
+// Checkpoint records how much of a write has reached the output.
+type Checkpoint struct {
+	Constraints int   // constraints written
+	Bytes       int64 // bytes written to the output, after compression
+}
+
+var errBadResume = errors.New("lp: resume checkpoint out of range")
+
+// Dialect is a dialect of the LP file format.
+type Dialect int
+
+const (
+	// CPLEXDialect writes constraints as accepted by CPLEX, for example
+	// "2 a + -1 b <= 0".
+	CPLEXDialect Dialect = iota
+	// GurobiDialect writes constraints as accepted by Gurobi, which reads
+	// the same form as CPLEX.
+	GurobiDialect
+	// LPSolveDialect writes constraints as accepted by lp_solve, with
+	// negative coefficients after the first written as subtraction and each
+	// constraint ended by a semicolon, for example "2 a - 1 b <= 0;".
+	//
+	// lp_solve reads an unnamed constraint with a single variable as a
+	// bound, and variables are non-negative unless declared free, so the
+	// lines are not equivalent to the constraints on their own.
+	LPSolveDialect
+)
+
+// WriteConstraintsTo writes the constraints to w in the same form as
+// WriteConstraints, one constraint per line, formatted according to opts.
+// A nil opts is equivalent to the zero value.
+//
+// Output is buffered internally, and compressed if requested, as the
+// constraints are written, so the full output is never held in memory.
+func WriteConstraintsTo(w io.Writer, cons []Constraint, opts *Options) error {
+	return WriteConstraintsContext(context.Background(), w, cons, opts)
+}
+
+// cancelInterval is the number of constraints between checks for
+// cancellation.
+const cancelInterval = 1024
+
+// WriteConstraintsContext is like WriteConstraintsTo, but stops writing if ctx
+// is cancelled, checking after every 1024 constraints. On cancellation it
+// returns ctx.Err(), and the output written to w is a whole number of
+// constraint lines, with any compressed stream properly terminated.
+func WriteConstraintsContext(ctx context.Context, w io.Writer, cons []Constraint, opts *Options) (err error) {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if opts == nil {
+		opts = &Options{}
+	}
+	defer opts.recoverWrite(&err, cons)
+	if err := opts.Limits.check(cons); err != nil {
+		return err
+	}
+	var cw *countingWriter
+	if opts.Checkpoint != nil || opts.Resume != nil {
+		cw = &countingWriter{w: w}
+		if opts.Resume != nil {
+			cw.n = opts.Resume.Bytes
+		}
+		w = cw
+	}
+	out, err := opts.output(w)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cerr := out.Close(); err == nil {
+			err = cerr
+		}
+	}()
+
+	if opts.useCache() {
+		opts.Cache.start()
+		defer func() {
+			if err == nil {
+				opts.Cache.sweep()
+			}
+		}()
+	}
+
+	in := instruments{
+		ctx:      ctx,
+		rep:      opts.Report,
+		progress: opts.Progress,
+		interval: opts.ProgressInterval,
+		start:    time.Now(),
+
+		checkpoint: opts.Checkpoint,
+		cpInterval: opts.CheckpointInterval,
+		out:        out,
+		written:    cw,
+	}
+	if in.interval <= 0 {
+		in.interval = 10000
+	}
+	if in.cpInterval <= 0 {
+		in.cpInterval = 100000
+	}
+	if opts.Resume != nil {
+		in.first = opts.Resume.Constraints
+		if in.first < 0 || in.first > len(cons) {
+			return errBadResume
+		}
+	}
+	var phase *phaseTimer
+	if in.rep != nil {
+		*in.rep = Report{Constraints: len(cons)}
+		phase = startPhase()
+	}
+	if opts.ProfileLabels {
+		in.labels = newPhaseLabels(ctx)
+		defer in.labels.set(in.labels.base)
+		in.labels.set(in.labels.index)
+	}
+
+	if opts.Resume == nil {
+		if err := opts.writeComments(out); err != nil {
+			return err
+		}
+	}
+
+	var names []string
+	var nameMap map[string]int
+	if opts.Indexer != nil {
+		names = make([]string, opts.Indexer.Len())
+		for i := range names {
+			names[i] = opts.Indexer.Name(i)
+		}
+	} else {
+		if opts.IndexWorkers > 1 {
+			names, nameMap = IndexVariablesParallel(cons, opts.IndexWorkers)
+		} else {
+			names, nameMap = IndexVariables(cons)
+		}
+		SortVariables(names, nameMap, opts.Order)
+	}
+	r := NewRenderer(names, nameMap, nil, nil, nil, opts)
+	if opts.Names.Legal != nil {
+		// A resumed output already starts with the map.
+		nw := io.Writer(out)
+		if opts.Resume != nil {
+			nw = io.Discard
+		}
+		if r.names, err = opts.writeNames(nw, opts.commentPrefix(), names); err != nil {
+			return err
+		}
+	}
+
+	if in.rep != nil || in.labels != nil || in.progress != nil || in.checkpoint != nil || in.first > 0 ||
+		opts.Attributes != nil {
+		if in.rep != nil {
+			in.rep.Index = phase.stop()
+			phase = startPhase()
+			defer func() {
+				in.rep.Render = phase.stop()
+			}()
+		}
+		return r.writeInstrumented(out, cons, &in)
+	}
+	done := ctx.Done()
+	for i, c := range cons {
+		if done != nil && i%cancelInterval == 0 {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+		}
+		if _, err := out.Write(r.Render(c)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// output wraps w in the buffering and compression given by the options.
+// Closing the returned writer flushes all output to w but does not close w.
+func (o *Options) output(w io.Writer) (*outputWriter, error) {
+	if o.Limits.MaxBytes > 0 {
+		w = &limitWriter{w: w, max: o.Limits.MaxBytes}
+	}
+	out := &outputWriter{dst: w}
+	if o.Compress {
+		level := o.CompressionLevel
+		if level == 0 {
+			level = gzip.DefaultCompression
+		}
+		zw, err := gzip.NewWriterLevel(w, level)
+		if err != nil {
+			return nil, err
+		}
+		out.zw = zw
+		w = zw
+	}
+	size := o.BufferSize
+	if size == 0 {
+		size = 64 << 10
+	}
+	out.Writer = bufio.NewWriterSize(w, size)
+	return out, nil
+}
+
+// outputWriter is the buffered, and possibly compressed, output of a writer.
+type outputWriter struct {
+	*bufio.Writer
+	zw  *gzip.Writer
+	dst io.Writer
+}
+
+// checkpoint flushes the output written so far to the destination, ending
+// the gzip stream and starting a new one if compressing, so that the output
+// so far is complete.
+func (w *outputWriter) checkpoint() error {
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	if w.zw != nil {
+		if err := w.zw.Close(); err != nil {
+			return err
+		}
+		w.zw.Reset(w.dst)
+	}
+	return nil
+}
+
+// countingWriter counts the bytes written to w.
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (w *countingWriter) Write(b []byte) (int, error) {
+	n, err := w.w.Write(b)
+	w.n += int64(n)
+	return n, err
+}
+
+func (w *outputWriter) Close() error {
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	if w.zw != nil {
+		return w.zw.Close()
+	}
+	return nil
+}
+
+// writeComments writes the comment lines of the options, followed by the
+// variable attributes.
+func (o *Options) writeComments(w io.Writer) error {
+	var b []byte
+	for _, comment := range o.Comments {
+		for _, line := range strings.Split(comment, "\n") {
+			b = append(b, o.commentPrefix()...)
+			b = append(b, line...)
+			b = append(b, '\n')
+		}
+	}
+	if o.Attributes != nil {
+		b = o.appendVarAttributes(b)
+	}
+	_, err := w.Write(b)
+	return err
+}
+
+// commentPrefix returns the start of a comment line in the dialect.
+func (o *Options) commentPrefix() string {
+	if o.Dialect == LPSolveDialect {
+		return "// "
+	}
+	return `\ `
+}
+
+// writeNames returns the names under which the variables are written,
+// sanitized by the Names option if it is set, and writes the map from the
+// changed names back to the originals to w, each line starting with prefix.
+func (o *Options) writeNames(w io.Writer, prefix string, names []string) ([]string, error) {
+	if o.Names.Legal == nil {
+		return names, nil
+	}
+	sanitized := SanitizeNames(names, o.Names)
+	return sanitized, WriteNameMap(w, prefix, sanitized, names)
+}
+
+// appendConstraint appends the line for a condensed constraint with the
+// right hand side rhs.
+func (o *Options) appendConstraint(b []byte, w []float64, names []string, rhs float64) []byte {
+	b = o.appendTerms(b, w, names)
+	b = append(b, " <= "...)
+	b = o.appendFloat(b, rhs)
+	if o.Dialect == LPSolveDialect {
+		b = append(b, ';')
+	}
+	return append(b, '\n')
+}
+
+// drop zeroes the weights of magnitude less than the DropTolerance option,
+// returning how many it zeroed.
+func (o *Options) drop(w []float64) int {
+	var n int
+	for i, v := range w {
+		if o.drops(v) {
+			w[i] = 0
+			n++
+		}
+	}
+	return n
+}
+
+// drops returns whether the coefficient v is dropped by the DropTolerance
+// option.
+func (o *Options) drops(v float64) bool {
+	return v != 0 && math.Abs(v) < o.DropTolerance
+}
+
+// recordDropped records in the Report option, if it is set, that n
+// coefficients were dropped from constraint i.
+func (o *Options) recordDropped(i, n int) {
+	if o.Report == nil || n == 0 {
+		return
+	}
+	if o.Report.Dropped == nil {
+		o.Report.Dropped = make(map[int]int)
+	}
+	o.Report.Dropped[i] = n
+}
+
+// rhs returns the right hand side constant of the constraint.
+func (o *Options) rhs(c Constraint) float64 {
+	if o.RHS == nil {
+		return 0
+	}
+	return o.RHS(c)
+}
+
+// varScale returns the factors of the variables in names from the VarScale
+// option, extending scale, which holds those of a prefix of names.
+func (o *Options) varScale(scale []float64, names []string) []float64 {
+	for _, name := range names[len(scale):] {
+		f, ok := o.VarScale[name]
+		if !ok {
+			f = 1
+		} else if !(f > 0) {
+			panic("lp: scale factor not positive")
+		}
+		scale = append(scale, f)
+	}
+	return scale
+}
+
+// appendTwoSided appends the line for a constraint whose sides have been
+// condensed separately, with the constant rhs added to the right hand side.
+func (o *Options) appendTwoSided(b []byte, wl, wr []float64, names []string, rhs float64) []byte {
+	b = o.appendSide(b, wl, names, 0)
+	b = append(b, " <= "...)
+	b = o.appendSide(b, wr, names, rhs)
+	if o.Dialect == LPSolveDialect {
+		b = append(b, ';')
+	}
+	return append(b, '\n')
+}
+
+// appendSide appends the terms of one side of a constraint followed by the
+// constant, which is omitted if it is zero unless there are no terms.
+func (o *Options) appendSide(b []byte, w []float64, names []string, constant float64) []byte {
+	n := len(b)
+	b = o.appendTerms(b, w, names)
+	switch {
+	case len(b) == n:
+	case constant == 0:
+		return b
+	case o.Dialect == LPSolveDialect && constant < 0:
+		b = append(b, " - "...)
+		constant = -constant
+	default:
+		b = append(b, " + "...)
+	}
+	return o.appendFloat(b, constant)
+}
+
+// appendTerms appends all of the w_i * v_i terms, in the order given by
+// o.Terms.
+func (o *Options) appendTerms(b []byte, w []float64, names []string) []byte {
+	if o.Terms != IndexOrder {
+		return o.appendSortedTerms(b, w, names)
+	}
+	first := true
+	for i, v := range w {
+		if v == 0 {
+			continue
+		}
+		b = o.appendTerm(b, v, names[i], first)
+		first = false
+	}
+	return b
+}
+
+// appendSortedTerms is appendTerms for the orders other than IndexOrder.
+func (o *Options) appendSortedTerms(b []byte, w []float64, names []string) []byte {
+	var small [64]int
+	idx := small[:0]
+	for i, v := range w {
+		if v != 0 {
+			idx = append(idx, i)
+		}
+	}
+	switch o.Terms {
+	case NameOrder:
+		slices.SortFunc(idx, func(i, j int) int {
+			return strings.Compare(names[i], names[j])
+		})
+	case MagnitudeOrder:
+		slices.SortFunc(idx, func(i, j int) int {
+			if c := cmp.Compare(math.Abs(w[j]), math.Abs(w[i])); c != 0 {
+				return c
+			}
+			return i - j
+		})
+	default:
+		panic("lp: unknown term order")
+	}
+	for k, i := range idx {
+		b = o.appendTerm(b, w[i], names[i], k == 0)
+	}
+	return b
+}
+
+// appendTerm appends the term v * name, preceded by an operator unless it is
+// the first term.
+func (o *Options) appendTerm(b []byte, v float64, name string, first bool) []byte {
+	switch {
+	case first:
+	case o.Dialect == LPSolveDialect && v < 0:
+		b = append(b, " - "...)
+		v = -v
+	default:
+		b = append(b, " + "...)
+	}
+	switch {
+	case o.OmitUnit && v == 1:
+	case o.OmitUnit && v == -1:
+		b = append(b, '-')
+	default:
+		b = o.appendFloat(b, v)
+		b = append(b, ' ')
+	}
+	return append(b, name...)
+}
+
+// appendFloat appends the formatted value of v.
+func (o *Options) appendFloat(b []byte, v float64) []byte {
+	if o.Integers && v == math.Trunc(v) && math.Abs(v) < 1<<53 {
+		return strconv.AppendInt(b, int64(v), 10)
+	}
+	start := len(b)
+	if o.Exact {
+		return o.styleFloat(appendExact(b, v), start)
+	}
+	if o.MaxDigits > 0 {
+		v = roundDigits(v, o.MaxDigits)
+	}
+	format := o.Format
+	if format == 0 {
+		format = 'g'
+	}
+	prec := o.Precision
+	if prec == 0 {
+		prec = 16
+	}
+	if format == 'g' && prec == -1 {
+		return o.styleFloat(appendShortest(b, v), start)
+	}
+	return o.styleFloat(strconv.AppendFloat(b, v, format, prec, 64), start)
+}
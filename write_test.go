@@ -0,0 +1,316 @@
+package benchlp
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestWriteConstraintsTo(t *testing.T) {
+	cons := []Constraint{
+		{Left: []Term{{"a", 1.0 / 3}, {"b", 1}}, Right: []Term{{"c", 2}}},
+		{Left: []Term{{"c", 1}, {"a", 1e-20}}, Right: []Term{{"b", 2.5}}},
+	}
+	for _, test := range []struct {
+		opts *Options
+		want string
+	}{
+		{
+			opts: nil,
+			want: "0.3333333333333333 a + 1 b + -2 c <= 0\n" +
+				"9.999999999999999e-21 a + -2.5 b + 1 c <= 0\n",
+		},
+		{
+			opts: &Options{Precision: -1, OmitUnit: true},
+			want: "0.3333333333333333 a + b + -2 c <= 0\n" +
+				"1e-20 a + -2.5 b + c <= 0\n",
+		},
+		{
+			opts: &Options{Format: 'e', Precision: 3, Integers: true},
+			want: "3.333e-01 a + 1 b + -2 c <= 0\n" +
+				"1.000e-20 a + -2.500e+00 b + 1 c <= 0\n",
+		},
+		{
+			opts: &Options{Format: 'f', Precision: 2, OmitUnit: true},
+			want: "0.33 a + b + -2.00 c <= 0.00\n" +
+				"0.00 a + -2.50 b + c <= 0.00\n",
+		},
+		{
+			opts: &Options{Exact: true},
+			want: "0.333333333333333314829616256247390992939472198486328125 a + 1 b + -2 c <= 0\n" +
+				"0.00000000000000000000999999999999999945153271454209571651729503702787392447107715776066783064379706047475337982177734375 a + -2.5 b + 1 c <= 0\n",
+		},
+		{
+			opts: &Options{Precision: -1, OmitUnit: true, Dialect: LPSolveDialect},
+			want: "0.3333333333333333 a + b - 2 c <= 0;\n" +
+				"1e-20 a - 2.5 b + c <= 0;\n",
+		},
+	} {
+		var buf bytes.Buffer
+		if err := WriteConstraintsTo(&buf, cons, test.opts); err != nil {
+			t.Fatal(err)
+		}
+		if buf.String() != test.want {
+			t.Errorf("options %+v:\ngot\n%s\nwant\n%s", test.opts, buf.String(), test.want)
+		}
+	}
+}
+
+func TestWriteConstraintsTwoSided(t *testing.T) {
+	cons := []Constraint{
+		{Left: []Term{{"a", 1}, {"b", 2}, {"a", 1}}, Right: []Term{{"c", 3}}},
+		{Left: []Term{{"c", 1}}, Right: []Term{{"c", 2}, {"b", -1}}},
+		{Right: []Term{{"a", 1}}},
+		{Left: []Term{{"b", 1}, {"b", -1}}},
+	}
+	opts := &Options{TwoSided: true, OmitUnit: true}
+	want := "2 a + 2 b <= 3 c\n" +
+		"c <= -b + 2 c\n" +
+		"0 <= a\n" +
+		"0 <= 0\n"
+	var buf bytes.Buffer
+	if err := WriteConstraintsTo(&buf, cons, opts); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != want {
+		t.Errorf("got\n%s\nwant\n%s", buf.String(), want)
+	}
+
+	got, err := ReadConstraints(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !EqualConstraints(got, cons, 0) {
+		t.Errorf("read back %v, want %v", got, cons)
+	}
+	if got[1].Right == nil {
+		t.Errorf("right hand side not kept: %v", got[1])
+	}
+
+	buf.Reset()
+	instrumented := *opts
+	instrumented.Report = &Report{}
+	if err := WriteConstraintsTo(&buf, cons, &instrumented); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != want {
+		t.Errorf("instrumented: got\n%s\nwant\n%s", buf.String(), want)
+	}
+
+	icons, names := IndexConstraints(cons)
+	buf.Reset()
+	if err := WriteIndexedConstraintsTo(&buf, icons, names, opts); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != want {
+		t.Errorf("indexed: got\n%s\nwant\n%s", buf.String(), want)
+	}
+}
+
+func TestWriteConstraintsDrop(t *testing.T) {
+	cons := []Constraint{
+		{Left: []Term{{"a", 0.1}, {"a", 0.2}, {"b", 1}}, Right: []Term{{"a", 0.3}}},
+		{Left: []Term{{"a", 1}, {"b", 1e-10}}},
+		{Left: []Term{{"a", 1e-12}, {"b", -1e-13}}},
+	}
+	rep := &Report{}
+	var buf bytes.Buffer
+	if err := WriteConstraintsTo(&buf, cons, &Options{DropTolerance: 1e-9, Report: rep}); err != nil {
+		t.Fatal(err)
+	}
+	want := "1 b <= 0\n" +
+		"1 a <= 0\n" +
+		" <= 0\n"
+	if buf.String() != want {
+		t.Errorf("got\n%s\nwant\n%s", buf.String(), want)
+	}
+	if wantDropped := map[int]int{0: 1, 1: 1, 2: 2}; !reflect.DeepEqual(rep.Dropped, wantDropped) {
+		t.Errorf("got dropped %v, want %v", rep.Dropped, wantDropped)
+	}
+
+	rep = &Report{}
+	if err := WriteConstraintsTo(io.Discard, cons, &Options{DropTolerance: 1e-11, Report: rep}); err != nil {
+		t.Fatal(err)
+	}
+	if wantDropped := map[int]int{0: 1, 2: 2}; !reflect.DeepEqual(rep.Dropped, wantDropped) {
+		t.Errorf("got dropped %v, want %v", rep.Dropped, wantDropped)
+	}
+}
+
+func TestWriteConstraintsRHS(t *testing.T) {
+	cons := []Constraint{
+		{Left: []Term{{"a", 1}}, Right: []Term{{"b", 2}}},
+		{Left: []Term{{"b", 1}}},
+		{Right: []Term{{"a", 1}}},
+	}
+	capacity := []float64{5, -1.5, 0}
+	var row int
+	rhs := func(c Constraint) float64 {
+		v := capacity[row]
+		row++
+		return v
+	}
+	for _, test := range []struct {
+		opts *Options
+		want string
+	}{
+		{
+			opts: &Options{OmitUnit: true},
+			want: "a + -2 b <= 5\n" +
+				"b <= -1.5\n" +
+				"-a <= 0\n",
+		},
+		{
+			opts: &Options{OmitUnit: true, TwoSided: true},
+			want: "a <= 2 b + 5\n" +
+				"b <= -1.5\n" +
+				"0 <= a\n",
+		},
+		{
+			opts: &Options{OmitUnit: true, TwoSided: true, Dialect: LPSolveDialect, Report: &Report{}},
+			want: "a <= 2 b + 5;\n" +
+				"b <= -1.5;\n" +
+				"0 <= a;\n",
+		},
+	} {
+		row = 0
+		test.opts.RHS = rhs
+		var buf bytes.Buffer
+		if err := WriteConstraintsTo(&buf, cons, test.opts); err != nil {
+			t.Fatal(err)
+		}
+		if buf.String() != test.want {
+			t.Errorf("options %+v:\ngot\n%s\nwant\n%s", test.opts, buf.String(), test.want)
+		}
+	}
+}
+
+func TestWriteConstraintsToCompress(t *testing.T) {
+	cons := randomConstraints(100, 1000)
+	var plain, compressed bytes.Buffer
+	if err := WriteConstraintsTo(&plain, cons, &Options{BufferSize: 16}); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteConstraintsTo(&compressed, cons, &Options{Compress: true, CompressionLevel: gzip.BestSpeed}); err != nil {
+		t.Fatal(err)
+	}
+	if compressed.Len() >= plain.Len() {
+		t.Errorf("compressed output is not smaller: %d >= %d", compressed.Len(), plain.Len())
+	}
+	zr, err := gzip.NewReader(&compressed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := io.ReadAll(zr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, plain.Bytes()) {
+		t.Errorf("decompressed output differs")
+	}
+}
+
+// crashWriter fails once more than limit bytes have been written to it.
+type crashWriter struct {
+	bytes.Buffer
+	limit int
+}
+
+var errCrash = errors.New("crash")
+
+func (w *crashWriter) Write(b []byte) (int, error) {
+	if w.Len()+len(b) > w.limit {
+		n, _ := w.Buffer.Write(b[:w.limit-w.Len()])
+		return n, errCrash
+	}
+	return w.Buffer.Write(b)
+}
+
+func TestWriteConstraintsResume(t *testing.T) {
+	cons := randomConstraints(100, 1000)
+	for _, compress := range []bool{false, true} {
+		opts := &Options{Comments: []string{"resumed"}, Compress: compress, BufferSize: 256}
+		var want bytes.Buffer
+		if err := WriteConstraintsTo(&want, cons, opts); err != nil {
+			t.Fatal(err)
+		}
+
+		var last *Checkpoint
+		first := *opts
+		first.CheckpointInterval = 100
+		first.Checkpoint = func(cp Checkpoint) {
+			last = &cp
+		}
+		w := &crashWriter{limit: want.Len() / 2}
+		if err := WriteConstraintsTo(w, cons, &first); err != errCrash {
+			t.Fatalf("compress %t: got error %v, want crash", compress, err)
+		}
+		if last == nil || last.Constraints == 0 || last.Bytes > int64(w.Len()) {
+			t.Fatalf("compress %t: bad checkpoint %+v after %d bytes", compress, last, w.Len())
+		}
+
+		w.Truncate(int(last.Bytes))
+		w.limit = math.MaxInt
+		second := first
+		second.Resume = last
+		if err := WriteConstraintsTo(w, cons, &second); err != nil {
+			t.Fatal(err)
+		}
+		got, wantText := w.Bytes(), want.Bytes()
+		if compress {
+			got = gunzip(t, got)
+			wantText = gunzip(t, wantText)
+		}
+		if !bytes.Equal(got, wantText) {
+			t.Errorf("compress %t: resumed output differs", compress)
+		}
+	}
+
+	bad := &Options{Resume: &Checkpoint{Constraints: len(cons) + 1}}
+	if err := WriteConstraintsTo(io.Discard, cons, bad); err == nil {
+		t.Errorf("no error resuming past the end")
+	}
+}
+
+func gunzip(t *testing.T, b []byte) []byte {
+	zr, err := gzip.NewReader(bytes.NewReader(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := io.ReadAll(zr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
+
+// BenchmarkWriteFile writes to a file with buffer sizes from the bufio
+// default up, to show the cost of the write calls.
+func BenchmarkWriteFile(b *testing.B) {
+	cons := randomSparseConstraints(*benchVars, *benchCons, *benchDensity)
+	for _, size := range []int{4 << 10, 64 << 10, 1 << 20} {
+		b.Run(fmt.Sprintf("BufferSize=%d", size), func(b *testing.B) {
+			f, err := os.Create(filepath.Join(b.TempDir(), "out.lp"))
+			if err != nil {
+				b.Fatal(err)
+			}
+			defer f.Close()
+			opts := &Options{BufferSize: size}
+			for i := 0; i < b.N; i++ {
+				if _, err := f.Seek(0, io.SeekStart); err != nil {
+					b.Fatal(err)
+				}
+				if err := WriteConstraintsTo(f, cons, opts); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}